@@ -0,0 +1,65 @@
+package client
+
+import "sync"
+
+const (
+	adaptiveIncreaseFactor = 2.0  // 每次失败时间隔倍数的乘数
+	adaptiveDecreaseStep   = 0.25 // 每次成功时间隔倍数的减少量
+	adaptiveMaxMultiplier  = 16.0 // 间隔倍数上限，避免故障期间请求间隔无限增长
+)
+
+// adaptiveInterval 按 AIMD 方式调整后台请求间隔的倍数：
+// 失败或熔断器开启时倍数翻倍（乘性增加间隔），成功时倍数线性回落到 1（加性恢复到基准间隔）
+type adaptiveInterval struct {
+	mu         sync.Mutex
+	multiplier float64
+}
+
+// newAdaptiveInterval 创建间隔倍数为 1 的自适应间隔
+func newAdaptiveInterval() *adaptiveInterval {
+	return &adaptiveInterval{multiplier: 1}
+}
+
+// onSuccess 记录一次成功请求，倍数向 1 回落，返回调整后的倍数
+func (a *adaptiveInterval) onSuccess() float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.multiplier = max(1, a.multiplier-adaptiveDecreaseStep)
+	return a.multiplier
+}
+
+// onFailure 记录一次失败或熔断器拒绝，倍数翻倍，返回调整后的倍数
+func (a *adaptiveInterval) onFailure() float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.multiplier = min(adaptiveMaxMultiplier, a.multiplier*adaptiveIncreaseFactor)
+	return a.multiplier
+}
+
+// current 返回当前倍数
+func (a *adaptiveInterval) current() float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.multiplier
+}
+
+// recordAdaptiveResult 在启用自适应间隔时根据后台请求结果调整间隔倍数
+func (c *GRPCClient) recordAdaptiveResult(err error) {
+	if c.adaptive == nil {
+		return
+	}
+
+	before := c.adaptive.current()
+	var after float64
+	if err != nil {
+		after = c.adaptive.onFailure()
+	} else {
+		after = c.adaptive.onSuccess()
+	}
+	if after != before {
+		c.slogger.Info("自适应请求间隔倍数已调整", map[string]interface{}{
+			"old_multiplier": before,
+			"new_multiplier": after,
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	pb "srpc/proto"
+
+	"google.golang.org/grpc"
+)
+
+// ErrStreamStale 双向流在 StreamStaleThreshold 内没有收到任何消息（包括服务端心跳），对端可能已失联
+var ErrStreamStale = errors.New("双向流长时间未收到消息")
+
+// AllStream OpenAllStream 打开的双向流
+// Send 和 CloseSend 可以与接收并发调用；服务端心跳只用于判断对端是否存活，不会出现在 Messages 中
+type AllStream struct {
+	c         *GRPCClient
+	stream    grpc.BidiStreamingClient[pb.StreamReqData, pb.StreamResData]
+	ctx       context.Context
+	cancel    context.CancelFunc
+	threshold time.Duration
+	out       chan *pb.StreamResData
+	errc      chan error
+
+	sendMu sync.Mutex // gRPC 不允许并发调用 Send
+
+	mu            sync.Mutex
+	idleSince     time.Time // 开始等待下一条消息的时间，等待消费方取走消息期间为零值
+	lastHeartbeat time.Time // 最近一次收到心跳的时间
+	stale         bool      // 是否因超过 StreamStaleThreshold 而结束
+}
+
+// OpenAllStream 打开双向流 AllStream，服务端的数据消息通过 Messages 返回的 channel 交付，最多缓冲 bufferSize 条
+// 配置了 StreamStaleThreshold 时，等待下一条消息（数据或心跳）超过该时长即取消流，Err 返回包装 ErrStreamStale 的错误；
+// 消费方未及时取走消息的时间不计入，服务端需要通过 STREAM_HEARTBEAT_INTERVAL_SEC 开启心跳，否则空闲的流也会被判定失联
+// 流在 ctx 取消、调用 Close、服务端结束流或客户端关闭时结束，期间占用 StreamRequestWeight 个并发许可；opts 的含义与 CallGetStream 相同
+func (c *GRPCClient) OpenAllStream(ctx context.Context, bufferSize int, opts ...grpc.CallOption) (*AllStream, error) {
+	release, err := c.acquireStreamSlot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	greeter, ctx, cancel, err := c.prepareStream(ctx)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	stream, err := greeter.AllStream(ctx, opts...)
+	if err != nil {
+		cancel()
+		release()
+		return nil, classifyError(err)
+	}
+
+	s := &AllStream{
+		c:         c,
+		stream:    stream,
+		ctx:       ctx,
+		cancel:    cancel,
+		threshold: c.config.StreamStaleThreshold,
+		out:       make(chan *pb.StreamResData, max(bufferSize, 0)),
+		errc:      make(chan error, 1),
+	}
+	go func() {
+		defer release()
+		defer cancel()
+		s.errc <- s.receive()
+	}()
+	if s.threshold > 0 {
+		go s.watchStale()
+	}
+	return s, nil
+}
+
+// Messages 返回服务端数据消息的 channel，流结束后关闭
+func (s *AllStream) Messages() <-chan *pb.StreamResData {
+	return s.out
+}
+
+// Err 在 Messages 关闭后恰好返回一个值：服务端正常结束流时为 nil，失联时包装 ErrStreamStale，其余 RPC 错误按类别包装为 RPCError
+func (s *AllStream) Err() <-chan error {
+	return s.errc
+}
+
+// Send 向服务端发送一条消息
+func (s *AllStream) Send(data string) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+
+	s.c.logPayload(s.ctx, "AllStream", map[string]interface{}{"request_data": data})
+	if err := s.stream.Send(&pb.StreamReqData{Data: data}); err != nil {
+		// Send 返回 io.EOF 表示流已结束，真实错误通过 Err 获取
+		if errors.Is(err, io.EOF) {
+			return err
+		}
+		return classifyError(err)
+	}
+	return nil
+}
+
+// CloseSend 通知服务端不再发送消息，仍可继续接收
+func (s *AllStream) CloseSend() error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	return s.stream.CloseSend()
+}
+
+// Close 取消流，之后 Messages 关闭，Err 返回 ErrCanceled 类别的错误
+func (s *AllStream) Close() {
+	s.cancel()
+}
+
+// LastHeartbeat 返回最近一次收到服务端心跳的时间，尚未收到时为零值
+func (s *AllStream) LastHeartbeat() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastHeartbeat
+}
+
+// receive 接收服务端消息直到流结束，心跳只更新时间，数据消息交给消费方
+func (s *AllStream) receive() error {
+	defer close(s.out)
+	for {
+		s.setIdle(s.c.clock.Now())
+		resp, err := s.stream.Recv()
+		if errors.Is(err, io.EOF) {
+			s.c.logStreamTrailer(s.ctx, "AllStream", s.stream.Trailer())
+			return nil
+		}
+		if err != nil {
+			s.mu.Lock()
+			stale := s.stale
+			s.mu.Unlock()
+			if stale {
+				return fmt.Errorf("%w: %v 内未收到任何消息", ErrStreamStale, s.threshold)
+			}
+			return classifyError(err)
+		}
+
+		if resp.GetKind() == pb.StreamMessageKind_STREAM_MESSAGE_KIND_HEARTBEAT {
+			s.mu.Lock()
+			s.lastHeartbeat = s.c.clock.Now()
+			s.mu.Unlock()
+			continue
+		}
+
+		s.setIdle(time.Time{})
+		s.c.logPayload(s.ctx, "AllStream", map[string]interface{}{"response_data": resp.GetData()})
+		select {
+		case s.out <- resp:
+		case <-s.ctx.Done():
+			return classifyError(s.ctx.Err())
+		}
+	}
+}
+
+// setIdle 记录开始等待下一条消息的时间，零值表示正在等待消费方
+func (s *AllStream) setIdle(t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.idleSince = t
+}
+
+// watchStale 等待下一条消息超过 threshold 时取消流
+func (s *AllStream) watchStale() {
+	for {
+		s.mu.Lock()
+		wait := s.threshold
+		if !s.idleSince.IsZero() {
+			wait = s.idleSince.Add(s.threshold).Sub(s.c.clock.Now())
+		}
+		if wait <= 0 {
+			s.stale = true
+		}
+		s.mu.Unlock()
+
+		if wait <= 0 {
+			s.c.slogger.WarnContext(s.ctx, "双向流长时间未收到消息，判定对端失联", map[string]interface{}{
+				"threshold":      s.threshold.String(),
+				"last_heartbeat": s.LastHeartbeat(),
+			})
+			s.cancel()
+			return
+		}
+		select {
+		case <-s.c.clock.After(wait):
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
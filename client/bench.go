@@ -0,0 +1,220 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	pb "srpc/proto"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/status"
+)
+
+// BenchWorkload 压测负载类型
+type BenchWorkload string
+
+const (
+	BenchUnary  BenchWorkload = "unary"  // SayHello 普通调用
+	BenchStream BenchWorkload = "stream" // GetStream 服务端流，完整接收一次流计为一个请求
+)
+
+// BenchOptions 压测参数
+type BenchOptions struct {
+	QPS      int           // 目标每秒请求数，所有 worker 共享；0 表示不限速，吞吐由 Workers 决定
+	Workers  int           // 固定的并发 worker 数（默认 1）
+	Duration time.Duration // 压测时长
+	Workload BenchWorkload // 负载类型（默认 unary）
+}
+
+// BenchReport 压测报告
+type BenchReport struct {
+	Workload    BenchWorkload
+	TargetQPS   int
+	Workers     int
+	Total       int64            // 完成的请求数
+	Success     int64            // 成功数
+	Errors      map[string]int64 // 按 gRPC 状态码统计的失败数，熔断拒绝和无可用连接单独计数
+	Elapsed     time.Duration    // 实际压测时长（含等待最后一批请求完成）
+	AchievedQPS float64          // 实际达到的每秒请求数
+	P50         time.Duration    // 延迟分位数，由与客户端指标相同的直方图估算
+	P90         time.Duration
+	P99         time.Duration
+	Max         time.Duration
+}
+
+// String 返回便于终端阅读的多行报告
+func (r BenchReport) String() string {
+	var b strings.Builder
+	target := "不限速"
+	if r.TargetQPS > 0 {
+		target = fmt.Sprintf("%d", r.TargetQPS)
+	}
+	fmt.Fprintf(&b, "压测报告（%s）\n", r.Workload)
+	fmt.Fprintf(&b, "  目标 QPS:   %s，worker 数: %d\n", target, r.Workers)
+	fmt.Fprintf(&b, "  持续时间:   %v\n", r.Elapsed.Round(time.Millisecond))
+	fmt.Fprintf(&b, "  实际 QPS:   %.2f\n", r.AchievedQPS)
+	fmt.Fprintf(&b, "  请求总数:   %d（成功 %d，失败 %d）\n", r.Total, r.Success, r.Total-r.Success)
+	for _, code := range slices.Sorted(maps.Keys(r.Errors)) {
+		fmt.Fprintf(&b, "    %-20s %d\n", code, r.Errors[code])
+	}
+	fmt.Fprintf(&b, "  延迟:       p50=%v p90=%v p99=%v max=%v\n", r.P50, r.P90, r.P99, r.Max)
+	return b.String()
+}
+
+// RunBench 使用固定数量的 worker 以目标 QPS 发送请求，持续 opts.Duration 或直到 ctx 取消，返回压测报告
+// 与 RunLoad 不同，并发度固定为 Workers，适合测量服务端在给定并发下的吞吐和延迟；
+// 请求不经过后台定时循环，但同样使用客户端的连接池、压缩、熔断器、重试和并发信号量配置，并计入客户端指标
+func (c *GRPCClient) RunBench(ctx context.Context, opts BenchOptions) (BenchReport, error) {
+	if opts.Duration <= 0 {
+		return BenchReport{}, fmt.Errorf("无效的压测时长: %v", opts.Duration)
+	}
+	if opts.QPS < 0 {
+		return BenchReport{}, fmt.Errorf("无效的目标 QPS: %d", opts.QPS)
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+	if opts.Workload == "" {
+		opts.Workload = BenchUnary
+	}
+	if opts.Workload != BenchUnary && opts.Workload != BenchStream {
+		return BenchReport{}, fmt.Errorf("未知的压测负载类型: %s", opts.Workload)
+	}
+
+	var limiter *rate.Limiter
+	if opts.QPS > 0 {
+		limiter = rate.NewLimiter(rate.Limit(opts.QPS), 1)
+	}
+
+	// benchCtx 控制发送窗口；已发出的请求使用调用方的 ctx，压测结束时不会被中途取消
+	benchCtx, cancel := context.WithTimeout(ctx, opts.Duration)
+	defer cancel()
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		histogram latencyHistogram
+		report    = BenchReport{
+			Workload:  opts.Workload,
+			TargetQPS: opts.QPS,
+			Workers:   opts.Workers,
+			Errors:    make(map[string]int64),
+		}
+	)
+	record := func(latency time.Duration, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		report.Total++
+		if err != nil {
+			report.Errors[benchErrorCode(err)]++
+			return
+		}
+		report.Success++
+		histogram.observe(latency)
+	}
+
+	c.slogger.Info("开始压测", map[string]interface{}{
+		"workload":   opts.Workload,
+		"target_qps": opts.QPS,
+		"workers":    opts.Workers,
+		"duration":   opts.Duration.String(),
+	})
+
+	start := time.Now()
+	for w := 0; w < opts.Workers; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for seq := 0; ; seq++ {
+				if limiter != nil {
+					// 令牌无法在压测窗口内获得时 Wait 立即返回错误
+					if err := limiter.Wait(benchCtx); err != nil {
+						return
+					}
+				} else if benchCtx.Err() != nil {
+					return
+				}
+				if c.IsShutting() {
+					return
+				}
+
+				acquire := c.acquireSlot
+				if opts.Workload == BenchStream {
+					acquire = c.acquireStreamSlot
+				}
+				release, err := acquire(benchCtx)
+				if err != nil {
+					return
+				}
+				begin := time.Now()
+				err = c.benchRequest(ctx, opts.Workload, fmt.Sprintf("Bench-%d-%d", worker, seq))
+				record(time.Since(begin), err)
+				release()
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	report.Elapsed = time.Since(start)
+	if report.Elapsed > 0 {
+		report.AchievedQPS = float64(report.Total) / report.Elapsed.Seconds()
+	}
+	report.P50 = histogram.quantile(0.50)
+	report.P90 = histogram.quantile(0.90)
+	report.P99 = histogram.quantile(0.99)
+	report.Max = histogram.max
+
+	c.slogger.Info("压测完成", map[string]interface{}{
+		"workload":     opts.Workload,
+		"total":        report.Total,
+		"success":      report.Success,
+		"errors":       report.Errors,
+		"achieved_qps": report.AchievedQPS,
+		"p50":          report.P50.String(),
+		"p90":          report.P90.String(),
+		"p99":          report.P99.String(),
+		"max":          report.Max.String(),
+	})
+
+	// 调用方主动取消时返回取消原因，正常到达压测时长不视为错误
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// benchRequest 执行一次压测请求，服务端流需要完整接收所有响应
+func (c *GRPCClient) benchRequest(ctx context.Context, workload BenchWorkload, name string) error {
+	if workload == BenchStream {
+		return c.callGetStream(ctx, name, func(*pb.StreamResData) error { return nil })
+	}
+
+	if !c.circuitBreaker.AllowRequest() {
+		return ErrCircuitOpen
+	}
+	pc, _, err := c.readyConn()
+	if err != nil {
+		return err
+	}
+	_, err = c.sayHello(ctx, pc, name)
+	return err
+}
+
+// benchErrorCode 返回压测报告中错误的分类名，客户端本地拒绝的请求没有 gRPC 状态码，单独列出
+func benchErrorCode(err error) string {
+	switch {
+	case errors.Is(err, ErrCircuitOpen):
+		return "CircuitOpen"
+	case errors.Is(err, ErrNotConnected):
+		return "NotConnected"
+	case errors.Is(err, ErrClientShutting):
+		return "ClientShutting"
+	default:
+		return status.Code(err).String()
+	}
+}
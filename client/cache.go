@@ -0,0 +1,105 @@
+package client
+
+import (
+	"container/list"
+	"srpc/pkg/tools"
+	pb "srpc/proto"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// noCacheOption 标记本次调用跳过响应缓存，对 gRPC 本身没有作用
+type noCacheOption struct {
+	grpc.EmptyCallOption
+}
+
+// NoCache 返回跳过响应缓存的调用选项：本次 SendNow 既不读取缓存，也不写入结果
+func NoCache() grpc.CallOption {
+	return noCacheOption{}
+}
+
+// hasNoCache 判断调用选项中是否包含 NoCache
+func hasNoCache(opts []grpc.CallOption) bool {
+	for _, opt := range opts {
+		if _, ok := opt.(noCacheOption); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheEntry 缓存中的一条 SayHello 响应
+type cacheEntry struct {
+	name      string
+	reply     *pb.HelloReply
+	expiresAt time.Time
+}
+
+// responseCache 按请求 name 缓存成功的 SayHello 响应的 LRU 缓存，条目在 ttl 后过期
+type responseCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	size    int
+	clock   tools.Clock
+	order   *list.List // 最近使用的条目在前
+	entries map[string]*list.Element
+}
+
+// newResponseCache 创建响应缓存，ttl 或 size 不大于 0 时返回 nil 表示不缓存
+func newResponseCache(ttl time.Duration, size int, clock tools.Clock) *responseCache {
+	if ttl <= 0 || size <= 0 {
+		return nil
+	}
+	return &responseCache{
+		ttl:     ttl,
+		size:    size,
+		clock:   clock,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// get 返回未过期的缓存响应副本，过期条目会被删除
+func (rc *responseCache) get(name string) (*pb.HelloReply, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	elem, ok := rc.entries[name]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if !rc.clock.Now().Before(entry.expiresAt) {
+		rc.order.Remove(elem)
+		delete(rc.entries, name)
+		return nil, false
+	}
+	rc.order.MoveToFront(elem)
+	return proto.Clone(entry.reply).(*pb.HelloReply), true
+}
+
+// put 写入响应副本，超出容量时淘汰最久未使用的条目
+func (rc *responseCache) put(name string, reply *pb.HelloReply) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	entry := &cacheEntry{
+		name:      name,
+		reply:     proto.Clone(reply).(*pb.HelloReply),
+		expiresAt: rc.clock.Now().Add(rc.ttl),
+	}
+	if elem, ok := rc.entries[name]; ok {
+		elem.Value = entry
+		rc.order.MoveToFront(elem)
+		return
+	}
+	rc.entries[name] = rc.order.PushFront(entry)
+	for rc.order.Len() > rc.size {
+		oldest := rc.order.Back()
+		rc.order.Remove(oldest)
+		delete(rc.entries, oldest.Value.(*cacheEntry).name)
+	}
+}
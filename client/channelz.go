@@ -0,0 +1,50 @@
+package client
+
+import (
+	"net"
+
+	"google.golang.org/grpc"
+	channelzsvc "google.golang.org/grpc/channelz/service"
+)
+
+// startChannelzServer 在 ChannelzAddr 上启动只提供 channelz 服务的 gRPC 服务器，未配置时不启动
+// grpc-go 总是收集 channelz 数据，该服务使 grpcdebug 等工具可以查看客户端通道、子通道和 socket 的状态
+// 监听失败只记录错误，不影响客户端运行
+func (c *GRPCClient) startChannelzServer() {
+	if c.config.ChannelzAddr == "" {
+		return
+	}
+
+	lis, err := net.Listen("tcp", c.config.ChannelzAddr)
+	if err != nil {
+		c.slogger.Error("channelz 服务监听失败", map[string]interface{}{"addr": c.config.ChannelzAddr, "error": err})
+		return
+	}
+
+	srv := grpc.NewServer()
+	channelzsvc.RegisterChannelzServiceToServer(srv)
+	c.mu.Lock()
+	c.channelzServer = srv
+	c.mu.Unlock()
+
+	c.slogger.Info("channelz 服务已启动", map[string]interface{}{"addr": lis.Addr().String()})
+	go func() {
+		if err := srv.Serve(lis); err != nil {
+			c.slogger.Error("channelz 服务异常退出", map[string]interface{}{"error": err})
+		}
+	}()
+}
+
+// stopChannelzServer 关闭 channelz 服务
+func (c *GRPCClient) stopChannelzServer() {
+	c.mu.Lock()
+	srv := c.channelzServer
+	c.channelzServer = nil
+	c.mu.Unlock()
+	if srv == nil {
+		return
+	}
+
+	srv.Stop()
+	c.slogger.Info("channelz 服务已关闭")
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"srpc/pkg/chaos"
+	pb "srpc/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// chaosConfig 由客户端配置构建故障注入配置
+func (c *GRPCClient) chaosConfig() chaos.Config {
+	return chaos.Config{
+		ErrorRate:     c.config.ChaosErrorRate,
+		Codes:         c.config.ChaosCodes,
+		Latency:       c.config.ChaosLatency,
+		LatencyJitter: c.config.ChaosLatencyJitter,
+	}
+}
+
+// chaosUnaryInterceptor 在普通 RPC 发出前注入延迟和错误
+// 注入的错误与真实 RPC 错误一样经过重试、熔断器和指标统计
+func (c *GRPCClient) chaosUnaryInterceptor(cfg chaos.Config) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if chaosExempt(method) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		if err := c.injectChaos(ctx, cfg, method); err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// chaosStreamInterceptor 在建立流之前注入延迟和错误
+func (c *GRPCClient) chaosStreamInterceptor(cfg chaos.Config) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if err := c.injectChaos(ctx, cfg, method); err != nil {
+			return nil, err
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+// injectChaos 执行一次故障注入，注入错误时记录日志
+func (c *GRPCClient) injectChaos(ctx context.Context, cfg chaos.Config, method string) error {
+	err := cfg.Inject(ctx)
+	if err != nil {
+		c.slogger.WarnContext(ctx, "故障注入：返回错误", map[string]interface{}{
+			"method": method,
+			"code":   status.Code(err).String(),
+		})
+	}
+	return err
+}
+
+// chaosExempt 健康检查和服务端信息查询不注入故障，避免触发重连掩盖请求路径上的重试和熔断行为
+func chaosExempt(method string) bool {
+	return method == pb.Greeter_Ping_FullMethodName || method == pb.Greeter_GetServerInfo_FullMethodName
+}
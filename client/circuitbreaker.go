@@ -1,6 +1,7 @@
 package client
 
 import (
+	"srpc/pkg/tools"
 	"sync"
 	"time"
 )
@@ -31,6 +32,7 @@ func (s CircuitBreakerState) String() string {
 // CircuitBreaker 熔断器
 type CircuitBreaker struct {
 	state             CircuitBreakerState
+	forced            bool // 由 ForceOpen/ForceClose 手动固定状态，不再按阈值自动切换
 	failureCount      int
 	successCount      int
 	lastStateChange   time.Time
@@ -39,47 +41,86 @@ type CircuitBreaker struct {
 	successThreshold  int
 	halfOpenMaxCalls  int
 	halfOpenCallCount int
+	onStateChange     func(old, new CircuitBreakerState)
+	clock             tools.Clock
 	mu                sync.RWMutex
+
+	// 按错误率触发的配置，errorRateSource 为 nil 时只按连续失败次数触发
+	errorRateThreshold float64
+	errorRateMinCalls  int64
+	errorRateSource    func(since time.Time) (errorRate float64, requests int64)
+}
+
+// CircuitBreakerOption 熔断器的可选配置
+type CircuitBreakerOption func(*CircuitBreaker)
+
+// WithCircuitBreakerClock 指定熔断器使用的时钟，测试中可传入 tools.FakeClock，默认使用真实时钟
+func WithCircuitBreakerClock(clock tools.Clock) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.clock = clock
+	}
+}
+
+// WithErrorRateThreshold 启用按错误率触发：关闭状态下记录失败时，若 source 报告的错误率（0-1）不低于 threshold
+// 且请求数不少于 minRequests，即使连续失败次数未达到阈值也开启熔断，适合成功与失败交替出现的部分故障
+// source 的 since 为上一次状态切换的时间，只应统计其后的请求，避免恢复后因恢复前的失败立即再次开启；source 在锁外调用
+func WithErrorRateThreshold(threshold float64, minRequests int, source func(since time.Time) (errorRate float64, requests int64)) CircuitBreakerOption {
+	return func(cb *CircuitBreaker) {
+		cb.errorRateThreshold = threshold
+		cb.errorRateMinCalls = int64(minRequests)
+		cb.errorRateSource = source
+	}
 }
 
 // NewCircuitBreaker 创建新的熔断器
-func NewCircuitBreaker(failureThreshold, successThreshold int, openDuration time.Duration) *CircuitBreaker {
-	return &CircuitBreaker{
+func NewCircuitBreaker(failureThreshold, successThreshold int, openDuration time.Duration, opts ...CircuitBreakerOption) *CircuitBreaker {
+	cb := &CircuitBreaker{
 		state:            CBStateClosed,
 		failureThreshold: failureThreshold,
 		successThreshold: successThreshold,
 		openDuration:     openDuration,
 		halfOpenMaxCalls: 3, // 半开状态下允许的最大请求数
+		clock:            tools.RealClock{},
 	}
+	for _, opt := range opts {
+		opt(cb)
+	}
+	return cb
+}
+
+// SetStateChangeCallback 设置状态变化回调，回调在锁外执行，可能从不同 goroutine 并发调用
+func (cb *CircuitBreaker) SetStateChangeCallback(fn func(old, new CircuitBreakerState)) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.onStateChange = fn
 }
 
 // AllowRequest 检查是否允许请求
 func (cb *CircuitBreaker) AllowRequest() bool {
-	cb.mu.RLock()
-	defer cb.mu.RUnlock()
+	cb.mu.Lock()
 
 	switch cb.state {
 	case CBStateClosed:
+		cb.mu.Unlock()
 		return true
 	case CBStateOpen:
-		// 检查是否应该切换到半开状态
-		if time.Since(cb.lastStateChange) >= cb.openDuration {
-			cb.mu.RUnlock()
-			cb.mu.Lock()
-			cb.state = CBStateHalfOpen
-			cb.halfOpenCallCount = 0
-			cb.lastStateChange = time.Now()
+		// 手动开启的熔断器不会自动切换到半开状态
+		if cb.forced || cb.clock.Now().Sub(cb.lastStateChange) < cb.openDuration {
 			cb.mu.Unlock()
-			cb.mu.RLock()
-			return true
+			return false
 		}
-		return false
+		// 开启时间已到，切换到半开状态
+		cb.halfOpenCallCount = 0
+		old := cb.transitionLocked(CBStateHalfOpen)
+		cb.mu.Unlock()
+		cb.notify(old, CBStateHalfOpen)
+		return true
 	case CBStateHalfOpen:
-		if cb.halfOpenCallCount < cb.halfOpenMaxCalls {
-			return true
-		}
-		return false
+		allowed := cb.halfOpenCallCount < cb.halfOpenMaxCalls
+		cb.mu.Unlock()
+		return allowed
 	default:
+		cb.mu.Unlock()
 		return false
 	}
 }
@@ -87,8 +128,12 @@ func (cb *CircuitBreaker) AllowRequest() bool {
 // RecordSuccess 记录成功
 func (cb *CircuitBreaker) RecordSuccess() {
 	cb.mu.Lock()
-	defer cb.mu.Unlock()
+	if cb.forced {
+		cb.mu.Unlock()
+		return
+	}
 
+	old, current := cb.state, cb.state
 	switch cb.state {
 	case CBStateClosed:
 		cb.successCount++
@@ -97,38 +142,109 @@ func (cb *CircuitBreaker) RecordSuccess() {
 		cb.halfOpenCallCount++
 		cb.successCount++
 		if cb.successCount >= cb.successThreshold {
-			cb.state = CBStateClosed
 			cb.successCount = 0
 			cb.failureCount = 0
-			cb.lastStateChange = time.Now()
+			current = CBStateClosed
+			cb.transitionLocked(current)
 		}
 	default:
 		// TODO
 	}
+	cb.mu.Unlock()
+	cb.notify(old, current)
 }
 
 // RecordFailure 记录失败
 func (cb *CircuitBreaker) RecordFailure() {
+	rateExceeded := cb.errorRateExceeded()
+
 	cb.mu.Lock()
-	defer cb.mu.Unlock()
+	if cb.forced {
+		cb.mu.Unlock()
+		return
+	}
 
+	old, current := cb.state, cb.state
 	switch cb.state {
 	case CBStateClosed:
 		cb.failureCount++
 		cb.successCount = 0
-		if cb.failureCount >= cb.failureThreshold {
-			cb.state = CBStateOpen
-			cb.lastStateChange = time.Now()
+		if cb.failureCount >= cb.failureThreshold || rateExceeded {
+			current = CBStateOpen
+			cb.transitionLocked(current)
 		}
 	case CBStateHalfOpen:
 		cb.halfOpenCallCount++
 		cb.failureCount++
 		cb.successCount = 0
-		cb.state = CBStateOpen
-		cb.lastStateChange = time.Now()
+		current = CBStateOpen
+		cb.transitionLocked(current)
 	default:
 		// TODO
 	}
+	cb.mu.Unlock()
+	cb.notify(old, current)
+}
+
+// errorRateExceeded 按错误率判断是否应开启熔断，未启用时返回 false
+func (cb *CircuitBreaker) errorRateExceeded() bool {
+	if cb.errorRateSource == nil {
+		return false
+	}
+	cb.mu.RLock()
+	since := cb.lastStateChange
+	cb.mu.RUnlock()
+
+	rate, requests := cb.errorRateSource(since)
+	return requests >= cb.errorRateMinCalls && rate >= cb.errorRateThreshold
+}
+
+// ForceOpen 手动开启熔断器并拒绝所有请求，直到调用 ForceClose 或 Reset
+func (cb *CircuitBreaker) ForceOpen() {
+	cb.force(CBStateOpen, true)
+}
+
+// ForceClose 手动关闭熔断器并放行所有请求，期间失败不会触发熔断，直到调用 Reset
+func (cb *CircuitBreaker) ForceClose() {
+	cb.force(CBStateClosed, true)
+}
+
+// Reset 清除手动设置和计数器，回到关闭状态并恢复按阈值自动切换
+func (cb *CircuitBreaker) Reset() {
+	cb.force(CBStateClosed, false)
+}
+
+// force 切换到指定状态并清零计数器
+func (cb *CircuitBreaker) force(state CircuitBreakerState, forced bool) {
+	cb.mu.Lock()
+	cb.forced = forced
+	cb.failureCount = 0
+	cb.successCount = 0
+	cb.halfOpenCallCount = 0
+	old := cb.transitionLocked(state)
+	cb.mu.Unlock()
+	cb.notify(old, state)
+}
+
+// transitionLocked 切换状态并返回切换前的状态，调用方需持有写锁，并在释放锁后调用 notify
+func (cb *CircuitBreaker) transitionLocked(state CircuitBreakerState) CircuitBreakerState {
+	old := cb.state
+	cb.state = state
+	cb.lastStateChange = cb.clock.Now()
+	return old
+}
+
+// notify 状态发生变化时调用回调
+func (cb *CircuitBreaker) notify(old, current CircuitBreakerState) {
+	if old == current {
+		return
+	}
+	cb.mu.RLock()
+	fn := cb.onStateChange
+	cb.mu.RUnlock()
+	if fn != nil {
+		fn(old, current)
+	}
 }
 
 // GetState 获取当前状态
@@ -137,3 +253,45 @@ func (cb *CircuitBreaker) GetState() CircuitBreakerState {
 	defer cb.mu.RUnlock()
 	return cb.state
 }
+
+// IsForced 返回当前状态是否由 ForceOpen/ForceClose 手动固定
+func (cb *CircuitBreaker) IsForced() bool {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+	return cb.forced
+}
+
+// onCircuitBreakerStateChange 记录熔断器状态变化并调用 Config.OnCircuitBreakerStateChange
+func (c *GRPCClient) onCircuitBreakerStateChange(old, current CircuitBreakerState) {
+	c.slogger.Warn("熔断器状态变化", map[string]interface{}{
+		"old_state": old.String(),
+		"new_state": current.String(),
+		"forced":    c.circuitBreaker.IsForced(),
+	})
+	if c.config.OnCircuitBreakerStateChange != nil {
+		c.config.OnCircuitBreakerStateChange(old, current)
+	}
+}
+
+// ForceOpenCircuitBreaker 手动开启熔断器，主动拒绝所有请求，直到调用 ForceCloseCircuitBreaker 或 ResetCircuitBreaker
+func (c *GRPCClient) ForceOpenCircuitBreaker() {
+	c.slogger.Warn("手动开启熔断器")
+	c.circuitBreaker.ForceOpen()
+}
+
+// ForceCloseCircuitBreaker 手动关闭熔断器，放行所有请求且失败不会触发熔断，直到调用 ResetCircuitBreaker
+func (c *GRPCClient) ForceCloseCircuitBreaker() {
+	c.slogger.Warn("手动关闭熔断器")
+	c.circuitBreaker.ForceClose()
+}
+
+// ResetCircuitBreaker 清除手动设置和计数器，熔断器回到关闭状态并恢复自动切换
+func (c *GRPCClient) ResetCircuitBreaker() {
+	c.slogger.Info("重置熔断器")
+	c.circuitBreaker.Reset()
+}
+
+// CircuitBreakerState 返回熔断器当前状态
+func (c *GRPCClient) CircuitBreakerState() CircuitBreakerState {
+	return c.circuitBreaker.GetState()
+}
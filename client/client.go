@@ -2,57 +2,215 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/rand/v2"
+	"net/http"
 	"os"
 	"os/signal"
 	_ "srpc/pkg/compress" // 确保压缩器被注册
 	"srpc/pkg/log"
 	"srpc/pkg/tools"
 	_ "srpc/pkg/tools"
-	pb "srpc/proto"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 )
 
 // Config 客户端配置
 type Config struct {
 	ServerAddr            string        // gRPC 服务器地址
-	KeepAliveInterval     time.Duration // 连接保活间隔
+	DialTimeout           time.Duration // 建立连接时等待连接就绪的最长时间（默认 10 秒），超时后 NewGRPCClient 或本次重连返回错误
+	KeepAliveInterval     time.Duration // 健康检查间隔（应用层 Ping 探测及断连、降级连接的恢复）
 	RequestInterval       time.Duration // 请求间隔时间
 	MaxRetries            int           // 最大重试次数
 	JitterPercent         int           // 随机抖动百分比（0-100）
+	JitterSeed            uint64        // 抖动随机数种子，非 0 时抖动序列可复现，用于测试；为 0 时随机选取
+	AdaptiveInterval      bool          // 是否根据请求结果自适应调整请求间隔：失败或熔断时间隔翻倍（最多 16 倍），成功后逐步回落到 RequestInterval
 	EnableCompression     bool          // 是否启用压缩
-	CompressionType       string        // 压缩类型：snappy（目前只支持 snappy）
+	CompressionType       string        // 压缩类型：snappy 或 deflate
 	GenerateRequestID     bool          // 是否为每个请求生成唯一 ID
+	RequestIDFormat       string        // 请求 ID 格式：simple（默认，基于时间戳的十进制整数）、simple62（同一 ID 的 base62 形式）、uuid（随机 UUID）、uuidv7（按时间排序的 UUID）、ulid 或 short（16 位十六进制随机 ID）
+	IdempotencyKeys       bool          // 是否为一元 SayHello 附加幂等键（取请求 ID，同一逻辑请求的重试和对冲请求相同），供服务端去重；需启用 GenerateRequestID
+	LoadBalancingPolicy   string        // 负载均衡策略，例如 round_robin；为空时使用 gRPC 默认的 pick_first
+	EnableTracing         bool          // 是否启用 OpenTelemetry 链路追踪（使用全局 TracerProvider）
+	PoolSize              int           // 连接池大小，小于等于 1 时只使用单个连接
+	ReconnectBaseDelay    time.Duration // 重连退避的基础延迟（默认 1 秒）
+	ReconnectMaxDelay     time.Duration // 重连退避的最大延迟（默认 30 秒）
+	MaxReconnectAttempts  int           // 单轮重连的最大尝试次数，耗尽后进入降级状态；0 表示无限重试（退避受 ReconnectMaxDelay 限制）
+	RetryBaseDelay        time.Duration // 请求重试退避的基础延迟（默认 1 秒）
+	RetryMaxDelay         time.Duration // 请求重试退避的最大延迟（默认 10 秒）
+	RetryMultiplier       float64       // 请求重试退避的增长倍数（默认 2）
+	PerAttemptTimeout     time.Duration // 单次尝试的超时时间（默认 5 秒），每次重试都会重新计时
+	OverallTimeout        time.Duration // 一次逻辑请求（含全部重试和退避等待）的整体超时（默认 30 秒）
+	SlowRequestThreshold  time.Duration // 单次尝试耗时达到该值时记录慢请求警告，包含服务端处理耗时和网络开销；0 表示不记录
+	HedgingMaxAttempts    int           // 对冲请求：每次尝试最多同时发送的相同 SayHello 数（含原始请求），小于等于 1 表示不启用
+	HedgingDelay          time.Duration // 对冲请求：原始请求发出后多久仍无响应时追加下一个请求，0 表示同时发出全部请求
+	RetryBudgetRatio      float64       // 每次成功请求存入的重试令牌数（默认 0.1，即重试量约为成功量的 10%）
+	RetryBudgetMinReserve int           // 每秒至少保留的重试令牌数（默认 10）
+	RetryBudgetMaxTokens  int           // 重试预算令牌桶容量（默认 100），限制故障前积累的重试量
+	WaitForReady          bool          // RPC 是否等待连接就绪（直到超时）而不是立即失败
+	MaxRequestsPerSecond  float64       // 每秒请求数上限，0 表示不限流
+	MaxConcurrentRequests int           // 同时进行的请求总权重上限，一元请求权重为 1，0 表示不限制
+	StreamRequestWeight   int           // 流调用占用的并发权重（默认 4），超过 MaxConcurrentRequests 时按其计算
+	StreamStaleThreshold  time.Duration // OpenAllStream 等待下一条消息（含服务端心跳）的最长时间，超过时判定对端失联并结束流；0 表示不检测
+	DisableAutoRequests   bool          // 是否禁用后台定时请求循环，仅通过 SendNow 按需发起请求
+	HandleSignals         bool          // Run 是否安装 SIGINT/SIGTERM 处理器并在收到信号时关闭（同时处理 SIGUSR1 和 SIGHUP）；库嵌入时通常保持 false，改用 RunContext
+	MaxRecvMsgSize        int           // 单条接收消息解压后的最大字节数，0 表示使用 gRPC 默认值（4MB），不能为负数
+	MaxSendMsgSize        int           // 单条发送消息压缩后的最大字节数，0 表示使用 gRPC 默认值（不限制），不能为负数
+	DrainTimeout          time.Duration // 关闭时等待进行中请求完成的最长时间（默认 5 秒）
+	CacheTTL              time.Duration // SendNow 响应缓存的有效期，与 CacheSize 同时大于 0 时启用；只缓存成功响应
+	CacheSize             int           // SendNow 响应缓存的最大条目数（按请求 name 缓存，LRU 淘汰）
+	MetricsLogInterval    time.Duration // 定期记录指标快照的间隔，0 表示不记录
+	DebugAddr             string        // 调试 HTTP 服务监听地址（例如 localhost:8081），提供 /debug/status、/debug/metrics、POST /debug/metrics/reset 和 /debug/pprof/；为空时不启动
+	ChannelzAddr          string        // channelz gRPC 服务监听地址（例如 localhost:50052），供 grpcdebug 查看子通道状态；为空时不启动
+	LogPayloads           bool          // 是否以调试级别记录请求和响应载荷，默认不记录
+	MaxLoggedPayloadSize  int           // 记录载荷时单个字段的最大长度（字节），超出部分截断（默认 256）
+
+	// 后台请求工作池，DispatchWorkers 为 0 时在主循环中同步执行请求，服务端变慢时请求间隔随之拉长
+	// 启用后主循环按间隔把请求提交到有界队列，由固定数量的 worker 执行；关闭时排队中请求的去留由 DispatchShutdownPolicy 决定
+	DispatchWorkers        int    // 执行后台请求的 worker 数
+	DispatchQueueSize      int    // 排队等待执行的请求上限（默认等于 DispatchWorkers）
+	DispatchOverflowPolicy string // 队列已满时的策略：drop-newest（默认，丢弃新请求）、drop-oldest（丢弃最早排队的请求）或 block（主循环等待）
+	DispatchShutdownPolicy string // 关闭时排队中请求的处理：cancel（默认，直接丢弃）或 drain（在关闭的排空时间内继续执行，到期后丢弃剩余请求）
+
+	// 传输层 keepalive，KeepAliveTime 为 0 时不启用
+	KeepAliveTime                time.Duration // 连接空闲多久后发送 HTTP/2 PING（gRPC 要求至少 10 秒）
+	KeepAliveTimeout             time.Duration // 等待 PING 响应的超时时间，超时后关闭连接（默认 20 秒）
+	KeepAlivePermitWithoutStream bool          // 没有活跃 RPC 时是否也发送 PING
+	EnableAppHealthCheck         bool          // 启用传输层 keepalive 后是否仍执行应用层 Ping 健康检查
+	HealthCheckMaxInterval       time.Duration // 健康检查连续失败时退避间隔的上限（默认 2 分钟），首次成功后恢复为 KeepAliveInterval
+
+	// OnStateChange 在客户端整体连接状态变化时调用，在锁外执行，可能从不同 goroutine 并发调用
+	OnStateChange func(old, new ConnectionState)
+	// OnCircuitBreakerStateChange 在熔断器状态变化时调用（包括 ForceOpen/ForceClose/Reset），在锁外执行
+	OnCircuitBreakerStateChange func(old, new CircuitBreakerState)
+
+	// 熔断器按错误率触发：最近 1 分钟（且在上一次状态切换之后）的请求数不少于 CircuitBreakerMinRequests 时，
+	// 错误率达到 CircuitBreakerErrorRate（0-1）即开启熔断，与连续 5 次失败的规则并存；为 0 时不启用
+	CircuitBreakerErrorRate   float64
+	CircuitBreakerMinRequests int // 按错误率触发所需的最少请求数（默认 20）
+
+	// 故障注入，用于测试重试和熔断器，ChaosErrorRate 和延迟均为 0 时不启用；健康检查不受影响
+	ChaosErrorRate     float64       // 注入错误的概率（0-1）
+	ChaosCodes         []codes.Code  // 注入的 gRPC 状态码，每次随机选取；为空时使用 Unavailable
+	ChaosLatency       time.Duration // 每次 RPC 前增加的固定延迟
+	ChaosLatencyJitter time.Duration // 在固定延迟之上额外增加的随机延迟上限
+
+	// 请求签名，HMACSecret 为空时不启用；服务端需配置相同的密钥和 metadata 键
+	HMACSecret          string // 共享密钥，用于对方法名、时间戳和请求体计算 HMAC-SHA256
+	HMACSignatureHeader string // 携带签名的 metadata 键，为空时使用 x-srpc-signature
+	HMACTimestampHeader string // 携带毫秒时间戳的 metadata 键，为空时使用 x-srpc-timestamp
+
+	// Clock 熔断器、请求重试退避、重连退避、健康检查和后台请求循环使用的时钟，为 nil 时使用真实时钟；测试中可传入 tools.FakeClock
+	Clock tools.Clock
+
+	// ServiceConfigJSON 标准 gRPC 服务配置（JSON），作为默认服务配置传给 grpc.WithDefaultServiceConfig，
+	// 用于声明式地配置按方法的超时、gRPC 原生重试策略（retryPolicy）和对冲（hedgingPolicy）；为空表示不使用
+	// 名称解析器（例如 DNS TXT 记录）返回的服务配置优先于该默认值；与 LoadBalancingPolicy 合并，二者不能同时指定负载均衡策略
+	// gRPC 原生重试发生在本客户端的每次尝试之内，受 PerAttemptTimeout 约束，与 MaxRetries 叠加，启用 retryPolicy 时通常应将 MaxRetries 设为 0
+	ServiceConfigJSON string
+
+	// DialOptions 追加在内置连接选项之后的原生 grpc.DialOption，例如测试中连接 bufconn 的 grpc.WithContextDialer
+	DialOptions []grpc.DialOption
+
+	// CredentialsProvider 提供传输层凭证，每次建立或重新建立连接时调用一次，可借此使用轮换后的证书；为 nil 时使用明文连接
+	// 从 PEM 文件加载 TLS 凭证可使用 FileCredentials；DialOptions 中的 grpc.WithTransportCredentials 会覆盖该设置
+	CredentialsProvider CredentialsProvider
+
+	// 附加到每个出站 RPC（包括健康检查）的 metadata，与请求 ID 合并而不是覆盖
+	Metadata     map[string]string                     // 静态 metadata，例如租户 ID
+	MetadataFunc func(ctx context.Context) metadata.MD // 每次调用时生成的动态 metadata，例如需要定期刷新的认证令牌；可能被并发调用
+
+	// OnReload 收到 SIGHUP 时调用（需启用 HandleSignals），参数为当前生效的运行时配置，返回值经校验后通过 UpdateRuntimeConfig 应用
+	// 为 nil 时忽略 SIGHUP；返回错误时保留原配置
+	OnReload func(current RuntimeConfig) (RuntimeConfig, error)
 }
 
 // GRPCClient gRPC 客户端
 type GRPCClient struct {
 	config          Config
-	conn            *grpc.ClientConn
-	greeter         pb.GreeterClient
-	ctx             context.Context
+	ctx             context.Context // 本次运行的 context，排空结束后取消
 	cancel          context.CancelFunc
+	stopCtx         context.Context // 关闭第一阶段取消，停止发起新请求
+	stop            context.CancelFunc
+	shutdownOnce    *sync.Once    // 保证每轮运行只关闭一次
+	runDone         chan struct{} // 本次 Run 返回时关闭
 	wg              sync.WaitGroup
-	stopChan        chan struct{}
 	mu              sync.RWMutex
 	isShutting      bool
-	connectionState ConnectionState   // 连接状态
+	running         bool              // Run 正在执行
+	pool            *connPool         // 连接池
+	connectionState ConnectionState   // 连接状态（连接池整体状态）
 	lastError       error             // 最后错误
-	reconnectCount  int               // 重连次数
 	circuitBreaker  *CircuitBreaker   // 熔断器
+	retryBudget     *retryBudget      // 全局重试预算
+	rateLimiter     *rate.Limiter     // 请求限流器，为 nil 时不限流
+	semaphore       *Semaphore        // 并发请求信号量，为 nil 时不限制
+	inflight        *inflightTracker  // 进行中的请求，关闭时等待其排空
 	slogger         *log.Slogger      // 日志记录器
 	metrics         *Metrics          // 指标收集器
 	idGenerator     tools.IDGenerator // ID 生成器（如果启用）
+	pingSequence    atomic.Uint64     // Ping 探测序列号
+	pingUnsupported atomic.Bool       // 服务端是否未实现 Ping
+	jitterMu        sync.Mutex        // 保护 jitterRand
+	jitterRand      *rand.Rand        // 请求间隔抖动使用的随机数源，创建时播种一次
+	adaptive        *adaptiveInterval // 自适应请求间隔，未启用时为 nil
+	cache           *responseCache    // SendNow 响应缓存，未启用时为 nil
+	clock           tools.Clock       // 时钟，默认为真实时钟
+	debugServer     *http.Server      // 调试 HTTP 服务，未启动时为 nil
+	channelzServer  *grpc.Server      // channelz 服务，未启动时为 nil
+	dispatcher      *tools.WorkerPool // 本次运行的后台请求工作池，未启用或主循环未运行时为 nil
+
+	newGreeter func(grpc.ClientConnInterface) greeterClient // 为新连接创建 Greeter 存根，测试中可替换
+
+	runtimeMu       sync.Mutex                    // 串行化 UpdateRuntimeConfig
+	runtime         atomic.Pointer[RuntimeConfig] // 可在运行时调整的配置，见 UpdateRuntimeConfig
+	intervalChanged chan struct{}                 // 请求间隔被调整时通知主循环重新计时
 }
 
+// 请求 ID 格式，对应 Config.RequestIDFormat
+const (
+	RequestIDFormatSimple   = "simple"   // 时间戳 + 节点 ID + 序列号组成的十进制整数
+	RequestIDFormatSimple62 = "simple62" // 与 simple 相同的 64 位 ID，以不超过 11 位的 base62 输出
+	RequestIDFormatUUID     = "uuid"     // 随机 UUID（版本4）
+	RequestIDFormatUUIDv7   = "uuidv7"   // RFC 9562 UUIDv7，按生成时间排序
+	RequestIDFormatULID     = "ulid"     // 26 位 Crockford Base32 ULID，按字典序排序
+	RequestIDFormatShort    = "short"    // 16 位十六进制随机 ID，最紧凑但不含时间信息
+)
+
 // NewGRPCClient 创建新的 gRPC 客户端
 func NewGRPCClient(config Config) (*GRPCClient, error) {
-	ctx, cancel := context.WithCancel(context.Background())
+	client, err := newClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// 建立 gRPC 连接
+	if err := client.connect(); err != nil {
+		// 释放 context 持有的资源，避免资源泄露
+		client.cancel()
+		return nil, fmt.Errorf("连接gRPC服务器失败: %w", err)
+	}
+
+	// 启动健康检查
+	client.startHealthChecker()
+
+	return client, nil
+}
 
+// newClient 校验配置、填充默认值并创建尚未连接的客户端
+func newClient(config Config) (*GRPCClient, error) {
+	// 消息大小限制为 0 时使用 gRPC 默认值，负数没有意义，直接拒绝以免静默回退
+	if config.MaxRecvMsgSize < 0 || config.MaxSendMsgSize < 0 {
+		return nil, fmt.Errorf("消息大小限制不能为负数: max_recv_msg_size=%d, max_send_msg_size=%d", config.MaxRecvMsgSize, config.MaxSendMsgSize)
+	}
 
 	// 设置压缩类型默认值
 	compressionType := config.CompressionType
@@ -60,23 +218,175 @@ func NewGRPCClient(config Config) (*GRPCClient, error) {
 		compressionType = "snappy" // 默认使用 snappy 压缩
 	}
 
+	// 设置重连退避默认值
+	if config.ReconnectBaseDelay <= 0 {
+		config.ReconnectBaseDelay = time.Second
+	}
+	if config.ReconnectMaxDelay <= 0 {
+		config.ReconnectMaxDelay = 30 * time.Second
+	}
+	if config.HealthCheckMaxInterval <= 0 {
+		config.HealthCheckMaxInterval = 2 * time.Minute
+	}
+
+	// 设置请求重试退避默认值
+	if config.RetryBaseDelay <= 0 {
+		config.RetryBaseDelay = time.Second
+	}
+	if config.RetryMaxDelay <= 0 {
+		config.RetryMaxDelay = 10 * time.Second
+	}
+	if config.RetryMultiplier <= 1 {
+		config.RetryMultiplier = 2
+	}
+
+	// 设置超时默认值
+	if config.PerAttemptTimeout <= 0 {
+		config.PerAttemptTimeout = 5 * time.Second
+	}
+	if config.OverallTimeout <= 0 {
+		config.OverallTimeout = 30 * time.Second
+	}
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = 10 * time.Second
+	}
+
+	// 设置重试预算默认值
+	if config.RetryBudgetRatio <= 0 {
+		config.RetryBudgetRatio = 0.1
+	}
+	if config.RetryBudgetMinReserve <= 0 {
+		config.RetryBudgetMinReserve = 10
+	}
+	if config.RetryBudgetMaxTokens <= 0 {
+		config.RetryBudgetMaxTokens = 100
+	}
+
+	// 校验后台请求工作池配置
+	if _, err := tools.ParseOverflowPolicy(config.DispatchOverflowPolicy); err != nil {
+		return nil, err
+	}
+	switch config.DispatchShutdownPolicy {
+	case "", DispatchShutdownCancel, DispatchShutdownDrain:
+	default:
+		return nil, fmt.Errorf("不支持的后台请求关闭策略: %q，可选 %s、%s", config.DispatchShutdownPolicy, DispatchShutdownCancel, DispatchShutdownDrain)
+	}
+	if config.DispatchQueueSize <= 0 {
+		config.DispatchQueueSize = max(config.DispatchWorkers, 1)
+	}
+
+	// 校验服务配置，避免到连接时才发现格式错误
+	serviceConfig, err := buildServiceConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateServiceConfig(serviceConfig); err != nil {
+		return nil, err
+	}
+
+	if config.HedgingDelay < 0 {
+		return nil, fmt.Errorf("对冲延迟不能为负数: %s", config.HedgingDelay)
+	}
+
+	// 校验熔断器错误率配置
+	if config.CircuitBreakerErrorRate < 0 || config.CircuitBreakerErrorRate > 1 {
+		return nil, fmt.Errorf("熔断错误率必须在 0-1 之间: %v", config.CircuitBreakerErrorRate)
+	}
+	if config.CircuitBreakerMinRequests <= 0 {
+		config.CircuitBreakerMinRequests = 20
+	}
+
+	// 设置流调用并发权重默认值
+	if config.StreamRequestWeight <= 0 {
+		config.StreamRequestWeight = defaultStreamRequestWeight
+	}
+
+	// 设置关闭排空超时默认值
+	if config.DrainTimeout <= 0 {
+		config.DrainTimeout = 5 * time.Second
+	}
+
+	// 设置载荷日志截断长度默认值
+	if config.MaxLoggedPayloadSize <= 0 {
+		config.MaxLoggedPayloadSize = defaultMaxLoggedPayloadSize
+	}
+
+	if config.IdempotencyKeys && !config.GenerateRequestID {
+		return nil, errors.New("幂等键取自请求 ID，启用 IdempotencyKeys 需要同时启用 GenerateRequestID")
+	}
+
 	// 初始化 ID 生成器（如果启用）
 	var idGenerator tools.IDGenerator
 	if config.GenerateRequestID {
-		idGenerator = tools.GetDefaultIDGenerator()
+		switch config.RequestIDFormat {
+		case "", RequestIDFormatSimple:
+			idGenerator = tools.GetDefaultIDGenerator()
+		case RequestIDFormatSimple62:
+			idGenerator = tools.Base62IDGenerator{Generator: tools.GetDefaultIDGenerator()}
+		case RequestIDFormatUUID:
+			idGenerator = tools.UUIDGenerator{}
+		case RequestIDFormatUUIDv7:
+			idGenerator = tools.GetUUIDv7Generator()
+		case RequestIDFormatULID:
+			idGenerator = tools.GetULIDGenerator()
+		case RequestIDFormatShort:
+			idGenerator = tools.ShortIDGenerator{}
+		default:
+			return nil, fmt.Errorf("不支持的请求 ID 格式: %q", config.RequestIDFormat)
+		}
+	}
+
+	clock := config.Clock
+	if clock == nil {
+		clock = tools.RealClock{}
+	}
+
+	metrics := NewMetrics()
+	metrics.setClock(clock)
+	cbOpts := []CircuitBreakerOption{WithCircuitBreakerClock(clock)}
+	if config.CircuitBreakerErrorRate > 0 {
+		cbOpts = append(cbOpts, WithErrorRateThreshold(config.CircuitBreakerErrorRate, config.CircuitBreakerMinRequests, func(since time.Time) (float64, int64) {
+			return metrics.windowErrorRate(time.Minute, since)
+		}))
 	}
 
 	client := &GRPCClient{
 		config:          config,
-		ctx:             ctx,
-		cancel:          cancel,
-		stopChan:        make(chan struct{}),
 		connectionState: StateDisconnected,
-		reconnectCount:  0,
-		circuitBreaker:  NewCircuitBreaker(5, 3, 30*time.Second), // 5次失败触发，3次成功恢复，开启30秒
+		pool:            newConnPool(config.PoolSize),
+		circuitBreaker:  NewCircuitBreaker(5, 3, 30*time.Second, cbOpts...), // 5次失败触发，3次成功恢复，开启30秒
+		retryBudget:     newRetryBudget(config.RetryBudgetRatio, config.RetryBudgetMinReserve, config.RetryBudgetMaxTokens, clock),
+		rateLimiter:     newRateLimiter(config.MaxRequestsPerSecond),
+		inflight:        newInflightTracker(),
+		semaphore:       newRequestSemaphore(config.MaxConcurrentRequests),
 		slogger:         log.NewLogger(),
-		metrics:         NewMetrics(),
+		metrics:         metrics,
 		idGenerator:     idGenerator,
+		jitterRand:      newJitterRand(config.JitterSeed),
+		clock:           clock,
+		cache:           newResponseCache(config.CacheTTL, config.CacheSize, clock),
+		newGreeter:      newGreeterClient,
+		intervalChanged: make(chan struct{}, 1),
+	}
+	if config.AdaptiveInterval {
+		client.adaptive = newAdaptiveInterval()
+	}
+	client.metrics.semaphore = client.semaphore
+	client.runtime.Store(&RuntimeConfig{
+		RequestInterval: config.RequestInterval,
+		JitterPercent:   config.JitterPercent,
+		MaxRetries:      config.MaxRetries,
+	})
+	client.resetRunState()
+	client.circuitBreaker.SetStateChangeCallback(client.onCircuitBreakerStateChange)
+
+	if cfg := client.chaosConfig(); cfg.Enabled() {
+		client.slogger.Warn("已启用故障注入，仅用于测试", map[string]interface{}{
+			"error_rate":     cfg.ErrorRate,
+			"codes":          cfg.Codes,
+			"latency":        cfg.Latency.String(),
+			"latency_jitter": cfg.LatencyJitter.String(),
+		})
 	}
 
 	// 更新配置中的压缩类型（如果启用了压缩但类型为空）
@@ -84,78 +394,258 @@ func NewGRPCClient(config Config) (*GRPCClient, error) {
 		client.config.CompressionType = "snappy"
 	}
 
-	// 建立 gRPC 连接
-	if err := client.connect(); err != nil {
-		// 释放 context 持有的资源，避免资源泄露
-		cancel()
-		return nil, fmt.Errorf("连接gRPC服务器失败: %v", err)
-	}
-
-	// 启动健康检查
-	client.startHealthChecker()
-
 	return client, nil
 }
 
-// Run 启动客户端主循环
+// ErrAlreadyRunning 客户端已在运行，不能重复调用 Run
+var ErrAlreadyRunning = errors.New("客户端已在运行")
+
+// Run 启动客户端主循环，阻塞直到 Shutdown 完成并清理资源
+// Shutdown 后（包括在 Run 之前调用 Shutdown）可以再次调用 Run 重新连接并恢复运行，累计的指标会保留
 func (c *GRPCClient) Run() error {
-	// 启动信号处理
-	c.setupSignalHandler()
+	return c.RunContext(context.Background())
+}
 
-	// 启动主工作 goroutine
-	c.wg.Add(1)
-	go c.mainLoop()
+// RunContext 与 Run 相同，但 ctx 取消时会触发 Shutdown，便于由调用方的生命周期驱动关闭
+func (c *GRPCClient) RunContext(ctx context.Context) error {
+	c.mu.Lock()
+	if c.running {
+		c.mu.Unlock()
+		return ErrAlreadyRunning
+	}
+	c.running = true
+	restart := c.isShutting
+	runDone := make(chan struct{})
+	c.runDone = runDone
+	c.mu.Unlock()
 
-	// 等待终止
+	defer func() {
+		c.mu.Lock()
+		c.running = false
+		c.mu.Unlock()
+		close(runDone)
+	}()
+
+	// 上一次运行已关闭，重建本次运行的状态并重新连接
+	if restart {
+		if err := c.restart(); err != nil {
+			return err
+		}
+	}
+
+	// 启动信号处理（库嵌入时通常由应用自行处理信号）
+	if c.config.HandleSignals {
+		c.setupSignalHandler()
+	}
+
+	// 定期记录指标快照
+	c.startMetricsReporter()
+
+	// 启动调试 HTTP 服务和 channelz 服务
+	c.startDebugServer()
+	c.startChannelzServer()
+
+	// 调用方的 ctx 取消时关闭客户端
+	runCtx := c.ctx
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.slogger.Info("调用方 context 已取消，开始关闭")
+			c.Shutdown()
+		case <-runCtx.Done():
+		}
+	}()
+
+	// 启动主工作 goroutine，禁用自动请求时只保持连接和健康检查，由调用方通过 SendNow 按需发起请求
+	if c.config.DisableAutoRequests {
+		c.slogger.Info("已禁用自动请求，仅处理按需调用")
+	} else {
+		c.wg.Add(1)
+		go c.mainLoop()
+	}
+
+	// 等待终止：先等待关闭信号，不依赖是否有后台 goroutine 在运行
+	<-c.ctx.Done()
 	c.wg.Wait()
 
 	// 清理资源
 	return c.cleanup()
 }
 
-// setupSignalHandler 设置信号处理器
+// resetRunState 创建新一轮运行的 context 和关闭控制，调用方需持有 c.mu 或尚未共享 c
+// stopCtx 在关闭的第一阶段取消，使主循环停止发起新请求；ctx 在排空结束后取消，中止剩余请求和后台 goroutine
+func (c *GRPCClient) resetRunState() {
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	c.stopCtx, c.stop = context.WithCancel(c.ctx)
+	c.shutdownOnce = &sync.Once{}
+	c.isShutting = false
+}
+
+// restart 为新一轮运行重建 context 和连接，并重新启动健康检查
+func (c *GRPCClient) restart() error {
+	// 等待可能仍在进行的上一次关闭完成，避免其清理与新连接交错
+	c.mu.RLock()
+	once := c.shutdownOnce
+	c.mu.RUnlock()
+	once.Do(func() {})
+
+	c.slogger.Info("客户端重新启动")
+
+	c.mu.Lock()
+	c.resetRunState()
+	c.mu.Unlock()
+
+	if err := c.connect(); err != nil {
+		// 连接失败时回到已关闭状态，调用方可以稍后再次调用 Run
+		// 此时 Run 尚未进入主流程，不能经由 Shutdown 等待 Run 返回
+		c.shutdownOnce.Do(func() {
+			c.mu.Lock()
+			c.isShutting = true
+			c.mu.Unlock()
+			c.cancel()
+		})
+		c.wg.Wait()
+		c.cleanup()
+		return fmt.Errorf("连接gRPC服务器失败: %w", err)
+	}
+	c.startHealthChecker()
+	return nil
+}
+
+// setupSignalHandler 设置信号处理器，本次运行结束后自动注销
+// SIGINT/SIGTERM 触发关闭，SIGUSR1 记录一次指标快照而不关闭，SIGHUP 通过 Config.OnReload 重新加载运行时配置
 func (c *GRPCClient) setupSignalHandler() {
 	signalChan := make(chan os.Signal, 1)
-	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGUSR1, syscall.SIGHUP)
 
+	ctx := c.ctx
 	go func() {
-		sig := <-signalChan
-		c.slogger.Info("收到信号，开始关闭", map[string]interface{}{"signal": sig})
-		c.Shutdown()
+		defer signal.Stop(signalChan)
+		for {
+			select {
+			case sig := <-signalChan:
+				switch sig {
+				case syscall.SIGUSR1:
+					c.logMetrics("收到 SIGUSR1，输出指标快照")
+					continue
+				case syscall.SIGHUP:
+					c.reloadConfig()
+					continue
+				}
+				c.slogger.Info("收到信号，开始关闭", map[string]interface{}{"signal": sig})
+				c.Shutdown()
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
 	}()
 }
 
-// Shutdown 关闭客户端
+// Shutdown 关闭客户端，最多等待 DrainTimeout 让进行中的请求完成
 func (c *GRPCClient) Shutdown() {
-	c.mu.Lock()
-	if c.isShutting {
-		c.mu.Unlock()
-		return
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.DrainTimeout)
+	defer cancel()
+	c.ShutdownContext(ctx)
+}
+
+// ShutdownGracefully 关闭客户端，最多等待 timeout 让进行中的请求完成，超时后取消剩余请求
+// 与 Shutdown 相同，只是排空时间由调用方指定而不是 DrainTimeout
+func (c *GRPCClient) ShutdownGracefully(timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	c.ShutdownContext(ctx)
+}
+
+// ShutdownContext 分两阶段关闭客户端：
+// 先停止发起新请求并等待进行中的请求完成，ctx 到期后再取消剩余请求并关闭连接
+//
+// 语义约定：
+//   - Run 之前调用：直接关闭 NewGRPCClient 建立的连接，之后调用 Run 会重新连接
+//   - Run 期间调用：关闭完成且 Run 清理完资源后返回
+//   - 并发或重复调用：只有第一次调用执行关闭，其余调用阻塞到关闭完成后返回
+//
+// 不能在 OnStateChange 回调中同步调用，否则会等待回调所在的 goroutine 退出而死锁，应使用 go c.Shutdown()
+func (c *GRPCClient) ShutdownContext(ctx context.Context) {
+	c.mu.RLock()
+	once := c.shutdownOnce
+	c.mu.RUnlock()
+
+	once.Do(func() { c.shutdown(ctx) })
+
+	// Run 正在执行时等待其完成清理
+	c.mu.RLock()
+	running, runDone := c.running, c.runDone
+	c.mu.RUnlock()
+	if running {
+		<-runDone
 	}
+}
+
+// shutdown 执行一次两阶段关闭，由 shutdownOnce 保证每轮运行只执行一次
+func (c *GRPCClient) shutdown(ctx context.Context) {
+	c.mu.Lock()
 	c.isShutting = true
+	running := c.running
+	stop, cancel := c.stop, c.cancel
 	c.mu.Unlock()
 
 	c.slogger.Info("开始关闭")
 
-	// 发送停止信号
-	c.cancel()
+	// 第一阶段：停止主循环发起新请求，等待进行中的请求排空
+	stop()
+	c.drainDispatcher(ctx)
+	pending := c.inflight.current()
+	if pending > 0 {
+		c.slogger.Info("等待进行中的请求完成", map[string]interface{}{"inflight": pending})
+	}
+	aborted := 0
+	if !c.inflight.wait(ctx) {
+		aborted = c.inflight.current()
+		c.slogger.Warn("排空超时，取消剩余请求", map[string]interface{}{"aborted": aborted})
+	}
+	c.metrics.RecordShutdownDrain(max(pending-aborted, 0), aborted)
 
-	// 等待主循环退出
-	close(c.stopChan)
+	// 第二阶段：取消 context，中止剩余请求并停止后台 goroutine
+	cancel()
 
-	// 等待所有 goroutine 完成
-	c.wg.Wait()
+	// Run 正在执行时由 Run 等待后台 goroutine 并清理连接，否则在此完成
+	if !running {
+		c.wg.Wait()
+		if err := c.cleanup(); err != nil {
+			c.slogger.Error("清理资源失败", map[string]interface{}{"error": err.Error()})
+		}
+	}
 }
 
-// cleanup 清理资源
+// cleanup 清理本次运行的资源，关闭并重置所有连接，以便再次 Run 时重新连接
 func (c *GRPCClient) cleanup() error {
 	c.slogger.Info("清理资源")
+	c.stopDebugServer()
+	c.stopChannelzServer()
 
-	if c.conn != nil {
-		if err := c.conn.Close(); err != nil {
-			return fmt.Errorf("关闭gRPC连接失败: %v", err)
+	var closeErr error
+	for _, pc := range c.pool.conns {
+		c.mu.Lock()
+		conn := pc.conn
+		pc.conn = nil
+		pc.greeter = nil
+		pc.state = StateDisconnected
+		old, current := c.refreshStateLocked()
+		c.mu.Unlock()
+		c.notifyStateChange(old, current)
+		if conn == nil {
+			continue
 		}
-		c.slogger.Info("gRPC 连接已关闭")
+		if err := conn.Close(); err != nil {
+			closeErr = fmt.Errorf("关闭gRPC连接失败: %v", err)
+			continue
+		}
+		c.slogger.Info("gRPC 连接已关闭", map[string]interface{}{"conn_index": pc.index})
+	}
+	c.logMetrics("最终指标汇总")
+	if closeErr != nil {
+		return closeErr
 	}
 
 	c.slogger.Info("客户端已完全关闭")
@@ -167,6 +657,13 @@ func (c *GRPCClient) GetConfig() Config {
 	return c.config
 }
 
+// runContext 返回本次运行的 context，供可能与重启并发的按需调用使用
+func (c *GRPCClient) runContext() context.Context {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.ctx
+}
+
 // IsShutting 检查是否正在关闭
 func (c *GRPCClient) IsShutting() bool {
 	c.mu.RLock()
@@ -0,0 +1,210 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"srpc/client/mocks"
+	"srpc/pkg/tools"
+	pb "srpc/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// waitWaiters 等待至少 n 个 goroutine 在 clock 上进入等待，之后再 Advance 才能确定触发的是哪次等待
+func waitWaiters(t *testing.T, clock *tools.FakeClock, n int) {
+	t.Helper()
+	waitFor(t, 5*time.Second, "goroutine 进入时钟等待", func() bool { return clock.Waiters() >= n })
+}
+
+// assertStill 短暂等待后检查 cond 仍成立，用于确认时钟未推进时没有提前触发
+func assertStill(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	time.Sleep(20 * time.Millisecond)
+	if !cond() {
+		t.Fatalf("时钟未推进时%s", what)
+	}
+}
+
+func TestCircuitBreakerOpenDurationFollowsClock(t *testing.T) {
+	clock := tools.NewFakeClock(time.Unix(0, 0))
+	cb := NewCircuitBreaker(2, 2, 30*time.Second, WithCircuitBreakerClock(clock))
+
+	cb.RecordFailure()
+	cb.RecordFailure()
+	if cb.GetState() != CBStateOpen {
+		t.Fatalf("连续失败后状态 = %v，期望开启", cb.GetState())
+	}
+
+	clock.Advance(30*time.Second - time.Nanosecond)
+	if cb.AllowRequest() {
+		t.Fatal("开启时长未到时不应放行请求")
+	}
+	clock.Advance(time.Nanosecond)
+	if !cb.AllowRequest() || cb.GetState() != CBStateHalfOpen {
+		t.Fatalf("开启时长到期后状态 = %v，期望半开并放行", cb.GetState())
+	}
+
+	// 半开状态失败重新开启，并从此刻重新计时
+	cb.RecordFailure()
+	clock.Advance(29 * time.Second)
+	if cb.AllowRequest() {
+		t.Fatal("重新开启后未满开启时长不应放行请求")
+	}
+	clock.Advance(time.Second)
+	if !cb.AllowRequest() {
+		t.Fatal("重新开启的时长到期后应半开放行")
+	}
+	cb.RecordSuccess()
+	cb.RecordSuccess()
+	if cb.GetState() != CBStateClosed {
+		t.Errorf("半开状态连续成功后状态 = %v，期望关闭", cb.GetState())
+	}
+}
+
+func TestRetryBudgetRefillFollowsClock(t *testing.T) {
+	clock := tools.NewFakeClock(time.Unix(0, 0))
+	b := newRetryBudget(0.1, 2, 10, clock)
+
+	if !b.withdraw() || !b.withdraw() {
+		t.Fatal("初始预算应允许 2 次重试")
+	}
+	if b.withdraw() {
+		t.Fatal("预算耗尽后不应允许重试")
+	}
+	// 不足一秒时不补充
+	clock.Advance(999 * time.Millisecond)
+	if b.withdraw() {
+		t.Fatal("时钟推进不足一秒时不应补充令牌")
+	}
+	// 满一秒后补充到最低保留量
+	clock.Advance(time.Millisecond)
+	if !b.withdraw() || !b.withdraw() {
+		t.Fatal("推进一秒后应补充到最低保留量")
+	}
+	if b.withdraw() {
+		t.Fatal("补充的令牌用完后不应允许重试")
+	}
+}
+
+func TestRetryBackoffWaitsOnClock(t *testing.T) {
+	clock := tools.NewFakeClock(time.Unix(0, 0))
+	cfg := mockConfig()
+	cfg.Clock = clock
+	cfg.MaxRetries = 1
+	cfg.RetryBaseDelay = time.Second
+	cfg.RetryMaxDelay = time.Second
+	greeter := &mocks.Greeter{SayHelloFunc: mocks.SayHelloSequence(status.Error(codes.Unavailable, "服务不可用"), nil)}
+	c := newMockClient(t, cfg, greeter)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.SendNow(context.Background(), "clock")
+		done <- err
+	}()
+
+	// 健康检查和重试退避各占一个等待
+	waitFor(t, 5*time.Second, "第一次尝试", func() bool { return greeter.Calls("SayHello") == 1 })
+	waitWaiters(t, clock, 2)
+	assertStill(t, "已经重试", func() bool { return greeter.Calls("SayHello") == 1 })
+
+	clock.Advance(time.Second)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("重试后 SendNow 失败: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("推进时钟后重试未完成")
+	}
+	if got := greeter.Calls("SayHello"); got != 2 {
+		t.Errorf("SayHello 调用 %d 次，期望 2", got)
+	}
+}
+
+func TestMainLoopIntervalFollowsClock(t *testing.T) {
+	clock := tools.NewFakeClock(time.Unix(0, 0))
+	cfg := mockConfig()
+	cfg.Clock = clock
+	cfg.RequestInterval = 10 * time.Second
+	greeter := &mocks.Greeter{}
+	c := newMockClient(t, cfg, greeter)
+	runClient(c)
+
+	calls := func() int { return greeter.Calls("SayHello") }
+	for want := 1; want <= 3; want++ {
+		// 健康检查和主循环各占一个等待
+		waitWaiters(t, clock, 2)
+		clock.Advance(cfg.RequestInterval - time.Millisecond)
+		assertStill(t, "提前发起请求", func() bool { return calls() == want-1 })
+
+		clock.Advance(time.Millisecond)
+		waitFor(t, 5*time.Second, "主循环发起请求", func() bool { return calls() == want })
+	}
+}
+
+// pingServer 只实现 Ping 的 Greeter 服务端
+type pingServer struct {
+	pb.UnimplementedGreeterServer
+}
+
+func (pingServer) Ping(_ context.Context, in *pb.PingRequest) (*pb.PingReply, error) {
+	return &pb.PingReply{Sequence: in.GetSequence()}, nil
+}
+
+func TestReconnectBackoffFollowsClock(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	pb.RegisterGreeterServer(srv, pingServer{})
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	// refuse 为 true 时拨号失败，模拟服务端不可达
+	var refuse atomic.Bool
+	clock := tools.NewFakeClock(time.Unix(0, 0))
+	cfg := Config{
+		ServerAddr: "passthrough:///bufnet",
+		DialOptions: []grpc.DialOption{grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			if refuse.Load() {
+				return nil, errors.New("拒绝连接")
+			}
+			return lis.DialContext(ctx)
+		})},
+		DialTimeout:        100 * time.Millisecond,
+		KeepAliveInterval:  time.Hour,
+		RequestInterval:    time.Hour,
+		ReconnectBaseDelay: 5 * time.Second,
+		ReconnectMaxDelay:  5 * time.Second,
+		Clock:              clock,
+	}
+	c, err := NewGRPCClient(cfg)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	t.Cleanup(c.Shutdown)
+
+	pc := c.pool.conns[0]
+	refuse.Store(true)
+	c.mu.Lock()
+	pc.state = StateDisconnected
+	c.mu.Unlock()
+	c.startReconnect(pc, StateDisconnected)
+
+	// 第一次重连立即拨号并失败，随后在时钟上等待退避；健康检查占另一个等待
+	waitWaiters(t, clock, 2)
+	refuse.Store(false)
+	assertStill(t, "已经重连", func() bool { return c.ConnectionState() != StateConnected })
+
+	clock.Advance(cfg.ReconnectMaxDelay)
+	waitFor(t, 5*time.Second, "退避后重连成功", func() bool { return c.GetMetrics().Connections[0].Reconnects == 1 })
+	if got := c.ConnectionState(); got != StateConnected {
+		t.Errorf("重连后连接状态 = %v，期望 %v", got, StateConnected)
+	}
+}
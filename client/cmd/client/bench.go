@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"srpc/client"
+)
+
+// 压测模式参数，与其他参数一样支持环境变量
+var (
+	benchMode     bool
+	benchOptions  client.BenchOptions
+	benchWorkload string
+)
+
+func init() {
+	boolVar(&benchMode, "bench", "BENCH_MODE", false, "以压测模式运行：按目标 QPS 发送请求，结束后打印报告并退出")
+	intVar(&benchOptions.QPS, "bench-qps", "BENCH_QPS", 100, "压测目标 QPS，0 表示不限速")
+	intVar(&benchOptions.Workers, "bench-workers", "BENCH_WORKERS", 10, "压测并发 worker 数")
+	durationVar(&benchOptions.Duration, "bench-duration", "BENCH_DURATION_SEC", time.Second, 30, "压测时长")
+	stringVar(&benchWorkload, "bench-workload", "BENCH_WORKLOAD", string(client.BenchUnary), "压测负载类型，unary 或 stream")
+}
+
+// runBench 执行压测并将报告打印到标准输出，SIGINT/SIGTERM 会提前结束压测并打印已完成部分的报告
+func runBench(grpcClient *client.GRPCClient) int {
+	defer grpcClient.Shutdown()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	benchOptions.Workload = client.BenchWorkload(benchWorkload)
+	report, err := grpcClient.RunBench(ctx, benchOptions)
+	if err != nil && ctx.Err() == nil {
+		slog.Error("压测失败", "error", err)
+		return 1
+	}
+
+	fmt.Print(report.String())
+	return 0
+}
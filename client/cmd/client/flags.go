@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"srpc/client"
+)
+
+// 以下辅助函数注册与环境变量对应的命令行参数：参数默认值取自环境变量（未设置时使用配置文件中的值，再其次是内置默认值），
+// 因此显式传入的参数优先于环境变量，环境变量优先于配置文件，配置文件优先于内置默认值
+
+// 指定配置文件的命令行参数和环境变量
+const (
+	configFileFlag = "config"
+	configFileEnv  = "CONFIG_FILE"
+)
+
+// fileFields 配置文件中设置过的 Config 字段，键为字段指针；未使用配置文件时为空
+var fileFields = make(map[any]bool)
+
+// applyConfigFile 将 -config 参数或 CONFIG_FILE 环境变量指定的配置文件写入 config，须在注册其他参数之前调用
+// 参数尚未解析，因此直接从 os.Args 中查找 -config
+func applyConfigFile(config *client.Config) error {
+	path := getEnv(configFileEnv, "")
+	for i, arg := range os.Args[1:] {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || name != configFileFlag {
+			continue
+		}
+		if !hasValue && i+2 < len(os.Args) {
+			value = os.Args[i+2]
+		}
+		path = value
+	}
+	flag.String(configFileFlag, path, envUsage("YAML 或 JSON 配置文件路径，键为 Config 字段名", configFileEnv))
+	if path == "" {
+		return nil
+	}
+
+	names, err := client.ApplyConfigFile(path, config)
+	if err != nil {
+		return err
+	}
+	v := reflect.ValueOf(config).Elem()
+	for _, name := range names {
+		fileFields[v.FieldByName(name).Addr().Interface()] = true
+	}
+	slog.Info("已加载配置文件", "path", path, "fields", len(names))
+	return nil
+}
+
+// fileDefault 返回配置文件中的值（若设置过），否则返回内置默认值
+func fileDefault[T any](p *T, defaultValue T) T {
+	if fileFields[p] {
+		return *p
+	}
+	return defaultValue
+}
+
+// stringVar 注册字符串参数
+func stringVar(p *string, name, env, defaultValue, usage string) {
+	flag.StringVar(p, name, getEnv(env, fileDefault(p, defaultValue)), envUsage(usage, env))
+}
+
+// intVar 注册整数参数
+func intVar(p *int, name, env string, defaultValue int, usage string) {
+	flag.IntVar(p, name, getEnvAsInt(env, fileDefault(p, defaultValue)), envUsage(usage, env))
+}
+
+// floatVar 注册浮点数参数
+func floatVar(p *float64, name, env string, defaultValue float64, usage string) {
+	flag.Float64Var(p, name, getEnvAsFloat(env, fileDefault(p, defaultValue)), envUsage(usage, env))
+}
+
+// boolVar 注册布尔参数，默认值为 true 的参数可通过 -name=false 关闭
+func boolVar(p *bool, name, env string, defaultValue bool, usage string) {
+	flag.BoolVar(p, name, getEnvAsBool(env, fileDefault(p, defaultValue)), envUsage(usage, env))
+}
+
+// durationVar 注册时长参数，命令行和配置文件接受 30s、500ms 等格式，环境变量沿用以 unit 为单位的整数
+func durationVar(p *time.Duration, name, env string, unit time.Duration, defaultValue int, usage string) {
+	unitName := "秒"
+	if unit == time.Millisecond {
+		unitName = "毫秒"
+	}
+	value := fileDefault(p, time.Duration(defaultValue)*unit)
+	if os.Getenv(env) != "" {
+		value = time.Duration(getEnvAsInt(env, int(value/unit))) * unit
+	}
+	flag.DurationVar(p, name, value, fmt.Sprintf("%s（环境变量 %s，单位%s）", usage, env, unitName))
+}
+
+// envUsage 在参数说明后附上对应的环境变量名
+func envUsage(usage, env string) string {
+	return fmt.Sprintf("%s（环境变量 %s）", usage, env)
+}
+
+// usage 打印帮助信息，列出的默认值已合并当前环境变量
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "用法: %s [参数]\n\n", os.Args[0])
+	fmt.Fprintln(out, "长期运行的 gRPC 客户端，按固定间隔向服务端发送 SayHello 请求。")
+	fmt.Fprintln(out, "配置优先级：命令行参数 > 环境变量 > 配置文件 > 内置默认值，下列默认值已包含当前环境变量和配置文件的取值。")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "参数:")
+	flag.PrintDefaults()
+}
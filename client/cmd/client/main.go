@@ -1,82 +1,325 @@
 package main
 
 import (
+	"compress/flate"
+	"context"
+	"flag"
+	"fmt"
 	"log/slog"
 	"os"
+	"runtime"
 	"strconv"
+	"strings"
 	"time"
 
 	"srpc/client"
-	_ "srpc/pkg/compress" // 确保压缩器被注册
+	"srpc/pkg/chaos"
+	"srpc/pkg/compress" // 同时确保压缩器被注册
+	"srpc/pkg/tools"
+	"srpc/pkg/tracing"
+)
+
+var (
+	showVersion = flag.Bool("version", false, "打印版本信息后退出")
+	once        = flag.Bool("once", false, "发送一次请求后退出，请求失败时以非零状态码退出，可用于 CI 冒烟测试")
 )
 
 func main() {
-	slog.Info("启动gRPC客户端")
+	os.Exit(run())
+}
 
+// run 运行客户端并返回进程退出码，使 defer 的清理逻辑在退出前执行
+func run() int {
 	// 读取配置
-	config := loadConfig()
+	config, err := loadConfig()
+	if err != nil {
+		slog.Error("加载配置文件失败", "error", err)
+		return 1
+	}
+
+	if *showVersion {
+		fmt.Printf("srpc-client %s (commit %s, %s %s/%s)\n",
+			client.Version, client.GitCommit, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+		return 0
+	}
+
+	slog.Info("启动gRPC客户端")
+	nodeID, source := tools.DefaultNodeID()
+	slog.Info("ID 生成器节点 ID", "node_id", nodeID, "source", source)
+
+	// 初始化链路追踪导出器，导出配置来自标准 OTEL_* 环境变量
+	if config.EnableTracing {
+		shutdownTracing, err := tracing.Setup(context.Background(), "srpc-client")
+		if err != nil {
+			slog.Error("初始化链路追踪失败", "error", err)
+			return 1
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			shutdownTracing(ctx)
+		}()
+	}
+
+	// 单次请求和压测模式不需要后台请求循环
+	if *once || benchMode {
+		config.DisableAutoRequests = true
+	}
 
 	// 创建客户端
 	grpcClient, err := client.NewGRPCClient(config)
 	if err != nil {
 		slog.Error("创建gRPC客户端失败", "error", err)
-		os.Exit(1)
+		return 1
+	}
+
+	if *once {
+		return sendOnce(grpcClient)
+	}
+	if benchMode {
+		return runBench(grpcClient)
 	}
 
 	// 运行客户端
 	if err := grpcClient.Run(); err != nil {
 		slog.Error("客户端运行失败", "error", err)
-		os.Exit(1)
+		return 1
 	}
 
 	slog.Info("客户端已正常退出")
+	return 0
+}
+
+// sendOnce 发送一次 SayHello 请求后关闭客户端，请求失败时返回非零退出码
+func sendOnce(grpcClient *client.GRPCClient) int {
+	defer grpcClient.Shutdown()
+
+	reply, err := grpcClient.SendNow(context.Background(), fmt.Sprintf("Client-%d", time.Now().Unix()))
+	if err != nil {
+		slog.Error("单次请求失败", "error", err)
+		return 1
+	}
+
+	slog.Info("单次请求成功", "message", reply.GetMessage())
+	return 0
 }
 
-// loadConfig 从环境变量加载配置
-func loadConfig() client.Config {
-	// 获取服务器地址，默认为localhost:50051
-	serverAddr := getEnv("GRPC_SERVER_ADDR", "localhost:50051")
+// loadConfig 从命令行参数、环境变量和配置文件加载配置，优先级：命令行参数 > 环境变量 > 配置文件 > 默认值
+func loadConfig() (client.Config, error) {
+	var config client.Config
+	if err := applyConfigFile(&config); err != nil {
+		return config, err
+	}
+
+	// 服务器地址，默认为localhost:50051
+	stringVar(&config.ServerAddr, "server-addr", "GRPC_SERVER_ADDR", "localhost:50051", "gRPC 服务器地址，支持 unix:///path/to.sock")
+
+	// 请求间隔，默认为30秒
+	durationVar(&config.RequestInterval, "request-interval", "REQUEST_INTERVAL_SEC", time.Second, 30, "请求间隔")
+
+	// 最大重试次数，默认为3
+	intVar(&config.MaxRetries, "max-retries", "MAX_RETRIES", 3, "最大重试次数")
+
+	// 健康检查间隔，默认为 20 秒；连续失败时逐次翻倍，最长 2 分钟
+	durationVar(&config.KeepAliveInterval, "keep-alive-interval", "KEEP_ALIVE_SEC", time.Second, 20, "健康检查间隔")
+	durationVar(&config.HealthCheckMaxInterval, "health-check-max-interval", "HEALTH_CHECK_MAX_INTERVAL_SEC", time.Second, 120, "健康检查连续失败时退避间隔的上限")
+
+	// 传输层 keepalive 配置，默认空闲 30 秒发送 PING，10 秒未响应视为断开
+	durationVar(&config.KeepAliveTime, "grpc-keepalive-time", "GRPC_KEEPALIVE_TIME_SEC", time.Second, 30, "传输层 keepalive 间隔，0 表示不启用")
+	durationVar(&config.KeepAliveTimeout, "grpc-keepalive-timeout", "GRPC_KEEPALIVE_TIMEOUT_SEC", time.Second, 10, "等待 keepalive PING 响应的超时")
+	boolVar(&config.KeepAlivePermitWithoutStream, "grpc-keepalive-permit-without-stream", "GRPC_KEEPALIVE_PERMIT_WITHOUT_STREAM", true, "没有活跃 RPC 时是否也发送 keepalive PING")
+
+	// 是否在传输层 keepalive 之外保留应用层 Ping 健康检查，默认为 false
+	boolVar(&config.EnableAppHealthCheck, "enable-app-health-check", "ENABLE_APP_HEALTH_CHECK", false, "启用传输层 keepalive 后是否仍执行应用层 Ping 健康检查")
+
+	// 抖动百分比，默认为10%（0-100）
+	intVar(&config.JitterPercent, "jitter-percent", "JITTER_PERCENT", 10, "请求间隔抖动百分比（0-100）")
+
+	// 是否根据请求结果自适应调整请求间隔，默认为 false
+	boolVar(&config.AdaptiveInterval, "adaptive-interval", "ADAPTIVE_INTERVAL", false, "失败或熔断时自动拉长请求间隔，成功后逐步恢复")
+
+	// 是否启用压缩，默认为 false
+	boolVar(&config.EnableCompression, "enable-compression", "ENABLE_COMPRESSION", false, "是否启用压缩")
+
+	// 压缩类型，默认为 snappy
+	stringVar(&config.CompressionType, "compression-type", "COMPRESSION_TYPE", "snappy", "压缩类型，snappy 或 deflate")
+
+	// deflate 压缩级别，仅在使用 deflate 压缩时生效，默认为 -1（compress/flate 默认级别）
+	var deflateLevel int
+	intVar(&deflateLevel, "deflate-level", "DEFLATE_LEVEL", flate.DefaultCompression, "deflate 压缩级别，取值 -2 到 9")
+
+	// 是否生成请求ID，默认为 true
+	boolVar(&config.GenerateRequestID, "generate-request-id", "GENERATE_REQUEST_ID", true, "是否为每个请求生成唯一 ID")
+
+	// 请求ID格式，默认为 simple
+	stringVar(&config.RequestIDFormat, "request-id-format", "REQUEST_ID_FORMAT", client.RequestIDFormatSimple, "请求 ID 格式，simple、simple62、uuid、uuidv7、ulid 或 short")
+
+	// 是否附加幂等键，默认为 false；服务端需启用去重
+	boolVar(&config.IdempotencyKeys, "idempotency-keys", "IDEMPOTENCY_KEYS", false, "是否为 SayHello 附加取自请求 ID 的幂等键，供服务端去重")
+
+	// 负载均衡策略，默认为空（使用 pick_first）
+	stringVar(&config.LoadBalancingPolicy, "load-balancing-policy", "LOAD_BALANCING_POLICY", "", "负载均衡策略，例如 round_robin")
 
-	// 获取请求间隔，默认为30秒
-	requestIntervalSec := getEnvAsInt("REQUEST_INTERVAL_SEC", 30)
-	requestInterval := time.Duration(requestIntervalSec) * time.Second
+	// gRPC 服务配置 JSON，默认为空
+	stringVar(&config.ServiceConfigJSON, "service-config-json", "SERVICE_CONFIG_JSON", "", "gRPC 服务配置 JSON，可声明按方法的超时、retryPolicy 和 hedgingPolicy")
 
-	// 获取最大重试次数，默认为3
-	maxRetries := getEnvAsInt("MAX_RETRIES", 3)
+	// 是否启用链路追踪，默认为 false
+	boolVar(&config.EnableTracing, "enable-tracing", "ENABLE_TRACING", false, "是否启用 OpenTelemetry 链路追踪")
 
-	// 获取连接保活间隔，默认为 20 秒
-	keepAliveSec := getEnvAsInt("KEEP_ALIVE_SEC", 20)
-	keepAliveInterval := time.Duration(keepAliveSec) * time.Second
+	// 连接池大小，默认为 1（单连接）
+	intVar(&config.PoolSize, "pool-size", "POOL_SIZE", 1, "连接池大小")
 
-	// 获取抖动百分比，默认为10%（0-100）
-	jitterPercent := getEnvAsInt("JITTER_PERCENT", 10)
-	// 限制在 0-100 范围内
-	if jitterPercent < 0 {
-		jitterPercent = 0
-	} else if jitterPercent > 100 {
-		jitterPercent = 100
+	// 重连退避配置，默认基础延迟 1 秒、最大延迟 30 秒、每轮最多 5 次（0 表示无限重试）
+	durationVar(&config.ReconnectBaseDelay, "reconnect-base-delay", "RECONNECT_BASE_DELAY_SEC", time.Second, 1, "重连退避的基础延迟")
+	durationVar(&config.ReconnectMaxDelay, "reconnect-max-delay", "RECONNECT_MAX_DELAY_SEC", time.Second, 30, "重连退避的最大延迟")
+	intVar(&config.MaxReconnectAttempts, "max-reconnect-attempts", "MAX_RECONNECT_ATTEMPTS", 5, "每轮重连的最大尝试次数，0 表示无限重试")
+
+	// 请求重试退避配置，默认基础延迟 1 秒、最大延迟 10 秒、倍数 2
+	durationVar(&config.RetryBaseDelay, "retry-base-delay", "RETRY_BASE_DELAY_MS", time.Millisecond, 1000, "请求重试退避的基础延迟")
+	durationVar(&config.RetryMaxDelay, "retry-max-delay", "RETRY_MAX_DELAY_MS", time.Millisecond, 10000, "请求重试退避的最大延迟")
+	floatVar(&config.RetryMultiplier, "retry-multiplier", "RETRY_MULTIPLIER", 2, "请求重试退避的增长倍数")
+
+	// 超时配置，默认单次尝试 5 秒、整体 30 秒、建立连接 10 秒
+	durationVar(&config.PerAttemptTimeout, "per-attempt-timeout", "PER_ATTEMPT_TIMEOUT_MS", time.Millisecond, 5000, "单次尝试的超时")
+	durationVar(&config.OverallTimeout, "overall-timeout", "OVERALL_TIMEOUT_MS", time.Millisecond, 30000, "一次逻辑请求（含全部重试）的整体超时")
+	durationVar(&config.DialTimeout, "dial-timeout", "DIAL_TIMEOUT_MS", time.Millisecond, 10000, "建立连接时等待连接就绪的超时")
+
+	// 慢请求阈值，默认 1 秒；慢请求日志包含服务端处理耗时和网络开销
+	durationVar(&config.SlowRequestThreshold, "slow-request-threshold", "SLOW_REQUEST_THRESHOLD_MS", time.Millisecond, 1000, "单次尝试耗时达到该值时记录慢请求警告，0 表示不记录")
+
+	// 熔断器按错误率触发，默认不启用
+	floatVar(&config.CircuitBreakerErrorRate, "circuit-breaker-error-rate", "CIRCUIT_BREAKER_ERROR_RATE", 0, "最近 1 分钟错误率（0-1）达到该值时开启熔断，0 表示只按连续失败次数触发")
+	intVar(&config.CircuitBreakerMinRequests, "circuit-breaker-min-requests", "CIRCUIT_BREAKER_MIN_REQUESTS", 20, "按错误率触发熔断所需的最少请求数")
+
+	// 对冲请求配置，默认不启用
+	intVar(&config.HedgingMaxAttempts, "hedging-max-attempts", "HEDGING_MAX_ATTEMPTS", 0, "每次尝试最多同时发送的相同请求数（含原始请求），小于等于 1 表示不启用对冲")
+	durationVar(&config.HedgingDelay, "hedging-delay", "HEDGING_DELAY_MS", time.Millisecond, 0, "原始请求无响应多久后追加对冲请求")
+
+	// 重试预算配置，默认成功请求的 10%，每秒最少保留 10 个令牌，容量 100
+	floatVar(&config.RetryBudgetRatio, "retry-budget-ratio", "RETRY_BUDGET_RATIO", 0.1, "每次成功请求存入的重试令牌数")
+	intVar(&config.RetryBudgetMinReserve, "retry-budget-min-reserve", "RETRY_BUDGET_MIN_RESERVE", 10, "重试预算每秒至少保留的令牌数")
+	intVar(&config.RetryBudgetMaxTokens, "retry-budget-max-tokens", "RETRY_BUDGET_MAX_TOKENS", 100, "重试预算令牌桶容量")
+
+	// 是否等待连接就绪，默认为 false（立即失败）
+	boolVar(&config.WaitForReady, "wait-for-ready", "WAIT_FOR_READY", false, "RPC 是否阻塞等待连接就绪")
+
+	// 每秒请求数上限，默认为 0（不限流）
+	floatVar(&config.MaxRequestsPerSecond, "max-requests-per-second", "MAX_REQUESTS_PER_SECOND", 0, "每秒请求数上限，0 表示不限流")
+
+	// 并发请求数上限，默认为 0（不限制）
+	intVar(&config.MaxConcurrentRequests, "max-concurrent-requests", "MAX_CONCURRENT_REQUESTS", 0, "同时进行的请求总权重上限，0 表示不限制")
+	intVar(&config.StreamRequestWeight, "stream-request-weight", "STREAM_REQUEST_WEIGHT", 4, "流调用占用的并发权重")
+
+	// 后台请求工作池，默认为 0（在主循环中同步执行）
+	intVar(&config.DispatchWorkers, "dispatch-workers", "DISPATCH_WORKERS", 0, "执行后台请求的 worker 数，0 表示在主循环中同步执行")
+	intVar(&config.DispatchQueueSize, "dispatch-queue-size", "DISPATCH_QUEUE_SIZE", 0, "后台请求排队上限，默认等于 worker 数")
+	stringVar(&config.DispatchOverflowPolicy, "dispatch-overflow-policy", "DISPATCH_OVERFLOW_POLICY", "drop-newest", "后台请求队列已满时的策略：drop-newest、drop-oldest 或 block")
+	stringVar(&config.DispatchShutdownPolicy, "dispatch-shutdown-policy", "DISPATCH_SHUTDOWN_POLICY", "cancel", "关闭时排队中后台请求的处理：cancel 直接丢弃，drain 在排空时间内继续执行")
+
+	// 是否禁用后台定时请求，默认为 false
+	boolVar(&config.DisableAutoRequests, "disable-auto-requests", "DISABLE_AUTO_REQUESTS", false, "是否禁用后台定时请求")
+
+	// 是否由客户端自行处理 SIGINT/SIGTERM，独立运行时默认为 true
+	boolVar(&config.HandleSignals, "handle-signals", "HANDLE_SIGNALS", true, "是否由客户端处理 SIGINT/SIGTERM")
+
+	// SIGHUP 重新加载运行时配置前读取的环境变量文件，默认为空（只重新读取进程环境）
+	var reloadEnvFile string
+	stringVar(&reloadEnvFile, "reload-env-file", "RELOAD_ENV_FILE", "", "收到 SIGHUP 时先加载的 KEY=VALUE 环境变量文件")
+
+	// 消息大小限制，默认为 0（使用 gRPC 默认值）
+	intVar(&config.MaxRecvMsgSize, "max-recv-msg-size", "MAX_RECV_MSG_SIZE", 0, "单条接收消息的最大字节数，0 表示使用 gRPC 默认值")
+	intVar(&config.MaxSendMsgSize, "max-send-msg-size", "MAX_SEND_MSG_SIZE", 0, "单条发送消息的最大字节数，0 表示使用 gRPC 默认值")
+
+	// 关闭时等待进行中请求完成的超时，默认为 5 秒
+	durationVar(&config.DrainTimeout, "drain-timeout", "DRAIN_TIMEOUT_SEC", time.Second, 5, "关闭时等待进行中请求完成的超时")
+
+	// 调试 HTTP 服务监听地址，默认为空（不启动）
+	stringVar(&config.DebugAddr, "debug-addr", "DEBUG_ADDR", "", "调试 HTTP 服务监听地址，提供 /debug/status、/debug/metrics、POST /debug/metrics/reset 和 /debug/pprof/")
+
+	// channelz 服务监听地址，默认为空（不启动）
+	stringVar(&config.ChannelzAddr, "channelz-addr", "CHANNELZ_ADDR", "", "channelz gRPC 服务监听地址，供 grpcdebug 查看子通道状态")
+
+	// 定期记录指标快照的间隔，默认为 0（不记录）
+	durationVar(&config.MetricsLogInterval, "metrics-log-interval", "METRICS_LOG_INTERVAL_SEC", time.Second, 0, "定期记录指标快照的间隔，0 表示不记录")
+
+	// 是否记录请求/响应载荷，默认为 false；载荷超过截断长度时截断
+	boolVar(&config.LogPayloads, "log-payloads", "LOG_PAYLOADS", false, "是否以 debug 级别记录请求和响应载荷")
+	intVar(&config.MaxLoggedPayloadSize, "max-logged-payload-size", "MAX_LOGGED_PAYLOAD_SIZE", 256, "记录载荷时单个字段的最大字节数")
+
+	// 故障注入配置，默认不注入；用于测试重试和熔断器
+	floatVar(&config.ChaosErrorRate, "chaos-error-rate", "CHAOS_ERROR_RATE", 0, "故障注入：注入错误的概率（0-1）")
+	durationVar(&config.ChaosLatency, "chaos-latency", "CHAOS_LATENCY_MS", time.Millisecond, 0, "故障注入：每次 RPC 前增加的固定延迟")
+	durationVar(&config.ChaosLatencyJitter, "chaos-latency-jitter", "CHAOS_LATENCY_JITTER_MS", time.Millisecond, 0, "故障注入：额外随机延迟的上限")
+	var chaosCodes string
+	stringVar(&chaosCodes, "chaos-codes", "CHAOS_CODES", "", "故障注入：注入的 gRPC 状态码，逗号分隔，例如 UNAVAILABLE,INTERNAL")
+
+	// 请求签名配置，默认不签名；密钥建议通过环境变量传入，避免出现在进程参数中
+	stringVar(&config.HMACSecret, "hmac-secret", "HMAC_SECRET", "", "请求签名共享密钥，为空时不签名")
+	stringVar(&config.HMACSignatureHeader, "hmac-signature-header", "HMAC_SIGNATURE_HEADER", "", "携带请求签名的 metadata 键，默认 x-srpc-signature")
+	stringVar(&config.HMACTimestampHeader, "hmac-timestamp-header", "HMAC_TIMESTAMP_HEADER", "", "携带签名时间戳的 metadata 键，默认 x-srpc-timestamp")
+
+	// TLS 配置，默认为空（明文连接）；证书文件在每次重连时重新读取，轮换后的证书无需重启即可生效
+	var tlsFiles client.FileCredentials
+	var useTLS bool
+	stringVar(&tlsFiles.CAFile, "tls-ca-file", "TLS_CA_FILE", "", "校验服务端证书的 CA 证书文件（PEM），配置后启用 TLS")
+	stringVar(&tlsFiles.CertFile, "tls-cert-file", "TLS_CERT_FILE", "", "mTLS 客户端证书文件（PEM），配置后启用 TLS")
+	stringVar(&tlsFiles.KeyFile, "tls-key-file", "TLS_KEY_FILE", "", "mTLS 客户端私钥文件（PEM）")
+	stringVar(&tlsFiles.ServerName, "tls-server-name", "TLS_SERVER_NAME", "", "校验服务端证书时使用的主机名，默认取自服务器地址")
+	boolVar(&useTLS, "tls", "TLS_ENABLED", false, "使用系统根证书启用 TLS")
+
+	// 附加到每个 RPC 的静态 metadata，格式为 key=value,key2=value2，默认为空
+	var rawMetadata string
+	stringVar(&rawMetadata, "metadata", "GRPC_METADATA", "", "附加到每个 RPC 的 metadata，格式为 key=value,key2=value2")
+
+	flag.Usage = usage
+	flag.Parse()
+
+	// 未通过参数或环境变量指定 metadata 时保留配置文件中的值
+	if rawMetadata != "" || config.Metadata == nil {
+		config.Metadata = parseMetadata(rawMetadata)
+	}
+	config.OnReload = reloadRuntimeConfig(reloadEnvFile)
+	if useTLS || tlsFiles != (client.FileCredentials{}) {
+		config.CredentialsProvider = tlsFiles
 	}
 
+	if chaosCodes != "" {
+		codes, err := chaos.ParseCodes(chaosCodes)
+		if err != nil {
+			slog.Warn("CHAOS_CODES 无效，使用默认状态码", "error", err)
+		}
+		config.ChaosCodes = codes
+	}
 
-	// 获取是否启用压缩，默认为 false
-	enableCompression := getEnvAsBool("ENABLE_COMPRESSION", false)
+	// 抖动百分比限制在 0-100 范围内
+	if config.JitterPercent < 0 {
+		config.JitterPercent = 0
+	} else if config.JitterPercent > 100 {
+		config.JitterPercent = 100
+	}
 
-	// 获取压缩类型，默认为 snappy
-	compressionType := getEnv("COMPRESSION_TYPE", "snappy")
+	if err := compress.SetDeflateLevel(deflateLevel); err != nil {
+		slog.Warn("DEFLATE_LEVEL 无效，使用默认级别", "error", err)
+	}
 
-	// 获取是否生成请求ID，默认为 true
-	generateRequestID := getEnvAsBool("GENERATE_REQUEST_ID", true)
+	return config, nil
+}
 
-	return client.Config{
-		ServerAddr:            serverAddr,
-		RequestInterval:       requestInterval,
-		MaxRetries:            maxRetries,
-		KeepAliveInterval:     keepAliveInterval,
-		JitterPercent:         jitterPercent,
-		EnableCompression:     enableCompression,
-		CompressionType:       compressionType,
-		GenerateRequestID:     generateRequestID,
+// parseMetadata 解析 key=value,key2=value2 格式的 metadata，忽略格式错误的项
+func parseMetadata(raw string) map[string]string {
+	if raw == "" {
+		return nil
+	}
+	md := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || key == "" {
+			slog.Warn("忽略格式错误的 metadata 项", "item", pair)
+			continue
+		}
+		md[key] = value
 	}
+	return md
 }
 
 // getEnv 获取环境变量，如果不存在则返回默认值
@@ -98,6 +341,17 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvAsFloat 获取浮点数环境变量，如果不存在则返回默认值
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+		slog.Warn("环境变量不是有效的浮点数，使用默认值", "key", key, "value", value, "default", defaultValue)
+	}
+	return defaultValue
+}
+
 // getEnvAsBool 获取布尔值环境变量，如果不存在则返回默认值
 // 支持的值：true, false, 1, 0, yes, no
 func getEnvAsBool(key string, defaultValue bool) bool {
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"srpc/client"
+)
+
+// reloadRuntimeConfig 返回收到 SIGHUP 时使用的 Config.OnReload
+// 运行中的进程无法从外部修改环境变量，因此先将 envFile（每行 KEY=VALUE，# 开头为注释）写入进程环境，
+// 再重新读取可在运行时调整的环境变量；命令行显式传入的参数优先级更高，重新加载时保持不变，未设置的环境变量沿用当前值
+func reloadRuntimeConfig(envFile string) func(client.RuntimeConfig) (client.RuntimeConfig, error) {
+	return func(current client.RuntimeConfig) (client.RuntimeConfig, error) {
+		if envFile != "" {
+			if err := loadEnvFile(envFile); err != nil {
+				return current, err
+			}
+		}
+
+		explicit := make(map[string]bool)
+		flag.Visit(func(f *flag.Flag) {
+			explicit[f.Name] = true
+		})
+
+		rc := current
+		if !explicit["request-interval"] {
+			rc.RequestInterval = time.Duration(getEnvAsInt("REQUEST_INTERVAL_SEC", int(current.RequestInterval/time.Second))) * time.Second
+		}
+		if !explicit["jitter-percent"] {
+			// 与启动时一致，抖动百分比限制在 0-100 范围内
+			rc.JitterPercent = min(max(getEnvAsInt("JITTER_PERCENT", current.JitterPercent), 0), 100)
+		}
+		if !explicit["max-retries"] {
+			rc.MaxRetries = getEnvAsInt("MAX_RETRIES", current.MaxRetries)
+		}
+		if !explicit["max-concurrent-requests"] {
+			rc.MaxConcurrentRequests = getEnvAsInt("MAX_CONCURRENT_REQUESTS", current.MaxConcurrentRequests)
+		}
+		return rc, nil
+	}
+}
+
+// loadEnvFile 读取 KEY=VALUE 格式的文件并设置为进程环境变量，值两侧的引号会被去掉
+func loadEnvFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("读取环境变量文件失败: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return fmt.Errorf("环境变量文件 %s 第 %d 行格式错误: %q", path, lineNo, line)
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		if err := os.Setenv(key, value); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,212 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"srpc/pkg/chaos"
+
+	"google.golang.org/grpc/codes"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadConfigFromFile 从 YAML（.yaml、.yml）或 JSON（.json）文件加载配置，格式按扩展名判断
+// 键为 Config 的字段名，大小写不敏感，也可使用下划线或连字符分隔（request_interval、request-interval）；
+// 时长使用 "30s"、"500ms" 等格式，Metadata 为字符串映射，ChaosCodes 为状态码名称列表或逗号分隔的字符串
+// 回调、时钟和 DialOptions 等无法序列化的字段不能通过文件设置；出现未知的键时返回错误，避免拼写错误被忽略
+func LoadConfigFromFile(path string) (Config, error) {
+	var config Config
+	if _, err := ApplyConfigFile(path, &config); err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
+
+// ApplyConfigFile 将配置文件中出现的字段写入 config，未出现的字段保持不变，返回按字母排序的已设置字段名
+// 调用方可以据此决定优先级，例如独立运行的客户端让命令行参数和环境变量覆盖文件中的值
+func ApplyConfigFile(path string, config *Config) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	raw := make(map[string]interface{})
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		err = dec.Decode(&raw)
+	default:
+		return nil, fmt.Errorf("不支持的配置文件格式: %q，可选 .yaml、.yml、.json", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析配置文件 %s 失败: %w", path, err)
+	}
+
+	fields := configFileFields()
+	v := reflect.ValueOf(config).Elem()
+	var set []string
+	for key, value := range raw {
+		name, ok := fields[normalizeConfigKey(key)]
+		if !ok {
+			return nil, fmt.Errorf("配置文件 %s 包含未知的键: %q", path, key)
+		}
+		if err := setConfigField(v.FieldByName(name), value); err != nil {
+			return nil, fmt.Errorf("配置文件 %s 中 %s 无效: %w", path, key, err)
+		}
+		set = append(set, name)
+	}
+	sort.Strings(set)
+	return set, nil
+}
+
+// configFileFields 返回可以通过配置文件设置的字段，键为规范化后的字段名
+func configFileFields() map[string]string {
+	fields := make(map[string]string)
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		switch field.Type.Kind() {
+		case reflect.Func, reflect.Interface:
+			continue
+		case reflect.Slice:
+			if field.Type.Elem().Kind() == reflect.Interface {
+				continue
+			}
+		}
+		fields[normalizeConfigKey(field.Name)] = field.Name
+	}
+	return fields
+}
+
+// normalizeConfigKey 去掉分隔符并转为小写，使 RequestInterval、request_interval 和 request-interval 等价
+func normalizeConfigKey(key string) string {
+	key = strings.ReplaceAll(key, "_", "")
+	key = strings.ReplaceAll(key, "-", "")
+	return strings.ToLower(key)
+}
+
+// setConfigField 将 YAML/JSON 解码出的值转换为字段类型后写入
+func setConfigField(field reflect.Value, value interface{}) error {
+	switch field.Type() {
+	case reflect.TypeOf(time.Duration(0)):
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("时长需使用 \"30s\"、\"500ms\" 等字符串格式，得到 %v", value)
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	case reflect.TypeOf([]codes.Code(nil)):
+		list, err := stringList(value)
+		if err != nil {
+			return err
+		}
+		parsed, err := chaos.ParseCodes(strings.Join(list, ","))
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(parsed))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("需要字符串，得到 %v", value)
+		}
+		field.SetString(s)
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("需要 true 或 false，得到 %v", value)
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := toInt(value)
+		if err != nil {
+			return fmt.Errorf("需要整数，得到 %v", value)
+		}
+		field.SetInt(n)
+	case reflect.Uint64:
+		n, err := toInt(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("需要非负整数，得到 %v", value)
+		}
+		field.SetUint(uint64(n))
+	case reflect.Float64:
+		n, err := toFloat(value)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(n)
+	case reflect.Map:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("需要键值映射，得到 %v", value)
+		}
+		out := make(map[string]string, len(m))
+		for k, v := range m {
+			out[k] = fmt.Sprint(v)
+		}
+		field.Set(reflect.ValueOf(out))
+	default:
+		return fmt.Errorf("不支持通过配置文件设置 %s 类型的字段", field.Type())
+	}
+	return nil
+}
+
+// toInt 转换 YAML（int）和 JSON（json.Number）解码出的整数
+func toInt(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), nil
+	case json.Number:
+		return v.Int64()
+	default:
+		return 0, fmt.Errorf("需要整数，得到 %v", value)
+	}
+}
+
+// toFloat 转换 YAML（int、float64）和 JSON（json.Number）解码出的数字
+func toFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case json.Number:
+		return v.Float64()
+	default:
+		return 0, fmt.Errorf("需要数字，得到 %v", value)
+	}
+}
+
+// stringList 接受字符串列表或逗号分隔的字符串
+func stringList(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			list = append(list, fmt.Sprint(item))
+		}
+		return list, nil
+	default:
+		return nil, fmt.Errorf("需要列表或逗号分隔的字符串，得到 %v", value)
+	}
+}
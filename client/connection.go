@@ -3,11 +3,18 @@ package client
 import (
 	"context"
 	"fmt"
+	"srpc/pkg/stats"
+	"srpc/pkg/tools"
 	pb "srpc/proto"
 	"time"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
 )
 
 // ConnectionState 连接状态
@@ -20,177 +27,512 @@ const (
 	StateDegraded                            // 降级（部分功能不可用）
 )
 
-// connect 建立 gRPC 连接
-func (c *GRPCClient) connect() error {
-	c.mu.Lock()
-	c.connectionState = StateConnecting
-	c.mu.Unlock()
+// String 方法用于 ConnectionState
+func (s ConnectionState) String() string {
+	switch s {
+	case StateDisconnected:
+		return "Disconnected"
+	case StateConnecting:
+		return "Connecting"
+	case StateConnected:
+		return "Connected"
+	case StateDegraded:
+		return "Degraded"
+	default:
+		return "Unknown"
+	}
+}
 
-	// 构建连接选项
+// dialOptions 构建连接选项，creds 为本次连接使用的传输层凭证
+func (c *GRPCClient) dialOptions(creds credentials.TransportCredentials) []grpc.DialOption {
 	opts := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(creds),
+		// 统计传输层收发的消息数和字节数
+		grpc.WithStatsHandler(stats.NewHandler(c.metrics)),
+	}
+
+	// 为所有 RPC 追加配置的 metadata，例如认证令牌和租户 ID
+	if len(c.config.Metadata) > 0 || c.config.MetadataFunc != nil {
+		opts = append(opts,
+			grpc.WithChainUnaryInterceptor(c.metadataUnaryInterceptor()),
+			grpc.WithChainStreamInterceptor(c.metadataStreamInterceptor()),
+		)
+	}
+
+	// 请求签名拦截器，为每个 RPC 附加时间戳和 HMAC 签名
+	if cfg := c.hmacConfig(); cfg.Enabled() {
+		opts = append(opts,
+			grpc.WithChainUnaryInterceptor(c.hmacUnaryInterceptor(cfg)),
+			grpc.WithChainStreamInterceptor(c.hmacStreamInterceptor(cfg)),
+		)
+	}
+
+	// 故障注入拦截器，注入的错误经过正常的重试、熔断器和指标路径
+	if cfg := c.chaosConfig(); cfg.Enabled() {
+		opts = append(opts,
+			grpc.WithChainUnaryInterceptor(c.chaosUnaryInterceptor(cfg)),
+			grpc.WithChainStreamInterceptor(c.chaosStreamInterceptor(cfg)),
+		)
 	}
 
+	// 传输层 keepalive：空闲时发送 HTTP/2 PING，防止 NAT 或负载均衡器回收空闲连接
+	if c.config.KeepAliveTime > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                c.config.KeepAliveTime,
+			Timeout:             c.config.KeepAliveTimeout,
+			PermitWithoutStream: c.config.KeepAlivePermitWithoutStream,
+		}))
+	}
+
+	// 如果配置了服务配置或负载均衡策略，通过默认服务配置启用（创建客户端时已校验）
+	// 配合 dns:///host:port 目标使用时，gRPC 会解析全部 A 记录并在后端之间均衡
+	if serviceConfig, _ := buildServiceConfig(c.config); serviceConfig != "" {
+		opts = append(opts, grpc.WithDefaultServiceConfig(serviceConfig))
+	}
+
+	// 如果启用链路追踪，为每个 RPC 创建 span 并通过 metadata 传播追踪上下文
+	if c.config.EnableTracing {
+		opts = append(opts, grpc.WithStatsHandler(otelgrpc.NewClientHandler()))
+	}
+
+	var callOpts []grpc.CallOption
+
 	// 如果启用压缩，添加压缩选项
 	if c.config.EnableCompression && c.config.CompressionType != "" {
-		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(c.config.CompressionType)))
+		callOpts = append(callOpts, grpc.UseCompressor(c.config.CompressionType))
 	}
 
+	// 如果启用 WaitForReady，RPC 会阻塞等待连接就绪（直到超时），而不是立即失败
+	if c.config.WaitForReady {
+		callOpts = append(callOpts, grpc.WaitForReady(true))
+	}
+
+	// 消息大小限制，未配置时使用 gRPC 默认值
+	if c.config.MaxRecvMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallRecvMsgSize(c.config.MaxRecvMsgSize))
+	}
+	if c.config.MaxSendMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallSendMsgSize(c.config.MaxSendMsgSize))
+	}
+
+	if len(callOpts) > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(callOpts...))
+	}
+
+	// 调用方追加的选项放在最后，可以覆盖上面的默认设置
+	opts = append(opts, c.config.DialOptions...)
+
+	return opts
+}
+
+// connect 为连接池中的每个连接建立 gRPC 连接
+func (c *GRPCClient) connect() error {
+	for _, pc := range c.pool.conns {
+		if err := c.connectConn(pc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// connectConn 建立单个 gRPC 连接
+func (c *GRPCClient) connectConn(pc *poolConn) error {
+	c.mu.Lock()
+	pc.state = StateConnecting
+	old, current := c.refreshStateLocked()
+	c.mu.Unlock()
+	c.notifyStateChange(old, current)
+
 	c.slogger.Info("正在连接到 gRPC 服务器", map[string]interface{}{
 		"server_addr":      c.config.ServerAddr,
+		"conn_index":       pc.index,
 		"compression":      c.config.EnableCompression,
 		"compression_type": c.config.CompressionType,
+		"lb_policy":        c.config.LoadBalancingPolicy,
+		"service_config":   c.config.ServiceConfigJSON != "",
+		"credentials":      c.config.CredentialsProvider != nil,
 	})
 
-	conn, err := grpc.NewClient(c.config.ServerAddr, opts...)
+	conn, err := c.dial()
 	if err != nil {
 		c.mu.Lock()
-		c.connectionState = StateDisconnected
+		pc.state = StateDisconnected
+		pc.lastError = err
 		c.lastError = err
+		old, current := c.refreshStateLocked()
 		c.mu.Unlock()
+		c.notifyStateChange(old, current)
 		return err
 	}
 
 	c.mu.Lock()
-	c.conn = conn
-	c.greeter = pb.NewGreeterClient(conn)
-	c.connectionState = StateConnected
-	c.lastError = nil
-	c.reconnectCount++
+	pc.conn = conn
+	pc.greeter = c.newGreeter(conn)
+	pc.state = StateConnected
+	pc.lastError = nil
+	pc.reconnectCount++
+	pc.instanceLogged = false
+	reconnectCount, greeter := pc.reconnectCount, pc.greeter
+	old, current = c.refreshStateLocked()
 	c.mu.Unlock()
+	c.notifyStateChange(old, current)
 
 	c.slogger.Info("成功连接到 gRPC 服务器", map[string]interface{}{
 		"server_addr":     c.config.ServerAddr,
-		"reconnect_count": c.reconnectCount,
+		"conn_index":      pc.index,
+		"reconnect_count": reconnectCount,
 	})
+	c.logServerInfo(pc, greeter)
+
+	// 跟踪底层传输状态，使连接状态反映真实的传输情况
+	c.wg.Add(1)
+	go c.watchConnState(pc, conn)
+
 	return nil
 }
 
+// dial 创建 gRPC 连接并等待就绪
+func (c *GRPCClient) dial() (*grpc.ClientConn, error) {
+	if err := checkTarget(c.config.ServerAddr); err != nil {
+		return nil, err
+	}
+	// 每次连接都重新获取凭证，使轮换后的证书在重连时生效
+	creds, err := c.transportCredentials()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := grpc.NewClient(c.config.ServerAddr, c.dialOptions(creds)...)
+	if err != nil {
+		return nil, err
+	}
+	// grpc.NewClient 是惰性的，主动建立连接并等待就绪，确保不可达的服务端能在此处暴露
+	if err := c.waitForReady(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// watchConnState 监听 grpc.ClientConn 的传输状态变化，并映射到连接状态
+// 应用层健康探测仍然保留，作为传输状态之外的第二信号
+func (c *GRPCClient) watchConnState(pc *poolConn, conn *grpc.ClientConn) {
+	defer c.wg.Done()
+
+	state := conn.GetState()
+	for conn.WaitForStateChange(c.ctx, state) {
+		state = conn.GetState()
+		if state == connectivity.Shutdown {
+			return
+		}
+		// 空闲连接不会自动重连，主动触发连接以保持可用
+		if state == connectivity.Idle {
+			conn.Connect()
+		}
+
+		c.mu.Lock()
+		// 连接已被重连替换，旧连接的状态不再有意义
+		if pc.conn != conn {
+			c.mu.Unlock()
+			return
+		}
+		prev := pc.state
+		pc.state = mapConnectivityState(state, prev)
+		changed := pc.state != prev
+		old, current := c.refreshStateLocked()
+		c.mu.Unlock()
+		c.notifyStateChange(old, current)
+
+		if changed {
+			c.slogger.Info("传输状态变化", map[string]interface{}{
+				"conn_index":      pc.index,
+				"transport_state": state.String(),
+				"state":           mapConnectivityState(state, prev).String(),
+			})
+		}
+	}
+}
+
+// mapConnectivityState 将 gRPC 传输状态映射为客户端连接状态
+func mapConnectivityState(state connectivity.State, current ConnectionState) ConnectionState {
+	switch state {
+	case connectivity.Ready:
+		return StateConnected
+	case connectivity.Idle, connectivity.Connecting:
+		return StateConnecting
+	case connectivity.TransientFailure:
+		return StateDisconnected
+	default:
+		return current
+	}
+}
+
+// waitForReady 触发连接建立并等待连接进入 Ready 状态，超过 DialTimeout 返回包装 context.DeadlineExceeded 的错误
+func (c *GRPCClient) waitForReady(conn *grpc.ClientConn) error {
+	ctx, cancel := context.WithTimeout(c.ctx, c.config.DialTimeout)
+	defer cancel()
+
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.Shutdown:
+			return fmt.Errorf("连接已关闭")
+		}
+		// TransientFailure 时 gRPC 会按自身退避继续重试，这里只需等待状态变化或超时
+		if !conn.WaitForStateChange(ctx, state) {
+			if c.ctx.Err() != nil {
+				return fmt.Errorf("客户端正在关闭，放弃等待连接就绪")
+			}
+			return fmt.Errorf("等待连接就绪超时（%v），服务端 %s 不可达或未响应，最后状态: %s: %w",
+				c.config.DialTimeout, c.config.ServerAddr, state, context.DeadlineExceeded)
+		}
+	}
+}
+
 // startHealthChecker 启动健康检查
 func (c *GRPCClient) startHealthChecker() {
 	c.wg.Add(1)
 	go func() {
 		defer c.wg.Done()
 
-		healthTicker := time.NewTicker(c.config.KeepAliveInterval)
-		defer healthTicker.Stop()
+		// 连续失败时按指数退避拉长检查间隔（最长 HealthCheckMaxInterval），减少故障期间的探测压力；成功后立即恢复
+		interval := c.config.KeepAliveInterval
+		// 首次失败后间隔翻倍，少量抖动避免多个客户端在故障恢复时同时探测
+		backoff := tools.NewBackoff(2*interval, max(c.config.HealthCheckMaxInterval, interval), 2)
+		backoff.Jitter = 0.2
+		wait := interval
 
 		for {
 			select {
 			case <-c.ctx.Done():
 				c.slogger.Info("健康检查收到关闭信号，正在退出")
 				return
-			case <-healthTicker.C:
-				c.checkConnectionHealth()
+			case <-c.clock.After(wait):
 			}
+
+			if c.checkConnectionHealth() {
+				if backoff.Attempt() > 0 {
+					c.slogger.Info("健康检查恢复，检查间隔恢复正常", map[string]interface{}{"interval": interval.String()})
+				}
+				backoff.Reset()
+				wait = interval
+				continue
+			}
+			wait = max(backoff.Next(), interval)
+			c.slogger.Warn("健康检查失败，拉长检查间隔", map[string]interface{}{
+				"consecutive_failures": backoff.Attempt(),
+				"next_check_in":        wait.String(),
+			})
 		}
 	}()
 }
 
-// checkConnectionHealth 检查连接健康状态
-func (c *GRPCClient) checkConnectionHealth() {
+// appHealthCheckEnabled 判断是否执行应用层 Ping 探测
+// 未配置传输层 keepalive 时总是启用；配置后默认关闭，除非显式启用 EnableAppHealthCheck
+func (c *GRPCClient) appHealthCheckEnabled() bool {
+	return c.config.KeepAliveTime <= 0 || c.config.EnableAppHealthCheck
+}
+
+// checkConnectionHealth 逐个检查连接池中连接的健康状态，所有连接检查后均处于已连接状态时返回 true
+func (c *GRPCClient) checkConnectionHealth() bool {
+	healthy := true
+	for _, pc := range c.pool.conns {
+		if !c.checkConnHealth(pc) {
+			healthy = false
+		}
+	}
+	return healthy
+}
+
+// checkConnHealth 检查单个连接的健康状态，已连接且探测通过时返回 true
+// 断开或降级的连接在后台重连，重连期间视为不健康
+func (c *GRPCClient) checkConnHealth(pc *poolConn) bool {
 	c.mu.RLock()
-	state := c.connectionState
-	conn := c.conn
+	state := pc.state
+	greeter := pc.greeter
 	c.mu.RUnlock()
 
 	// 如果正在关闭，跳过健康检查
 	if c.IsShutting() {
-		return
+		return true
 	}
 
+	fields := map[string]interface{}{"conn_index": pc.index}
+
 	// 检查连接状态
 	switch state {
 	case StateDisconnected:
-		c.slogger.Info("连接已断开，尝试重新连接")
-		c.reconnect()
+		c.slogger.Info("连接已断开，尝试重新连接", fields)
+		c.startReconnect(pc, StateDisconnected)
 	case StateConnected:
-		// 执行健康检查请求
-		if conn != nil {
+		// 执行应用层健康检查请求；仅启用传输层 keepalive 时由 gRPC 负责探测连接
+		if greeter != nil && c.appHealthCheckEnabled() {
 			ctx, cancel := context.WithTimeout(c.ctx, 3*time.Second)
 			defer cancel()
 
-			// 发送简单的 SayHello 请求作为健康检查
-			req := &pb.HelloRequest{Name: "health-check"}
-			_, err := c.greeter.SayHello(ctx, req)
+			// 发送 Ping 请求作为健康检查
+			err := c.probe(ctx, greeter)
 			if err != nil {
-				c.slogger.Error("健康检查失败，连接可能已断开", map[string]interface{}{"error": err})
+				c.slogger.Error("健康检查失败，连接可能已断开", map[string]interface{}{"conn_index": pc.index, "error": err})
 				c.mu.Lock()
-				c.connectionState = StateDisconnected
+				pc.state = StateDisconnected
+				pc.lastError = err
 				c.lastError = err
+				old, current := c.refreshStateLocked()
 				c.mu.Unlock()
-				c.reconnect()
+				c.notifyStateChange(old, current)
+				c.startReconnect(pc, StateDisconnected)
 			} else {
-				c.slogger.Info("健康检查通过")
+				c.slogger.Info("健康检查通过", fields)
 			}
 		}
 	case StateConnecting:
 		// 正在连接中，等待完成
-		c.slogger.Info("连接中，跳过健康检查")
+		c.slogger.Info("连接中，跳过健康检查", fields)
 	case StateDegraded:
 		// 降级状态，尝试恢复
-		c.slogger.Info("连接降级，尝试恢复")
-		c.reconnect()
+		c.slogger.Info("连接降级，尝试恢复", fields)
+		c.startReconnect(pc, StateDegraded)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return pc.state == StateConnected
+}
+
+// probe 发送一次健康探测，优先使用 Ping 并记录 RTT 和时钟偏差
+// 服务端未实现 Ping（返回 Unimplemented）时，回退到 SayHello
+func (c *GRPCClient) probe(ctx context.Context, greeter greeterClient) error {
+	if !c.pingUnsupported.Load() {
+		sent := time.Now()
+		reply, err := greeter.Ping(ctx, &pb.PingRequest{
+			ClientSendTimeUnixNano: sent.UnixNano(),
+			Sequence:               c.pingSequence.Add(1),
+		})
+		if err == nil {
+			rtt := time.Since(sent)
+			// 假设往返路径对称，服务端收到请求时客户端时钟约为 sent + rtt/2
+			skew := time.Unix(0, reply.GetServerReceiveTimeUnixNano()).Sub(sent.Add(rtt / 2))
+			c.metrics.RecordPing(rtt, skew)
+			return nil
+		}
+		if status.Code(err) != codes.Unimplemented {
+			return err
+		}
+		c.pingUnsupported.Store(true)
+		c.slogger.Warn("服务端未实现 Ping，健康检查回退到 SayHello")
 	}
+
+	_, err := greeter.SayHello(ctx, &pb.HelloRequest{Name: "health-check"})
+	return err
 }
 
-// reconnect 尝试重新连接
-func (c *GRPCClient) reconnect() {
+// startReconnect 在独立的 goroutine 中重连单个连接，重连的退避等待不会阻塞健康检查探测其他连接
+// 同一连接同时只运行一个重连，重连进行中时直接返回
+func (c *GRPCClient) startReconnect(pc *poolConn, observed ConnectionState) {
+	c.mu.Lock()
+	if pc.reconnecting {
+		c.mu.Unlock()
+		return
+	}
+	pc.reconnecting = true
+	c.mu.Unlock()
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer func() {
+			c.mu.Lock()
+			pc.reconnecting = false
+			c.mu.Unlock()
+		}()
+		c.reconnect(pc, observed)
+	}()
+}
+
+// reconnect 尝试重新建立单个连接，observed 为调用方决定重连时看到的连接状态
+// 加锁后状态已变化说明其他路径已接手恢复（例如传输层自行恢复），直接返回，避免重复拨号泄漏连接
+func (c *GRPCClient) reconnect(pc *poolConn, observed ConnectionState) {
 	// 检查是否正在关闭
 	if c.IsShutting() {
 		return
 	}
 
 	c.mu.Lock()
-	oldConn := c.conn
-	c.connectionState = StateConnecting
+	if pc.state != observed {
+		c.mu.Unlock()
+		return
+	}
+	oldConn := pc.conn
+	budgetExhausted := pc.state == StateDegraded
+	pc.state = StateConnecting
+	old, current := c.refreshStateLocked()
 	c.mu.Unlock()
+	c.notifyStateChange(old, current)
+
+	// 上一轮重连耗尽尝试次数后，本轮重新获得完整的尝试预算
+	if budgetExhausted {
+		c.metrics.RecordReconnectBudgetReset()
+		c.slogger.Warn("重连尝试预算已重置，开始新一轮重连", map[string]interface{}{"conn_index": pc.index})
+	}
 
 	// 关闭旧连接
 	if oldConn != nil {
 		oldConn.Close()
 	}
 
-	// 尝试重新连接
+	// 尝试重新连接，maxAttempts 为 0 时无限重试
 	var retryCount int
-	maxReconnectRetries := 5
+	maxAttempts := c.config.MaxReconnectAttempts
+	backoff := tools.NewBackoff(c.config.ReconnectBaseDelay, c.config.ReconnectMaxDelay, 2)
+	backoff.Clock = c.clock
 
-	for retryCount < maxReconnectRetries {
+	for maxAttempts <= 0 || retryCount < maxAttempts {
 		if c.IsShutting() {
 			return
 		}
 
-		c.slogger.Info("重新连接尝试", map[string]interface{}{"current_attempt": retryCount + 1, "max_attempts": maxReconnectRetries})
+		c.slogger.Info("重新连接尝试", map[string]interface{}{"conn_index": pc.index, "current_attempt": retryCount + 1, "max_attempts": maxAttempts})
 
-		err := c.connect()
+		err := c.connectConn(pc)
 		if err == nil {
-			c.slogger.Info("重新连接成功")
-			c.metrics.RecordReconnect()
+			c.slogger.Info("重新连接成功", map[string]interface{}{"conn_index": pc.index})
+			c.metrics.RecordReconnect(pc.index)
 			return
 		}
 
-		c.slogger.Error("重新连接失败", map[string]interface{}{"error": err})
+		c.slogger.Error("重新连接失败", map[string]interface{}{"conn_index": pc.index, "error": err})
 
 		// 指数退避等待
-		backoff := min(time.Duration(retryCount*retryCount+1)*time.Second, 30*time.Second)
+		wait := backoff.Next()
 
-		c.slogger.Info("等待后重试", map[string]interface{}{"backoff": backoff})
-		time.Sleep(backoff)
+		c.slogger.Info("等待后重试", map[string]interface{}{"conn_index": pc.index, "backoff": wait})
+		select {
+		case <-c.clock.After(wait):
+		case <-c.ctx.Done():
+			// 客户端关闭，MaxReconnectAttempts 为 0 时也能及时退出
+			return
+		}
 		retryCount++
 	}
 
-	// 重连失败
+	// 本轮重连失败，进入降级状态，由下一次健康检查继续尝试恢复
 	c.mu.Lock()
-	c.connectionState = StateDisconnected
-	c.lastError = fmt.Errorf("重连失败，已尝试 %d 次", maxReconnectRetries)
+	pc.state = StateDegraded
+	pc.lastError = fmt.Errorf("重连失败，已尝试 %d 次", maxAttempts)
+	c.lastError = pc.lastError
+	old, current = c.refreshStateLocked()
 	c.mu.Unlock()
+	c.notifyStateChange(old, current)
 
-	c.slogger.Error("重连失败，已达到最大重试次数", map[string]interface{}{"max_retries": maxReconnectRetries})
+	c.slogger.Error("重连失败，已达到最大重试次数，进入降级状态", map[string]interface{}{"conn_index": pc.index, "max_attempts": maxAttempts})
 }
 
-// getConnectionState 获取连接状态
-// func (c *GRPCClient) getConnectionState() ConnectionState {
-// 	c.mu.RLock()
-// 	defer c.mu.RUnlock()
-// 	return c.connectionState
-// }
+// ConnectionState 获取客户端整体连接状态
+func (c *GRPCClient) ConnectionState() ConnectionState {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.connectionState
+}
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"srpc/client/mocks"
+	pb "srpc/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// closedPortAddr 返回一个刚刚释放的本地端口地址，拨号时连接会被拒绝
+func closedPortAddr(t *testing.T) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	addr := lis.Addr().String()
+	lis.Close()
+	return addr
+}
+
+func TestNewGRPCClientFailsOnClosedPort(t *testing.T) {
+	cfg := Config{
+		ServerAddr:        closedPortAddr(t),
+		DialTimeout:       300 * time.Millisecond,
+		KeepAliveInterval: time.Hour,
+		RequestInterval:   time.Hour,
+	}
+
+	start := time.Now()
+	c, err := NewGRPCClient(cfg)
+	elapsed := time.Since(start)
+	if err == nil {
+		c.Shutdown()
+		t.Fatal("连接已关闭的端口应返回错误")
+	}
+
+	// gRPC 惰性连接不会暴露拨号失败，NewGRPCClient 应主动等待就绪并在 DialTimeout 后报错
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("错误 = %v，期望包装 context.DeadlineExceeded", err)
+	}
+	if !strings.Contains(err.Error(), cfg.ServerAddr) || !strings.Contains(err.Error(), "TRANSIENT_FAILURE") {
+		t.Errorf("错误信息 %q 应包含目标地址和最后的连接状态", err)
+	}
+	if elapsed < cfg.DialTimeout || elapsed > cfg.DialTimeout+time.Second {
+		t.Errorf("耗时 %v，期望接近 DialTimeout %v", elapsed, cfg.DialTimeout)
+	}
+}
+
+func TestConnectBuildsOneGreeterPerConnection(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	cfg := mockConfig()
+	cfg.PoolSize = 2
+	cfg.DialOptions = []grpc.DialOption{grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	})}
+	c, err := newClient(cfg)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	t.Cleanup(c.Shutdown)
+
+	// 服务端信息通过连接上已创建的存根获取，每个连接只创建一次存根
+	var built atomic.Int64
+	greeter := &mocks.Greeter{
+		PingFunc: pingOK,
+		GetServerInfoFunc: func(context.Context, *emptypb.Empty) (*pb.ServerInfo, error) {
+			return &pb.ServerInfo{}, nil
+		},
+	}
+	c.newGreeter = func(grpc.ClientConnInterface) greeterClient {
+		built.Add(1)
+		return greeter
+	}
+	if err := c.connect(); err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	if got := built.Load(); got != int64(cfg.PoolSize) {
+		t.Errorf("创建存根 %d 次，期望每个连接 1 次，共 %d 次", got, cfg.PoolSize)
+	}
+	if got := greeter.Calls("GetServerInfo"); got != cfg.PoolSize {
+		t.Errorf("GetServerInfo 调用 %d 次，期望 %d 次", got, cfg.PoolSize)
+	}
+}
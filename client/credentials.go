@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// CredentialsProvider 提供建立连接时使用的传输层凭证
+// 客户端每次建立或重新建立连接（包括健康检查触发的重连）时调用一次，实现可以借此返回轮换后的证书，
+// 例如 SPIFFE SVID 或 Vault 签发的短期证书；gRPC 在同一连接内部自动重连子通道时沿用已返回的凭证
+type CredentialsProvider interface {
+	TransportCredentials(ctx context.Context) (credentials.TransportCredentials, error)
+}
+
+// CredentialsProviderFunc 将函数适配为 CredentialsProvider
+type CredentialsProviderFunc func(ctx context.Context) (credentials.TransportCredentials, error)
+
+// TransportCredentials 调用 f
+func (f CredentialsProviderFunc) TransportCredentials(ctx context.Context) (credentials.TransportCredentials, error) {
+	return f(ctx)
+}
+
+// FileCredentials 从 PEM 文件加载 TLS 凭证，每次调用都重新读取文件，因此磁盘上替换的证书在下一次重连时生效
+type FileCredentials struct {
+	CAFile     string // 校验服务端证书的 CA 证书，为空时使用系统根证书
+	CertFile   string // 客户端证书，与 KeyFile 同时配置时启用 mTLS
+	KeyFile    string // 客户端私钥
+	ServerName string // 校验服务端证书时使用的主机名，为空时使用目标地址中的主机名
+	MinVersion uint16 // 允许的最低 TLS 版本，为 0 时使用 TLS 1.2
+}
+
+// TransportCredentials 读取证书文件并构造 TLS 凭证
+func (f FileCredentials) TransportCredentials(context.Context) (credentials.TransportCredentials, error) {
+	if (f.CertFile == "") != (f.KeyFile == "") {
+		return nil, errors.New("启用 mTLS 需要同时配置客户端证书文件和私钥文件")
+	}
+
+	cfg := &tls.Config{
+		ServerName: f.ServerName,
+		MinVersion: f.MinVersion,
+	}
+	if cfg.MinVersion == 0 {
+		cfg.MinVersion = tls.VersionTLS12
+	}
+
+	if f.CAFile != "" {
+		pem, err := os.ReadFile(f.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取 CA 证书失败: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA 证书文件 %s 中没有有效的 PEM 证书", f.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if f.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(f.CertFile, f.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("加载客户端证书失败: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return credentials.NewTLS(cfg), nil
+}
+
+// transportCredentials 返回本次连接使用的传输层凭证，未配置 CredentialsProvider 时使用明文连接
+func (c *GRPCClient) transportCredentials() (credentials.TransportCredentials, error) {
+	if c.config.CredentialsProvider == nil {
+		return insecure.NewCredentials(), nil
+	}
+	creds, err := c.config.CredentialsProvider.TransportCredentials(c.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取传输层凭证失败: %w", err)
+	}
+	if creds == nil {
+		return nil, errors.New("获取传输层凭证失败: CredentialsProvider 返回了空凭证")
+	}
+	return creds, nil
+}
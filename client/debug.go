@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+)
+
+// debugShutdownTimeout 关闭调试 HTTP 服务时等待进行中请求的最长时间
+const debugShutdownTimeout = time.Second
+
+// startDebugServer 在 DebugAddr 上启动调试 HTTP 服务，未配置时不启动
+// 监听失败只记录错误，不影响客户端运行
+func (c *GRPCClient) startDebugServer() {
+	if c.config.DebugAddr == "" {
+		return
+	}
+
+	lis, err := net.Listen("tcp", c.config.DebugAddr)
+	if err != nil {
+		c.slogger.Error("调试服务监听失败", map[string]interface{}{"addr": c.config.DebugAddr, "error": err})
+		return
+	}
+
+	srv := &http.Server{Handler: c.debugHandler(), ReadHeaderTimeout: 5 * time.Second}
+	c.mu.Lock()
+	c.debugServer = srv
+	c.mu.Unlock()
+
+	c.slogger.Info("调试服务已启动", map[string]interface{}{"addr": lis.Addr().String()})
+	go func() {
+		if err := srv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			c.slogger.Error("调试服务异常退出", map[string]interface{}{"error": err})
+		}
+	}()
+}
+
+// stopDebugServer 关闭调试 HTTP 服务
+func (c *GRPCClient) stopDebugServer() {
+	c.mu.Lock()
+	srv := c.debugServer
+	c.debugServer = nil
+	c.mu.Unlock()
+	if srv == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), debugShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		srv.Close()
+	}
+	c.slogger.Info("调试服务已关闭")
+}
+
+// debugHandler 返回调试服务的路由：/debug/status、/debug/metrics、POST /debug/metrics/reset 和 /debug/pprof/
+func (c *GRPCClient) debugHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/status", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, c.Status())
+	})
+	mux.HandleFunc("/debug/metrics", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, c.GetMetrics().Map())
+	})
+	mux.HandleFunc("POST /debug/metrics/reset", func(w http.ResponseWriter, r *http.Request) {
+		c.ResetMetrics()
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+// writeJSON 以缩进格式输出 JSON 响应
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
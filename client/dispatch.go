@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"srpc/pkg/tools"
+)
+
+// 后台请求关闭策略，对应 Config.DispatchShutdownPolicy
+const (
+	DispatchShutdownCancel = "cancel" // 关闭时丢弃排队中的请求，正在执行的请求参与排空
+	DispatchShutdownDrain  = "drain"  // 关闭时继续执行排队中的请求，直到队列清空或排空时间到期
+)
+
+// newDispatcher 按配置创建后台请求使用的工作池，DispatchWorkers 为 0 时返回 nil，请求在主循环中同步执行
+func (c *GRPCClient) newDispatcher() *tools.WorkerPool {
+	if c.config.DispatchWorkers <= 0 {
+		return nil
+	}
+	// 策略已在创建客户端时校验
+	policy, _ := tools.ParseOverflowPolicy(c.config.DispatchOverflowPolicy)
+	return tools.NewWorkerPool(c.config.DispatchWorkers, c.config.DispatchQueueSize,
+		tools.WithOverflowPolicy(policy),
+		tools.WithDropHandler(c.metrics.RecordDispatchDropped),
+	)
+}
+
+// drainDispatcher 在关闭的第一阶段处理工作池中排队的请求，ctx 为关闭的排空时限
+// drain 策略下通过 WorkerPool.Shutdown 等待排队中的请求执行完，到期时丢弃剩余请求；cancel 策略下由主循环直接丢弃
+func (c *GRPCClient) drainDispatcher(ctx context.Context) {
+	c.mu.RLock()
+	pool := c.dispatcher
+	c.mu.RUnlock()
+	if pool == nil || c.config.DispatchShutdownPolicy != DispatchShutdownDrain {
+		return
+	}
+
+	queued := pool.Stats().QueueDepth
+	if queued > 0 {
+		c.slogger.Info("等待排队中的后台请求完成", map[string]interface{}{"queue_depth": queued})
+	}
+	if err := pool.Shutdown(ctx); err != nil {
+		c.slogger.Warn("排空超时，丢弃排队中的后台请求", map[string]interface{}{"error": err.Error()})
+	}
+}
+
+// dispatch 执行一次后台请求：未启用工作池时同步执行，否则提交到工作池，队列已满时按溢出策略处理
+func (c *GRPCClient) dispatch(pool *tools.WorkerPool) {
+	if pool == nil {
+		c.makeRequest()
+		return
+	}
+
+	// OverflowBlock 策略下阻塞主循环，停止信号可以中断等待
+	err := pool.Submit(c.stopCtx, c.makeRequest)
+	if errors.Is(err, tools.ErrQueueFull) {
+		stats := pool.Stats()
+		c.slogger.Warn("后台请求队列已满，丢弃本次请求", map[string]interface{}{
+			"queue_depth": stats.QueueDepth,
+			"running":     stats.Running,
+		})
+	}
+}
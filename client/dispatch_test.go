@@ -0,0 +1,85 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"srpc/client/mocks"
+)
+
+// dispatchConfig 返回单 worker、队列已满时阻塞主循环的后台请求配置，关闭时队列必然是满的
+func dispatchConfig(policy string) Config {
+	cfg := mockConfig()
+	cfg.RequestInterval = time.Millisecond
+	cfg.DispatchWorkers = 1
+	cfg.DispatchQueueSize = 3
+	cfg.DispatchOverflowPolicy = "block"
+	cfg.DispatchShutdownPolicy = policy
+	return cfg
+}
+
+func TestDispatchShutdownCancelDropsQueued(t *testing.T) {
+	greeter := &mocks.Greeter{SayHelloFunc: slowSayHello(20 * time.Millisecond)}
+	c := newMockClient(t, dispatchConfig(DispatchShutdownCancel), greeter)
+	done := runClient(c)
+	waitFor(t, 5*time.Second, "队列填满", func() bool { return c.GetMetrics().DispatchQueueDepth == 3 })
+
+	c.Shutdown()
+	if err := <-done; err != nil {
+		t.Fatalf("Run 返回错误: %v", err)
+	}
+	if got := c.GetMetrics().DispatchDropped; got == 0 {
+		t.Error("cancel 策略下关闭时应丢弃排队中的请求")
+	}
+}
+
+func TestDispatchShutdownDrainRunsQueued(t *testing.T) {
+	greeter := &mocks.Greeter{SayHelloFunc: slowSayHello(20 * time.Millisecond)}
+	c := newMockClient(t, dispatchConfig(DispatchShutdownDrain), greeter)
+	done := runClient(c)
+	waitFor(t, 5*time.Second, "队列填满", func() bool { return c.GetMetrics().DispatchQueueDepth == 3 })
+	before := greeter.Calls("SayHello")
+
+	c.Shutdown()
+	if err := <-done; err != nil {
+		t.Fatalf("Run 返回错误: %v", err)
+	}
+	snap := c.GetMetrics()
+	if snap.DispatchDropped != 0 {
+		t.Errorf("drain 策略下 DispatchDropped = %d，期望 0", snap.DispatchDropped)
+	}
+	// 关闭时 1 个请求正在执行、3 个在排队，全部应在排空期间完成
+	if got := greeter.Calls("SayHello") - before; got < 3 {
+		t.Errorf("关闭后执行了 %d 个请求，期望至少 3 个", got)
+	}
+	if snap.FailedRequests != 0 {
+		t.Errorf("FailedRequests = %d，排空期间的请求应成功", snap.FailedRequests)
+	}
+}
+
+func TestDispatchShutdownDrainBoundedByTimeout(t *testing.T) {
+	greeter := &mocks.Greeter{SayHelloFunc: slowSayHello(200 * time.Millisecond)}
+	c := newMockClient(t, dispatchConfig(DispatchShutdownDrain), greeter)
+	done := runClient(c)
+	waitFor(t, 5*time.Second, "队列填满", func() bool { return c.GetMetrics().DispatchQueueDepth == 3 })
+
+	start := time.Now()
+	c.ShutdownGracefully(50 * time.Millisecond)
+	if err := <-done; err != nil {
+		t.Fatalf("Run 返回错误: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("关闭耗时 %v，应受排空时间限制", elapsed)
+	}
+	if got := c.GetMetrics().DispatchDropped; got == 0 {
+		t.Error("排空超时后应丢弃仍在排队的请求")
+	}
+}
+
+func TestInvalidDispatchShutdownPolicy(t *testing.T) {
+	cfg := mockConfig()
+	cfg.DispatchShutdownPolicy = "wait"
+	if _, err := newClient(cfg); err == nil {
+		t.Error("不支持的关闭策略应返回错误")
+	}
+}
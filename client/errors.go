@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RPC 错误类别，SendNow 和流式调用返回的错误可通过 errors.Is 判断类别，调用方无需直接依赖 grpc/status
+var (
+	ErrTimeout           = errors.New("请求超时")
+	ErrCanceled          = errors.New("请求已取消")
+	ErrUnavailable       = errors.New("服务不可用")
+	ErrInvalidArgument   = errors.New("请求参数无效")
+	ErrNotFound          = errors.New("资源不存在")
+	ErrAlreadyExists     = errors.New("资源已存在")
+	ErrPermissionDenied  = errors.New("没有访问权限")
+	ErrResourceExhausted = errors.New("资源耗尽")
+	ErrUnimplemented     = errors.New("服务端未实现该方法")
+	ErrInternal          = errors.New("服务端内部错误")
+)
+
+// codeKinds gRPC 状态码到错误类别的映射，未列出的状态码不做分类
+var codeKinds = map[codes.Code]error{
+	codes.DeadlineExceeded:  ErrTimeout,
+	codes.Canceled:          ErrCanceled,
+	codes.Unavailable:       ErrUnavailable,
+	codes.InvalidArgument:   ErrInvalidArgument,
+	codes.OutOfRange:        ErrInvalidArgument,
+	codes.NotFound:          ErrNotFound,
+	codes.AlreadyExists:     ErrAlreadyExists,
+	codes.PermissionDenied:  ErrPermissionDenied,
+	codes.Unauthenticated:   ErrPermissionDenied,
+	codes.ResourceExhausted: ErrResourceExhausted,
+	codes.Unimplemented:     ErrUnimplemented,
+	codes.Internal:          ErrInternal,
+	codes.Unknown:           ErrInternal,
+	codes.DataLoss:          ErrInternal,
+}
+
+// RPCError 带类别的 RPC 错误，errors.Is(err, ErrTimeout) 等判断类别，errors.Unwrap 返回原始错误
+// 原始错误仍可被 status.FromError / status.Code 识别，需要状态详情的调用方不受影响
+type RPCError struct {
+	Kind     error      // 错误类别，为上面的 Err* 之一
+	Code     codes.Code // 原始 gRPC 状态码，本地超时或取消时为对应的状态码
+	Attempts int        // 最终失败前实际发起的尝试次数（含首次请求），未经过重试逻辑时为 0
+	err      error
+}
+
+// Error 返回原始错误信息
+func (e *RPCError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap 返回原始错误
+func (e *RPCError) Unwrap() error {
+	return e.err
+}
+
+// Is 判断错误是否属于 target 类别
+func (e *RPCError) Is(target error) bool {
+	return target == e.Kind
+}
+
+// classifyError 将 RPC 返回的错误包装为 RPCError，无法分类的错误原样返回
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return err
+	}
+
+	var code codes.Code
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	default:
+		st, ok := status.FromError(err)
+		if !ok {
+			return err
+		}
+		code = st.Code()
+	}
+
+	kind, ok := codeKinds[code]
+	if !ok {
+		return err
+	}
+	return &RPCError{Kind: kind, Code: code, err: err}
+}
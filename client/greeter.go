@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	pb "srpc/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// greeterClient 客户端实际使用的 Greeter 方法，生成的 pb.GreeterClient 满足该接口
+// 测试中可以替换为 mocks.Greeter，在没有服务端的情况下驱动重试、熔断器和健康检查逻辑
+type greeterClient interface {
+	SayHello(ctx context.Context, in *pb.HelloRequest, opts ...grpc.CallOption) (*pb.HelloReply, error)
+	GetStream(ctx context.Context, in *pb.StreamReqData, opts ...grpc.CallOption) (grpc.ServerStreamingClient[pb.StreamResData], error)
+	PutStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[pb.StreamReqData, pb.StreamResData], error)
+	AllStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[pb.StreamReqData, pb.StreamResData], error)
+	Ping(ctx context.Context, in *pb.PingRequest, opts ...grpc.CallOption) (*pb.PingReply, error)
+	GetServerInfo(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*pb.ServerInfo, error)
+}
+
+// newGreeterClient 默认的存根构造函数
+func newGreeterClient(conn grpc.ClientConnInterface) greeterClient {
+	return pb.NewGreeterClient(conn)
+}
+
+// newGRPCClientWithGreeter 创建使用指定存根的客户端，不拨号，连接池中的每个连接直接视为已连接，供测试使用
+// 重连时仍会真实拨号，拨号成功后继续使用 greeter 而不是新建的存根
+func newGRPCClientWithGreeter(config Config, greeter greeterClient) (*GRPCClient, error) {
+	client, err := newClient(config)
+	if err != nil {
+		return nil, err
+	}
+	client.newGreeter = func(grpc.ClientConnInterface) greeterClient { return greeter }
+
+	client.mu.Lock()
+	for _, pc := range client.pool.conns {
+		pc.greeter = greeter
+		pc.state = StateConnected
+	}
+	client.refreshStateLocked()
+	client.mu.Unlock()
+
+	client.startHealthChecker()
+	return client, nil
+}
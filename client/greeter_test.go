@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+	"time"
+
+	"srpc/client/mocks"
+	"srpc/pkg/tools"
+	pb "srpc/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMockRetryExhaustion(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "服务不可用")
+	greeter := &mocks.Greeter{SayHelloFunc: mocks.SayHelloSequence(unavailable)}
+	cfg := mockConfig()
+	cfg.MaxRetries = 2
+	c := newMockClient(t, cfg, greeter)
+
+	_, err := c.SendNow(context.Background(), "exhausted")
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != codes.Unavailable || rpcErr.Attempts != 3 {
+		t.Fatalf("错误 = %#v，期望 Unavailable 且 Attempts 为 3", err)
+	}
+	if got := greeter.Calls("SayHello"); got != 3 {
+		t.Errorf("SayHello 调用 %d 次，期望首次加 2 次重试", got)
+	}
+
+	m := c.GetMetrics()
+	if m.TotalRetries != 2 || m.FailedRequests != 3 || m.ErrorsRetriedAway != 0 {
+		t.Errorf("重试 %d 次、失败 %d 次、重试挽回 %d 次，期望 2、3、0", m.TotalRetries, m.FailedRequests, m.ErrorsRetriedAway)
+	}
+	if want := map[string]int64{"Unavailable": 1}; !maps.Equal(m.FinalErrorsByCode, want) {
+		t.Errorf("FinalErrorsByCode = %v，期望 %v", m.FinalErrorsByCode, want)
+	}
+}
+
+func TestMockBreakerTripsOnScriptedFailures(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "服务不可用")
+	// 前 5 次失败使熔断器开启，之后的调用都会成功
+	greeter := &mocks.Greeter{SayHelloFunc: mocks.SayHelloSequence(
+		unavailable, unavailable, unavailable, unavailable, unavailable, nil,
+	)}
+	clock := tools.NewFakeClock(time.Unix(1_750_000_000, 0))
+	cfg := mockConfig()
+	cfg.Clock = clock
+	c := newMockClient(t, cfg, greeter)
+
+	for i := 1; i <= 5; i++ {
+		if _, err := c.SendNow(context.Background(), "trip"); status.Code(err) != codes.Unavailable {
+			t.Fatalf("第 %d 次请求错误 = %v，期望 Unavailable", i, err)
+		}
+		if i < 5 && c.CircuitBreakerState() != CBStateClosed {
+			t.Fatalf("第 %d 次失败后熔断器已开启，期望连续 5 次失败后才开启", i)
+		}
+	}
+	if got := c.CircuitBreakerState(); got != CBStateOpen {
+		t.Fatalf("熔断器状态 = %v，期望开启", got)
+	}
+	if _, err := c.SendNow(context.Background(), "rejected"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("熔断器开启时错误 = %v，期望 ErrCircuitOpen", err)
+	}
+	if got := greeter.Calls("SayHello"); got != 5 {
+		t.Errorf("SayHello 调用 %d 次，熔断器开启时不应调用存根", got)
+	}
+
+	// 开启时长过后试探成功，半开状态下连续 3 次成功后关闭
+	clock.Advance(30 * time.Second)
+	for range 3 {
+		if _, err := c.SendNow(context.Background(), "recover"); err != nil {
+			t.Fatalf("恢复后请求失败: %v", err)
+		}
+	}
+	if got := c.CircuitBreakerState(); got != CBStateClosed {
+		t.Errorf("熔断器状态 = %v，期望关闭", got)
+	}
+}
+
+func TestMockHealthCheckFailureDisconnects(t *testing.T) {
+	pingErr := status.Error(codes.Unavailable, "探测失败")
+	greeter := &mocks.Greeter{PingFunc: func(context.Context, *pb.PingRequest) (*pb.PingReply, error) {
+		return nil, pingErr
+	}}
+	clock := tools.NewFakeClock(time.Unix(1_750_000_000, 0))
+	cfg := mockConfig()
+	cfg.Clock = clock
+	cfg.KeepAliveInterval = time.Second
+	// 重连会真实拨号到不存在的 mock 地址，缩短等待使其尽快失败
+	cfg.DialTimeout = 50 * time.Millisecond
+	c := newMockClient(t, cfg, greeter)
+	if got := c.ConnectionState(); got != StateConnected {
+		t.Fatalf("初始连接状态 = %v，期望已连接", got)
+	}
+
+	waitWaiters(t, clock, 1)
+	clock.Advance(cfg.KeepAliveInterval)
+	waitFor(t, 5*time.Second, "探测失败后连接断开", func() bool {
+		return greeter.Calls("Ping") == 1 && c.ConnectionState() != StateConnected
+	})
+	// 探测失败后在后台重连，重连失败后按退避等待，不会恢复为已连接
+	waitFor(t, 5*time.Second, "重连失败", func() bool { return c.ConnectionState() == StateDisconnected })
+	if s := c.Status(); s.LastError == "" {
+		t.Error("Status 中没有记录最近的错误")
+	}
+}
+
+func TestMockHealthCheckFallsBackToSayHello(t *testing.T) {
+	greeter := &mocks.Greeter{
+		PingFunc: func(context.Context, *pb.PingRequest) (*pb.PingReply, error) {
+			return nil, status.Error(codes.Unimplemented, "旧版本服务端")
+		},
+		SayHelloFunc: mocks.SayHelloSequence(nil),
+	}
+	clock := tools.NewFakeClock(time.Unix(1_750_000_000, 0))
+	cfg := mockConfig()
+	cfg.Clock = clock
+	cfg.KeepAliveInterval = time.Second
+	c := newMockClient(t, cfg, greeter)
+
+	// 服务端未实现 Ping 时回退到 SayHello 探测，连接保持可用，之后不再尝试 Ping
+	for round := 1; round <= 2; round++ {
+		waitWaiters(t, clock, 1)
+		clock.Advance(cfg.KeepAliveInterval)
+		waitFor(t, 5*time.Second, "回退到 SayHello 探测", func() bool { return greeter.Calls("SayHello") == round })
+	}
+	if got := greeter.Calls("Ping"); got != 1 {
+		t.Errorf("Ping 调用 %d 次，确认不支持后应只尝试一次", got)
+	}
+	if got := c.ConnectionState(); got != StateConnected {
+		t.Errorf("连接状态 = %v，期望保持已连接", got)
+	}
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+
+	pb "srpc/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// hedgeResult 一个对冲请求的结果
+type hedgeResult struct {
+	reply *pb.HelloReply
+	md    callMetadata
+	err   error
+	hedge int // 0 为原始请求，之后为追加的对冲请求
+}
+
+// callMetadata 一次 RPC 收到的响应 header 和 trailer
+type callMetadata struct {
+	header  metadata.MD
+	trailer metadata.MD
+}
+
+// hedgingEnabled 是否启用对冲请求
+func (c *GRPCClient) hedgingEnabled() bool {
+	return c.config.HedgingMaxAttempts > 1
+}
+
+// hedgedSayHello 发送 SayHello，启用对冲时若 HedgingDelay 内没有响应则追加发送相同的请求，最多同时存在 HedgingMaxAttempts 个
+// 返回第一个成功的响应并取消其余请求；某个请求以可重试的错误失败时立即追加下一个，不再等待延迟；
+// 遇到致命错误或全部失败时返回该错误，由外层的重试逻辑统一处理，因此一次尝试只产生一个结果（一次指标记录和一次熔断器记录）
+// 同时返回产生该结果的请求的响应 header 和 trailer
+func (c *GRPCClient) hedgedSayHello(ctx context.Context, greeter greeterClient, req *pb.HelloRequest, opts ...grpc.CallOption) (*pb.HelloReply, callMetadata, error) {
+	if !c.hedgingEnabled() {
+		return sayHelloWithMetadata(ctx, greeter, req, opts...)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	maxAttempts := c.config.HedgingMaxAttempts
+	// 缓冲区容纳全部请求的结果，返回后仍在进行的请求被取消，写入结果时不会阻塞
+	results := make(chan hedgeResult, maxAttempts)
+	sent, pending := 0, 0
+	send := func() {
+		hedge := sent
+		sent++
+		pending++
+		if hedge > 0 {
+			c.metrics.RecordHedge()
+		}
+		go func() {
+			reply, md, err := sayHelloWithMetadata(ctx, greeter, req, opts...)
+			results <- hedgeResult{reply: reply, md: md, err: err, hedge: hedge}
+		}()
+	}
+
+	send()
+	timer := c.clock.After(c.config.HedgingDelay)
+	for {
+		select {
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				if r.hedge > 0 {
+					c.metrics.RecordHedgeWin()
+				}
+				return r.reply, r.md, nil
+			}
+			if isFatalError(r.err) || ctx.Err() != nil {
+				return nil, r.md, r.err
+			}
+			if sent < maxAttempts {
+				send()
+				timer = c.clock.After(c.config.HedgingDelay)
+			} else if pending == 0 {
+				return nil, r.md, r.err
+			}
+		case <-timer:
+			timer = nil
+			if sent < maxAttempts {
+				send()
+				if sent < maxAttempts {
+					timer = c.clock.After(c.config.HedgingDelay)
+				}
+			}
+		}
+	}
+}
+
+// sayHelloWithMetadata 发送 SayHello 并读取响应 header 和 trailer，每次调用使用独立的变量，可并发调用
+func sayHelloWithMetadata(ctx context.Context, greeter greeterClient, req *pb.HelloRequest, opts ...grpc.CallOption) (*pb.HelloReply, callMetadata, error) {
+	var md callMetadata
+	// 限制容量使 append 总是复制，避免并发的对冲请求写入同一底层数组
+	opts = append(opts[:len(opts):len(opts)], grpc.Header(&md.header), grpc.Trailer(&md.trailer))
+	reply, err := greeter.SayHello(ctx, req, opts...)
+	return reply, md, err
+}
@@ -0,0 +1,75 @@
+package client
+
+import "time"
+
+// latencyBounds 延迟直方图的桶上界，从 50µs 起按 1.2 倍增长到 60s 以上，分位数估算的相对误差不超过 20%
+var latencyBounds = func() []time.Duration {
+	var bounds []time.Duration
+	for b := float64(50 * time.Microsecond); b < float64(60*time.Second)*1.2; b *= 1.2 {
+		bounds = append(bounds, time.Duration(b))
+	}
+	return bounds
+}()
+
+// latencyHistogram 固定桶的延迟直方图，内存占用与样本数无关，适合长期运行时累计
+// 非并发安全，由调用方加锁
+type latencyHistogram struct {
+	counts []int64 // counts[i] 为落入 (latencyBounds[i-1], latencyBounds[i]] 的样本数，最后一个为溢出桶
+	total  int64
+	max    time.Duration
+}
+
+// observe 记录一个延迟样本
+func (h *latencyHistogram) observe(d time.Duration) {
+	if h.counts == nil {
+		h.counts = make([]int64, len(latencyBounds)+1)
+	}
+	i := 0
+	for i < len(latencyBounds) && d > latencyBounds[i] {
+		i++
+	}
+	h.counts[i]++
+	h.total++
+	h.max = max(h.max, d)
+}
+
+// quantile 返回 q 分位延迟的估计值（样本所在桶的上界，不超过最大值）
+func (h *latencyHistogram) quantile(q float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	rank := int64(float64(h.total)*q + 0.5)
+	rank = max(1, min(rank, h.total))
+	var seen int64
+	for i, n := range h.counts {
+		seen += n
+		if seen >= rank {
+			if i == len(latencyBounds) {
+				return h.max
+			}
+			return min(latencyBounds[i], h.max)
+		}
+	}
+	return h.max
+}
+
+// clone 返回直方图的副本
+func (h *latencyHistogram) clone() latencyHistogram {
+	c := *h
+	if h.counts != nil {
+		c.counts = append([]int64(nil), h.counts...)
+	}
+	return c
+}
+
+// sub 返回相对于较早副本 prev 的增量直方图，最大值沿用当前直方图的值
+func (h *latencyHistogram) sub(prev latencyHistogram) latencyHistogram {
+	d := h.clone()
+	for i := range prev.counts {
+		if i < len(d.counts) {
+			d.counts[i] -= prev.counts[i]
+		}
+	}
+	d.total -= prev.total
+	return d
+}
@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	"srpc/pkg/auth"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// hmacConfig 根据客户端配置构造请求签名配置
+func (c *GRPCClient) hmacConfig() auth.HMACConfig {
+	return auth.HMACConfig{
+		Secret:          []byte(c.config.HMACSecret),
+		SignatureHeader: c.config.HMACSignatureHeader,
+		TimestampHeader: c.config.HMACTimestampHeader,
+	}.WithDefaults()
+}
+
+// hmacUnaryInterceptor 使用共享密钥对方法名、时间戳和请求体签名，并写入出站 metadata
+func (c *GRPCClient) hmacUnaryInterceptor(cfg auth.HMACConfig) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		body, err := auth.MessageBody(req)
+		if err != nil {
+			return err
+		}
+		return invoker(c.signContext(ctx, cfg, method, body), method, req, reply, cc, opts...)
+	}
+}
+
+// hmacStreamInterceptor 在建立流时对方法名和时间戳签名，流上的后续消息不单独签名
+func (c *GRPCClient) hmacStreamInterceptor(cfg auth.HMACConfig) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(c.signContext(ctx, cfg, method, nil), desc, cc, method, opts...)
+	}
+}
+
+// signContext 计算签名并追加时间戳和签名到出站 metadata
+func (c *GRPCClient) signContext(ctx context.Context, cfg auth.HMACConfig, method string, body []byte) context.Context {
+	ts := c.clock.Now().UnixMilli()
+	return metadata.AppendToOutgoingContext(ctx,
+		cfg.TimestampHeader, strconv.FormatInt(ts, 10),
+		cfg.SignatureHeader, cfg.Sign(method, ts, body),
+	)
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"sync"
+)
+
+// inflightTracker 跟踪进行中的请求数量，用于关闭时等待请求排空
+// 与 sync.WaitGroup 不同，允许在等待期间继续 begin，不会因复用而 panic
+type inflightTracker struct {
+	mu    sync.Mutex
+	count int
+	idle  chan struct{} // count 为 0 时关闭
+}
+
+// newInflightTracker 创建新的请求跟踪器
+func newInflightTracker() *inflightTracker {
+	idle := make(chan struct{})
+	close(idle)
+	return &inflightTracker{idle: idle}
+}
+
+// begin 标记一个请求开始
+func (t *inflightTracker) begin() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.count == 0 {
+		t.idle = make(chan struct{})
+	}
+	t.count++
+}
+
+// end 标记一个请求结束
+func (t *inflightTracker) end() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.count--
+	if t.count == 0 {
+		close(t.idle)
+	}
+}
+
+// current 返回当前进行中的请求数量
+func (t *inflightTracker) current() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.count
+}
+
+// wait 等待所有进行中的请求完成，ctx 到期时返回 false
+func (t *inflightTracker) wait(ctx context.Context) bool {
+	t.mu.Lock()
+	idle := t.idle
+	t.mu.Unlock()
+
+	select {
+	case <-idle:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
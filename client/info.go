@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"errors"
+	pb "srpc/proto"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// serverInfoTimeout 连接建立后查询服务端信息的超时时间
+const serverInfoTimeout = 2 * time.Second
+
+// ServerInfo 查询服务端版本、Git 提交、运行时长等信息
+func (c *GRPCClient) ServerInfo(ctx context.Context) (*pb.ServerInfo, error) {
+	pc := c.pickConn()
+	c.mu.RLock()
+	greeter := pc.greeter
+	c.mu.RUnlock()
+	if greeter == nil {
+		return nil, errors.New("连接尚未建立")
+	}
+	return greeter.GetServerInfo(ctx, &emptypb.Empty{})
+}
+
+// logServerInfo 在连接成功后记录一次服务端版本，便于排查版本不一致问题
+func (c *GRPCClient) logServerInfo(pc *poolConn, greeter greeterClient) {
+	ctx, cancel := context.WithTimeout(c.ctx, serverInfoTimeout)
+	defer cancel()
+
+	info, err := greeter.GetServerInfo(ctx, &emptypb.Empty{})
+	if err != nil {
+		// 旧版本服务端未实现该接口，不视为错误
+		if status.Code(err) != codes.Unimplemented {
+			c.slogger.Warn("查询服务端信息失败", map[string]interface{}{
+				"conn_index": pc.index,
+				"error":      err.Error(),
+			})
+		}
+		return
+	}
+
+	c.slogger.Info("服务端信息", map[string]interface{}{
+		"conn_index":     pc.index,
+		"server_version": info.GetVersion(),
+		"git_commit":     info.GetGitCommit(),
+		"build_time":     info.GetBuildTime(),
+		"go_version":     info.GetGoVersion(),
+		"uptime_seconds": info.GetUptimeSeconds(),
+		"compressors":    info.GetCompressors(),
+	})
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"srpc/client/mocks"
+)
+
+// jitterClient 创建请求间隔为 interval、抖动百分比为 percent 的客户端
+func jitterClient(t *testing.T, interval time.Duration, percent int, seed uint64) *GRPCClient {
+	t.Helper()
+	cfg := mockConfig()
+	cfg.RequestInterval = interval
+	cfg.JitterPercent = percent
+	cfg.JitterSeed = seed
+	return newMockClient(t, cfg, &mocks.Greeter{})
+}
+
+// jitterSequence 返回连续 n 次计算的间隔
+func jitterSequence(c *GRPCClient, n int) []time.Duration {
+	seq := make([]time.Duration, n)
+	for i := range seq {
+		seq[i] = c.calculateJitteredInterval()
+	}
+	return seq
+}
+
+func TestJitteredIntervalWithinRange(t *testing.T) {
+	const base = time.Hour
+	c := jitterClient(t, base, 20, 0)
+
+	// 抖动范围为 JitterPercent 的一半，上下对称
+	low, high := base-base/10, base+base/10
+	for i, interval := range jitterSequence(c, 1000) {
+		if interval < low || interval > high {
+			t.Fatalf("第 %d 次间隔 %v 超出 [%v, %v]", i+1, interval, low, high)
+		}
+	}
+}
+
+func TestJitteredIntervalAtLeastOneMillisecond(t *testing.T) {
+	c := jitterClient(t, time.Millisecond, 100, 0)
+
+	var clamped bool
+	for i, interval := range jitterSequence(c, 1000) {
+		if interval < time.Millisecond {
+			t.Fatalf("第 %d 次间隔 %v 小于 1ms", i+1, interval)
+		}
+		clamped = clamped || interval == time.Millisecond
+	}
+	if !clamped {
+		t.Error("向下抖动的间隔应截断为 1ms")
+	}
+}
+
+func TestJitteredIntervalDisabled(t *testing.T) {
+	c := jitterClient(t, time.Hour, 0, 0)
+	for _, interval := range jitterSequence(c, 10) {
+		if interval != time.Hour {
+			t.Fatalf("JitterPercent 为 0 时间隔 = %v，期望 %v", interval, time.Hour)
+		}
+	}
+}
+
+func TestJitterSeedIsDeterministic(t *testing.T) {
+	a := jitterSequence(jitterClient(t, time.Hour, 50, 42), 20)
+	b := jitterSequence(jitterClient(t, time.Hour, 50, 42), 20)
+	other := jitterSequence(jitterClient(t, time.Hour, 50, 43), 20)
+
+	if !slices.Equal(a, b) {
+		t.Errorf("相同 JitterSeed 生成的序列不同:\n%v\n%v", a, b)
+	}
+	if slices.Equal(a, other) {
+		t.Error("不同 JitterSeed 生成了相同的序列")
+	}
+	if slices.Min(a) == slices.Max(a) {
+		t.Error("固定种子的序列没有抖动")
+	}
+}
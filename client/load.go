@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// LoadResult 压测结果
+type LoadResult struct {
+	Total       int64         // 发出的请求数
+	Success     int64         // 成功数
+	Failures    int64         // 失败数（包括熔断拒绝和无可用连接）
+	P50         time.Duration // 延迟中位数
+	P90         time.Duration
+	P99         time.Duration
+	Max         time.Duration
+	Elapsed     time.Duration // 实际压测时长
+	AchievedRPS float64       // 实际达到的每秒请求数
+}
+
+// RunLoad 以目标速率 rps 持续发送 SayHello，持续 duration 或直到 ctx 取消，返回汇总结果
+// 并发数由信号量限制：配置了 MaxConcurrentRequests 时与其他请求共享客户端信号量，否则最多 rps 个并发；
+// 并发打满时发送节奏随之放缓，实际速率体现在 AchievedRPS 中。请求同样计入客户端指标
+func (c *GRPCClient) RunLoad(ctx context.Context, rps int, duration time.Duration) (LoadResult, error) {
+	if rps <= 0 {
+		return LoadResult{}, fmt.Errorf("无效的目标速率: %d", rps)
+	}
+	if duration <= 0 {
+		return LoadResult{}, fmt.Errorf("无效的压测时长: %v", duration)
+	}
+
+	sem := c.semaphore
+	if sem == nil {
+		sem = NewSemaphore(rps)
+	}
+
+	// loadCtx 控制发送窗口；已发出的请求使用调用方的 ctx，压测结束时不会被中途取消
+	loadCtx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	var (
+		mu        sync.Mutex
+		result    LoadResult
+		latencies []time.Duration
+		wg        sync.WaitGroup
+	)
+	record := func(latency time.Duration, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		result.Total++
+		if err != nil {
+			result.Failures++
+			return
+		}
+		result.Success++
+		latencies = append(latencies, latency)
+	}
+
+	c.slogger.Info("开始压测", map[string]interface{}{"target_rps": rps, "duration": duration.String(), "concurrency": sem.Capacity()})
+
+	start := time.Now()
+	ticker := time.NewTicker(time.Second / time.Duration(rps))
+	defer ticker.Stop()
+
+loop:
+	for seq := 0; ; seq++ {
+		select {
+		case <-loadCtx.Done():
+			break loop
+		case <-ticker.C:
+		}
+		if c.IsShutting() || !sem.Acquire(loadCtx) {
+			break loop
+		}
+
+		wg.Add(1)
+		go func(seq int) {
+			defer wg.Done()
+			defer sem.Release()
+
+			if !c.circuitBreaker.AllowRequest() {
+				record(0, ErrCircuitOpen)
+				return
+			}
+			pc, _, err := c.readyConn()
+			if err != nil {
+				record(0, err)
+				return
+			}
+			begin := time.Now()
+			_, err = c.sayHello(ctx, pc, fmt.Sprintf("Load-%d", seq))
+			record(time.Since(begin), err)
+		}(seq)
+	}
+	wg.Wait()
+
+	result.Elapsed = time.Since(start)
+	if result.Elapsed > 0 {
+		result.AchievedRPS = float64(result.Total) / result.Elapsed.Seconds()
+	}
+	slices.Sort(latencies)
+	result.P50 = percentile(latencies, 0.50)
+	result.P90 = percentile(latencies, 0.90)
+	result.P99 = percentile(latencies, 0.99)
+	if len(latencies) > 0 {
+		result.Max = latencies[len(latencies)-1]
+	}
+
+	c.slogger.Info("压测完成", map[string]interface{}{
+		"total":        result.Total,
+		"success":      result.Success,
+		"failures":     result.Failures,
+		"achieved_rps": result.AchievedRPS,
+		"p50":          result.P50.String(),
+		"p90":          result.P90.String(),
+		"p99":          result.P99.String(),
+		"max":          result.Max.String(),
+	})
+
+	// 调用方主动取消时返回取消原因，正常到达压测时长不视为错误
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// percentile 返回已排序样本的 p 分位值（最近秩法）
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(float64(len(sorted))*p+0.5) - 1
+	idx = max(0, min(idx, len(sorted)-1))
+	return sorted[idx]
+}
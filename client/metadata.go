@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// metadataUnaryInterceptor 为每个普通 RPC 追加配置的 metadata
+func (c *GRPCClient) metadataUnaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(c.appendMetadata(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// metadataStreamInterceptor 为每个流式 RPC 追加配置的 metadata
+func (c *GRPCClient) metadataStreamInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(c.appendMetadata(ctx), desc, cc, method, opts...)
+	}
+}
+
+// appendMetadata 将静态 Metadata 和 MetadataFunc 生成的 metadata 追加到出站 context
+// 使用追加而不是覆盖，请求 ID 等已写入的 metadata 得以保留；同名键会同时携带多个值
+func (c *GRPCClient) appendMetadata(ctx context.Context) context.Context {
+	kv := make([]string, 0, 2*len(c.config.Metadata))
+	for k, v := range c.config.Metadata {
+		kv = append(kv, k, v)
+	}
+	if c.config.MetadataFunc != nil {
+		for k, values := range c.config.MetadataFunc(ctx) {
+			for _, v := range values {
+				kv = append(kv, k, v)
+			}
+		}
+	}
+	if len(kv) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
@@ -1,10 +1,25 @@
 package client
 
 import (
+	"context"
+	"errors"
+	"maps"
+	"srpc/pkg/stats"
+	"srpc/pkg/tools"
 	"sync"
 	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// connStats 单个连接的统计
+type connStats struct {
+	requests   int64
+	failures   int64
+	reconnects int64
+}
+
 // Metrics 指标收集器
 type Metrics struct {
 	mu                   sync.RWMutex
@@ -12,37 +27,471 @@ type Metrics struct {
 	successfulRequests   int64
 	failedRequests       int64
 	totalRequestDuration time.Duration
+	requestLatency       latencyHistogram // 单次尝试的延迟分布
 	reconnectCount       int64
+	retryCount           int64         // 实际执行的重试次数
+	reconnectBudgetReset int64         // 重连尝试预算重置次数
+	retryBudgetConsumed  int64         // 消耗重试预算的次数
+	retriesSuppressed    int64         // 因重试预算不足被拒绝的重试次数
+	rateLimitedRequests  int64         // 因限流而等待的请求次数
+	rateLimitWait        time.Duration // 限流等待时间累计
+	requestsDrained      int64         // 关闭时等待完成的进行中请求数
+	requestsAborted      int64         // 关闭时因排空超时被取消的请求数
+	cacheHits            int64         // SendNow 命中响应缓存的次数
+	cacheMisses          int64         // SendNow 未命中响应缓存的次数
+	dispatchDropped      int64         // 后台请求工作池因队列溢出或关闭丢弃的请求数
 	lastRequestTimestamp time.Time
+	pingCount            int64                    // 成功的 Ping 探测次数
+	totalPingRTT         time.Duration            // Ping 往返时间累计
+	lastPingRTT          time.Duration            // 最近一次 Ping 往返时间
+	lastClockSkew        time.Duration            // 最近一次估算的服务端时钟偏差（服务端减客户端）
+	connections          map[int]*connStats       // 按连接池序号统计
+	transfer             stats.Totals             // 传输层字节统计（全部方法）
+	methodTransfer       map[string]*stats.Totals // 传输层字节统计（按方法）
+
+	errorsByCode      map[codes.Code]int64 // 单次尝试失败数，按 gRPC 状态码统计
+	finalErrorsByCode map[codes.Code]int64 // 重试结束后仍失败的逻辑请求数，按最终状态码统计
+	retriedAway       int64                // 失败后经重试最终成功的尝试数，即被重试消化的瞬时错误
+	hedgedRequests    int64                // 追加发送的对冲请求数
+	hedgeWins         int64                // 对冲请求先于原始请求成功返回的次数
+	window            *rollingWindow       // 最近 15 分钟按秒统计的请求结果
+
+	serverTimed          int64         // 服务端在 trailer 中返回了处理耗时的尝试数
+	totalServerDuration  time.Duration // 服务端处理耗时累计
+	totalNetworkOverhead time.Duration // 端到端耗时减去服务端处理耗时的累计，包括网络传输、排队和序列化
+
+	instances map[string]int64 // 按服务端实例 ID 统计的尝试数，服务端未返回实例 ID 时不计入
+
+	// 整体连接状态的可用性统计，由 refreshStateLocked 在离开或进入已连接状态时记录
+	connectedSince time.Time     // 最近一次进入已连接状态的时间，断开期间为零值
+	lastConnected  time.Time     // 最近一次成功连接的时间
+	outageStart    time.Time     // 本次断开开始的时间，已连接或从未连接成功时为零值
+	lastOutage     time.Duration // 最近一次已结束的断开时长
+	totalDowntime  time.Duration // 已结束的断开时长累计
+	outages        int64         // 已结束的断开次数
+
+	clock tools.Clock // 滑动窗口、可用性统计和最近请求时间使用的时钟
+
+	dispatchStats func() tools.WorkerPoolStats // 当前运行的后台请求工作池状态，未启用时为 nil
+	semaphore     *Semaphore                   // 并发请求信号量，未限制并发时为 nil
 }
 
 // NewMetrics 创建新的指标收集器
 func NewMetrics() *Metrics {
+	clock := tools.RealClock{}
 	return &Metrics{
-		lastRequestTimestamp: time.Now(),
+		lastRequestTimestamp: clock.Now(),
+		connections:          make(map[int]*connStats),
+		methodTransfer:       make(map[string]*stats.Totals),
+		instances:            make(map[string]int64),
+		errorsByCode:         make(map[codes.Code]int64),
+		finalErrorsByCode:    make(map[codes.Code]int64),
+		window:               newRollingWindow(clock),
+		clock:                clock,
+	}
+}
+
+// setClock 指定滑动窗口、可用性统计和最近请求时间使用的时钟并清空窗口，测试中可传入 tools.FakeClock
+func (m *Metrics) setClock(clock tools.Clock) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.window = newRollingWindow(clock)
+	m.clock = clock
+	m.lastRequestTimestamp = clock.Now()
+}
+
+// RecordConnected 记录整体连接进入已连接状态，之前处于断开中时结束本次断开并累计时长
+func (m *Metrics) RecordConnected() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.clock.Now()
+	if !m.outageStart.IsZero() {
+		m.lastOutage = now.Sub(m.outageStart)
+		m.totalDowntime += m.lastOutage
+		m.outages++
+		m.outageStart = time.Time{}
 	}
+	m.connectedSince = now
+	m.lastConnected = now
 }
 
-// RecordRequest 记录请求指标
-func (m *Metrics) RecordRequest(success bool, duration time.Duration) {
+// RecordDisconnected 记录整体连接离开已连接状态（断开、重连中或降级），首次连接成功之前不计为断开
+func (m *Metrics) RecordDisconnected() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.connectedSince.IsZero() {
+		return
+	}
+	m.outageStart = m.clock.Now()
+	m.connectedSince = time.Time{}
+}
+
+// windowErrorRate 返回最近 span 内且不早于 since 的错误率（0-1）和请求数，供熔断器按错误率触发
+func (m *Metrics) windowErrorRate(span time.Duration, since time.Time) (float64, int64) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	requests, failures := m.window.sum(span, since)
+	if requests == 0 {
+		return 0, 0
+	}
+	return float64(failures) / float64(requests), requests
+}
+
+// RecordRequest 记录一次尝试的结果，err 为 nil 表示成功，失败时按状态码计入 errors_by_code
+func (m *Metrics) RecordRequest(err error, duration time.Duration) {
+	// 在加锁前解析状态码，缩短持锁时间
+	code := errorCode(err)
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	m.totalRequests++
-	if success {
+	if err == nil {
 		m.successfulRequests++
 	} else {
 		m.failedRequests++
+		m.errorsByCode[code]++
 	}
+	m.window.observe(err == nil)
 	m.totalRequestDuration += duration
-	m.lastRequestTimestamp = time.Now()
+	m.requestLatency.observe(duration)
+	m.lastRequestTimestamp = m.clock.Now()
+}
+
+// RecordServerDuration 记录一次尝试中服务端返回的处理耗时和据此估算的网络开销
+func (m *Metrics) RecordServerDuration(server, overhead time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.serverTimed++
+	m.totalServerDuration += server
+	m.totalNetworkOverhead += overhead
+}
+
+// RecordInstance 记录一次由指定服务端实例处理的尝试
+func (m *Metrics) RecordInstance(instance string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.instances[instance]++
+}
+
+// RecordConnRequest 记录单个连接上的请求结果
+func (m *Metrics) RecordConnRequest(conn int, success bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := m.connStatsLocked(conn)
+	stats.requests++
+	if !success {
+		stats.failures++
+	}
 }
 
 // RecordReconnect 记录重连指标
-func (m *Metrics) RecordReconnect() {
+func (m *Metrics) RecordReconnect(conn int) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.reconnectCount++
+	m.connStatsLocked(conn).reconnects++
+}
+
+// RecordPayload 记录传输层消息统计，实现 stats.Recorder
+func (m *Metrics) RecordPayload(p stats.Payload) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.transfer.Add(p)
+	totals, ok := m.methodTransfer[p.Method]
+	if !ok {
+		totals = &stats.Totals{}
+		m.methodTransfer[p.Method] = totals
+	}
+	totals.Add(p)
+}
+
+// RecordRetry 记录一次重试
+func (m *Metrics) RecordRetry() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retryCount++
+}
+
+// RecordRetryOutcome 记录一次逻辑请求在重试结束后的结果
+// 最终失败时按状态码计入 final_errors_by_code；最终成功时之前失败的 failedAttempts 次尝试计入 errors_retried_away
+func (m *Metrics) RecordRetryOutcome(err error, failedAttempts int) {
+	code := errorCode(err)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		m.finalErrorsByCode[code]++
+		return
+	}
+	m.retriedAway += int64(failedAttempts)
+}
+
+// errorCode 返回错误对应的 gRPC 状态码，nil 为 OK
+// 不含状态的错误中 context 超时和取消分别归为 DeadlineExceeded、Canceled，其余归为 Unknown
+func errorCode(err error) codes.Code {
+	if st, ok := status.FromError(err); ok {
+		return st.Code()
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	default:
+		return codes.Unknown
+	}
+}
+
+// codeCounts 将按状态码的计数转换为以状态码名称为键的映射，便于序列化
+func codeCounts(counts map[codes.Code]int64) map[string]int64 {
+	out := make(map[string]int64, len(counts))
+	for code, n := range counts {
+		out[code.String()] = n
+	}
+	return out
+}
+
+// RecordHedge 记录一次追加发送的对冲请求
+func (m *Metrics) RecordHedge() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hedgedRequests++
+}
+
+// RecordHedgeWin 记录一次对冲请求先于原始请求成功
+func (m *Metrics) RecordHedgeWin() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hedgeWins++
+}
+
+// RecordReconnectBudgetReset 记录一次重连尝试预算重置
+func (m *Metrics) RecordReconnectBudgetReset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reconnectBudgetReset++
+}
+
+// RecordPing 记录一次成功 Ping 的往返时间和时钟偏差估算
+func (m *Metrics) RecordPing(rtt, clockSkew time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pingCount++
+	m.totalPingRTT += rtt
+	m.lastPingRTT = rtt
+	m.lastClockSkew = clockSkew
+}
+
+// RecordRetryBudgetWithdrawal 记录一次消耗重试预算的重试
+func (m *Metrics) RecordRetryBudgetWithdrawal() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retryBudgetConsumed++
+}
+
+// RecordRetrySuppressed 记录一次因重试预算不足被拒绝的重试
+func (m *Metrics) RecordRetrySuppressed() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retriesSuppressed++
+}
+
+// RecordRateLimitWait 记录一次因限流产生的等待
+func (m *Metrics) RecordRateLimitWait(wait time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rateLimitedRequests++
+	m.rateLimitWait += wait
+}
+
+// RecordShutdownDrain 记录关闭时排空完成和被取消的请求数
+func (m *Metrics) RecordShutdownDrain(drained, aborted int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requestsDrained += int64(drained)
+	m.requestsAborted += int64(aborted)
+}
+
+// RecordCacheHit 记录一次响应缓存命中
+func (m *Metrics) RecordCacheHit() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cacheHits++
 }
 
+// RecordCacheMiss 记录一次响应缓存未命中
+func (m *Metrics) RecordCacheMiss() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cacheMisses++
+}
+
+// RecordDispatchDropped 记录一次被后台请求工作池丢弃的请求
+func (m *Metrics) RecordDispatchDropped() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dispatchDropped++
+}
+
+// setDispatchStats 设置读取后台请求工作池状态的函数，传入 nil 表示工作池已停止
+func (m *Metrics) setDispatchStats(fn func() tools.WorkerPoolStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dispatchStats = fn
+}
+
+// connStatsLocked 获取或创建连接统计，调用方需持有写锁
+func (m *Metrics) connStatsLocked(conn int) *connStats {
+	stats, ok := m.connections[conn]
+	if !ok {
+		stats = &connStats{}
+		m.connections[conn] = stats
+	}
+	return stats
+}
+
+// Reset 清零全部计数、累计值、延迟分布和滑动窗口，用于在已知时间点开始新一轮统计而无需重启
+// 与各 Record 方法互斥，进行中的记录要么完整计入重置前、要么完整计入重置后，不会只写入一半；
+// 熔断器按错误率判断时使用同一滑动窗口，重置后需重新积累 CircuitBreakerMinRequests 个请求
+// 最近一次请求时间、Ping RTT、时钟偏差、当前连接时长等最新观测值以及工作池、信号量等瞬时状态保持不变
+func (m *Metrics) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
+	m.totalRequests = 0
+	m.successfulRequests = 0
+	m.failedRequests = 0
+	m.totalRequestDuration = 0
+	m.requestLatency = latencyHistogram{}
+	m.reconnectCount = 0
+	m.retryCount = 0
+	m.reconnectBudgetReset = 0
+	m.retryBudgetConsumed = 0
+	m.retriesSuppressed = 0
+	m.rateLimitedRequests = 0
+	m.rateLimitWait = 0
+	m.requestsDrained = 0
+	m.requestsAborted = 0
+	m.cacheHits = 0
+	m.cacheMisses = 0
+	m.dispatchDropped = 0
+	m.pingCount = 0
+	m.totalPingRTT = 0
+	m.connections = make(map[int]*connStats)
+	m.transfer = stats.Totals{}
+	m.methodTransfer = make(map[string]*stats.Totals)
+	m.errorsByCode = make(map[codes.Code]int64)
+	m.finalErrorsByCode = make(map[codes.Code]int64)
+	m.retriedAway = 0
+	m.hedgedRequests = 0
+	m.hedgeWins = 0
+	m.serverTimed = 0
+	m.totalServerDuration = 0
+	m.totalNetworkOverhead = 0
+	m.instances = make(map[string]int64)
+	m.lastOutage = 0
+	m.totalDowntime = 0
+	m.outages = 0
+	if !m.outageStart.IsZero() {
+		// 进行中的断开从重置时刻起计算
+		m.outageStart = m.clock.Now()
+	}
+	m.window = newRollingWindow(m.clock)
+}
+
+// GetMetrics 获取指标快照，返回值与收集器不共享数据
+func (m *Metrics) GetMetrics() Snapshot {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	connections := make(map[int]ConnSnapshot, len(m.connections))
+	for index, stats := range m.connections {
+		connections[index] = ConnSnapshot{
+			Requests:   stats.requests,
+			Failures:   stats.failures,
+			Reconnects: stats.reconnects,
+		}
+	}
+
+	methodTransfer := make(map[string]stats.Totals, len(m.methodTransfer))
+	for method, totals := range m.methodTransfer {
+		methodTransfer[method] = *totals
+	}
+
+	windows := make(map[string]WindowStats, len(rollingWindows))
+	for _, w := range rollingWindows {
+		windows[w.name] = m.window.snapshot(w.span)
+	}
+
+	now := m.clock.Now()
+	totalDowntime := m.totalDowntime
+	if !m.outageStart.IsZero() {
+		totalDowntime += now.Sub(m.outageStart)
+	}
+	var uptime time.Duration
+	if !m.connectedSince.IsZero() {
+		uptime = now.Sub(m.connectedSince)
+	}
+
+	s := Snapshot{
+		TakenAt:               now,
+		TotalRequests:         m.totalRequests,
+		SuccessfulRequests:    m.successfulRequests,
+		FailedRequests:        m.failedRequests,
+		Windows:               windows,
+		TotalRequestDuration:  m.totalRequestDuration,
+		P50Duration:           m.requestLatency.quantile(0.50),
+		P90Duration:           m.requestLatency.quantile(0.90),
+		P99Duration:           m.requestLatency.quantile(0.99),
+		MaxDuration:           m.requestLatency.max,
+		ServerTimedRequests:   m.serverTimed,
+		TotalServerDuration:   m.totalServerDuration,
+		TotalNetworkOverhead:  m.totalNetworkOverhead,
+		InstanceRequests:      maps.Clone(m.instances),
+		ErrorsByCode:          codeCounts(m.errorsByCode),
+		FinalErrorsByCode:     codeCounts(m.finalErrorsByCode),
+		ErrorsRetriedAway:     m.retriedAway,
+		HedgedRequests:        m.hedgedRequests,
+		HedgeWins:             m.hedgeWins,
+		TotalRetries:          m.retryCount,
+		ReconnectCount:        m.reconnectCount,
+		ReconnectBudgetResets: m.reconnectBudgetReset,
+		RetryBudgetConsumed:   m.retryBudgetConsumed,
+		RetriesSuppressed:     m.retriesSuppressed,
+		RateLimitedRequests:   m.rateLimitedRequests,
+		RateLimitWait:         m.rateLimitWait,
+		RequestsDrained:       m.requestsDrained,
+		RequestsAborted:       m.requestsAborted,
+		CacheHits:             m.cacheHits,
+		CacheMisses:           m.cacheMisses,
+		DispatchDropped:       m.dispatchDropped,
+		LastRequestTime:       m.lastRequestTimestamp,
+		LastPingRTT:           m.lastPingRTT,
+		LastClockSkew:         m.lastClockSkew,
+		LastOutageDuration:    m.lastOutage,
+		TotalDowntime:         totalDowntime,
+		Outages:               m.outages,
+		LastConnectedTime:     m.lastConnected,
+		ConnectionUptime:      uptime,
+		PingCount:             m.pingCount,
+		TotalPingRTT:          m.totalPingRTT,
+		Connections:           connections,
+		Transfer:              m.transfer,
+		MethodTransfer:        methodTransfer,
+		latency:               m.requestLatency.clone(),
+	}
+	if m.dispatchStats != nil {
+		stats := m.dispatchStats()
+		s.DispatchQueueDepth, s.DispatchRunning = stats.QueueDepth, stats.Running
+	}
+	if m.semaphore != nil {
+		s.ConcurrencyInUse, s.ConcurrencyLimit, s.ConcurrencyWaiting = m.semaphore.InUse(), m.semaphore.Capacity(), m.semaphore.Waiting()
+	}
+	s.computeDerived()
+	return s
+}
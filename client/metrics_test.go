@@ -0,0 +1,288 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"srpc/client/mocks"
+	"srpc/pkg/stats"
+	"srpc/pkg/tools"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMetricsUsesInjectedClock(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := tools.NewFakeClock(start)
+	m := NewMetrics()
+	m.setClock(clock)
+
+	if got := m.GetMetrics().LastRequestTime; !got.Equal(start) {
+		t.Errorf("初始 LastRequestTime = %v，期望 %v", got, start)
+	}
+
+	clock.Advance(time.Minute)
+	m.RecordRequest(nil, time.Millisecond)
+	snap := m.GetMetrics()
+	if want := start.Add(time.Minute); !snap.LastRequestTime.Equal(want) {
+		t.Errorf("LastRequestTime = %v，期望 %v", snap.LastRequestTime, want)
+	}
+	if !snap.TakenAt.Equal(start.Add(time.Minute)) {
+		t.Errorf("TakenAt = %v，期望使用假时钟", snap.TakenAt)
+	}
+}
+
+func TestMetricsErrorsByCode(t *testing.T) {
+	m := NewMetrics()
+	errs := []error{
+		nil,
+		status.Error(codes.Unavailable, "服务不可用"),
+		status.Error(codes.Unavailable, "服务不可用"),
+		fmt.Errorf("调用失败: %w", status.Error(codes.InvalidArgument, "参数错误")),
+		context.DeadlineExceeded,
+		fmt.Errorf("等待响应: %w", context.Canceled),
+		errors.New("不含状态的错误"),
+	}
+	for _, err := range errs {
+		m.RecordRequest(err, time.Millisecond)
+	}
+
+	snap := m.GetMetrics()
+	want := map[string]int64{
+		"Unavailable":      2,
+		"InvalidArgument":  1,
+		"DeadlineExceeded": 1,
+		"Canceled":         1,
+		"Unknown":          1,
+	}
+	if !maps.Equal(snap.ErrorsByCode, want) {
+		t.Errorf("ErrorsByCode = %v，期望 %v", snap.ErrorsByCode, want)
+	}
+	if snap.FailedRequests != 6 || snap.SuccessfulRequests != 1 {
+		t.Errorf("失败 %d、成功 %d，期望 6 和 1", snap.FailedRequests, snap.SuccessfulRequests)
+	}
+
+	// 快照中的映射是副本，修改不影响后续快照
+	snap.ErrorsByCode["Unavailable"] = 100
+	if got := m.GetMetrics().ErrorsByCode["Unavailable"]; got != 2 {
+		t.Errorf("修改快照后 Unavailable = %d，期望 2", got)
+	}
+}
+
+func TestMetricsRetryOutcome(t *testing.T) {
+	m := NewMetrics()
+	m.RecordRetryOutcome(nil, 2)
+	m.RecordRetryOutcome(nil, 0)
+	m.RecordRetryOutcome(status.Error(codes.Unavailable, "服务不可用"), 3)
+	m.RecordRetryOutcome(errors.New("不含状态的错误"), 1)
+
+	snap := m.GetMetrics()
+	if snap.ErrorsRetriedAway != 2 {
+		t.Errorf("ErrorsRetriedAway = %d，期望 2", snap.ErrorsRetriedAway)
+	}
+	if want := map[string]int64{"Unavailable": 1, "Unknown": 1}; !maps.Equal(snap.FinalErrorsByCode, want) {
+		t.Errorf("FinalErrorsByCode = %v，期望 %v", snap.FinalErrorsByCode, want)
+	}
+
+	m.Reset()
+	if snap := m.GetMetrics(); len(snap.FinalErrorsByCode) != 0 || snap.ErrorsRetriedAway != 0 {
+		t.Errorf("Reset 后 FinalErrorsByCode=%v ErrorsRetriedAway=%d，期望清零", snap.FinalErrorsByCode, snap.ErrorsRetriedAway)
+	}
+}
+
+func TestSendNowRecordsErrorsByCode(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "服务不可用")
+	greeter := &mocks.Greeter{SayHelloFunc: mocks.SayHelloSequence(
+		unavailable, unavailable, nil, // 重试两次后成功
+		status.Error(codes.InvalidArgument, "参数错误"), // 不可重试，直接失败
+	)}
+	cfg := mockConfig()
+	cfg.MaxRetries = 3
+	c := newMockClient(t, cfg, greeter)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := c.SendNow(ctx, "retried"); err != nil {
+		t.Fatalf("重试后 SendNow 失败: %v", err)
+	}
+	if _, err := c.SendNow(ctx, "invalid"); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("SendNow 错误 = %v，期望 InvalidArgument", err)
+	}
+
+	snap := c.GetMetrics()
+	if want := map[string]int64{"Unavailable": 2, "InvalidArgument": 1}; !maps.Equal(snap.ErrorsByCode, want) {
+		t.Errorf("ErrorsByCode = %v，期望 %v", snap.ErrorsByCode, want)
+	}
+	if want := map[string]int64{"InvalidArgument": 1}; !maps.Equal(snap.FinalErrorsByCode, want) {
+		t.Errorf("FinalErrorsByCode = %v，期望 %v", snap.FinalErrorsByCode, want)
+	}
+	if snap.ErrorsRetriedAway != 2 {
+		t.Errorf("ErrorsRetriedAway = %d，期望 2", snap.ErrorsRetriedAway)
+	}
+}
+
+// sumCounts 返回按状态码统计的计数之和
+func sumCounts(counts map[string]int64) int64 {
+	var total int64
+	for _, n := range counts {
+		total += n
+	}
+	return total
+}
+
+func TestMetricsResetDuringConcurrentRecord(t *testing.T) {
+	m := NewMetrics()
+	unavailable := status.Error(codes.Unavailable, "服务不可用")
+
+	const goroutines, perGoroutine = 8, 5000
+	var wg sync.WaitGroup
+	for g := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perGoroutine {
+				var err error
+				if (g+i)%4 == 0 {
+					err = unavailable
+				}
+				m.RecordRequest(err, time.Millisecond)
+				m.RecordConnRequest(g%2, err == nil)
+			}
+		}()
+	}
+
+	// 记录过程中反复重置，每个快照内的计数必须相互一致，不能出现只更新了一半的记录
+	stop := make(chan struct{})
+	resetDone := make(chan struct{})
+	go func() {
+		defer close(resetDone)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			m.Reset()
+			snap := m.GetMetrics()
+			if snap.TotalRequests != snap.SuccessfulRequests+snap.FailedRequests {
+				t.Errorf("快照总数 %d 不等于成功 %d 加失败 %d", snap.TotalRequests, snap.SuccessfulRequests, snap.FailedRequests)
+				return
+			}
+			if sum := sumCounts(snap.ErrorsByCode); sum != snap.FailedRequests {
+				t.Errorf("ErrorsByCode 之和 %d 不等于失败数 %d", sum, snap.FailedRequests)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+	close(stop)
+	<-resetDone
+
+	// 重置后记录的请求一个不丢
+	m.Reset()
+	for i := range 100 {
+		var err error
+		if i%4 == 0 {
+			err = unavailable
+		}
+		m.RecordRequest(err, time.Millisecond)
+	}
+	snap := m.GetMetrics()
+	if snap.TotalRequests != 100 || snap.SuccessfulRequests != 75 || snap.ErrorsByCode["Unavailable"] != 25 {
+		t.Errorf("重置后总数 %d、成功 %d、Unavailable %d，期望 100、75、25",
+			snap.TotalRequests, snap.SuccessfulRequests, snap.ErrorsByCode["Unavailable"])
+	}
+	if snap.Windows["1m"].Requests != 100 {
+		t.Errorf("重置后 1m 窗口请求数 = %d，期望 100", snap.Windows["1m"].Requests)
+	}
+}
+
+func TestSnapshotDelta(t *testing.T) {
+	m := NewMetrics()
+	unavailable := status.Error(codes.Unavailable, "服务不可用")
+	const method = "/Greeter/SayHello"
+
+	m.RecordRequest(nil, 10*time.Millisecond)
+	m.RecordRequest(unavailable, 10*time.Millisecond)
+	m.RecordConnRequest(0, true)
+	m.RecordPayload(stats.Payload{Method: method, Direction: stats.Outbound, Length: 100, CompressedLength: 100, WireLength: 105})
+	prev := m.GetMetrics()
+
+	m.RecordRequest(nil, 30*time.Millisecond)
+	m.RecordRequest(nil, 30*time.Millisecond)
+	m.RecordRequest(unavailable, 30*time.Millisecond)
+	m.RecordRequest(status.Error(codes.InvalidArgument, "参数错误"), 30*time.Millisecond)
+	m.RecordConnRequest(0, false)
+	m.RecordConnRequest(1, true)
+	m.RecordRetry()
+	m.RecordPayload(stats.Payload{Method: method, Direction: stats.Outbound, Length: 40, CompressedLength: 40, WireLength: 45})
+
+	d := m.GetMetrics().Delta(prev)
+	if d.TotalRequests != 4 || d.SuccessfulRequests != 2 || d.FailedRequests != 2 || d.TotalRetries != 1 {
+		t.Errorf("增量总数 %d、成功 %d、失败 %d、重试 %d，期望 4、2、2、1",
+			d.TotalRequests, d.SuccessfulRequests, d.FailedRequests, d.TotalRetries)
+	}
+	if d.SuccessRate != 50 || d.AvgDuration != 30*time.Millisecond {
+		t.Errorf("增量成功率 %v、平均耗时 %v，期望按区间重新计算为 50%% 和 30ms", d.SuccessRate, d.AvgDuration)
+	}
+	if want := map[string]int64{"Unavailable": 1, "InvalidArgument": 1}; !maps.Equal(d.ErrorsByCode, want) {
+		t.Errorf("增量 ErrorsByCode = %v，期望 %v", d.ErrorsByCode, want)
+	}
+	if got := d.Connections[0]; got != (ConnSnapshot{Requests: 1, Failures: 1}) {
+		t.Errorf("连接 0 的增量 = %+v，期望 1 次请求 1 次失败", got)
+	}
+	if got := d.Connections[1]; got != (ConnSnapshot{Requests: 1}) {
+		t.Errorf("连接 1 的增量 = %+v，期望 1 次请求", got)
+	}
+	if got := d.MethodTransfer[method]; got.MessagesSent != 1 || got.BytesSent != 45 {
+		t.Errorf("%s 的传输增量 = %+v，期望 1 条消息 45 字节", method, got)
+	}
+
+	// 与自身的增量为零
+	if self := prev.Delta(prev); self.TotalRequests != 0 || len(self.ErrorsByCode) != 0 || self.SuccessRate != 0 {
+		t.Errorf("自身增量 = 总数 %d、ErrorsByCode %v、成功率 %v，期望全为零", self.TotalRequests, self.ErrorsByCode, self.SuccessRate)
+	}
+}
+
+func TestDebugMetricsReset(t *testing.T) {
+	greeter := &mocks.Greeter{SayHelloFunc: mocks.SayHelloSequence(nil)}
+	c := newMockClient(t, mockConfig(), greeter)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := c.SendNow(ctx, "reset"); err != nil {
+		t.Fatalf("SendNow 失败: %v", err)
+	}
+
+	srv := httptest.NewServer(c.debugHandler())
+	defer srv.Close()
+
+	// 只接受 POST，GET 不应清空指标
+	resp, err := http.Get(srv.URL + "/debug/metrics/reset")
+	if err != nil {
+		t.Fatalf("GET 失败: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed || c.GetMetrics().TotalRequests != 1 {
+		t.Errorf("GET 状态码 %d、总数 %d，期望 405 且指标不变", resp.StatusCode, c.GetMetrics().TotalRequests)
+	}
+
+	resp, err = http.Post(srv.URL+"/debug/metrics/reset", "", nil)
+	if err != nil {
+		t.Fatalf("POST 失败: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("POST 状态码 = %d，期望 204", resp.StatusCode)
+	}
+	if got := c.GetMetrics().TotalRequests; got != 0 {
+		t.Errorf("重置后总数 = %d，期望 0", got)
+	}
+}
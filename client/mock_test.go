@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"srpc/client/mocks"
+	pb "srpc/proto"
+)
+
+// mockConfig 返回连接 mock 存根的基础配置，健康检查间隔足够长，不会干扰测试中的调用计数
+func mockConfig() Config {
+	return Config{
+		ServerAddr:        "passthrough:///mock",
+		KeepAliveInterval: time.Hour,
+		RequestInterval:   time.Hour,
+		RetryBaseDelay:    time.Millisecond,
+		RetryMaxDelay:     time.Millisecond,
+	}
+}
+
+// newMockClient 创建使用 greeter 的客户端，测试结束时自动关闭
+func newMockClient(t *testing.T, cfg Config, greeter *mocks.Greeter) *GRPCClient {
+	t.Helper()
+	if greeter.PingFunc == nil {
+		greeter.PingFunc = pingOK
+	}
+	c, err := newGRPCClientWithGreeter(cfg, greeter)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	t.Cleanup(c.Shutdown)
+	return c
+}
+
+// pingOK 总是成功的 Ping
+func pingOK(_ context.Context, in *pb.PingRequest) (*pb.PingReply, error) {
+	return &pb.PingReply{
+		ClientSendTimeUnixNano:    in.GetClientSendTimeUnixNano(),
+		Sequence:                  in.GetSequence(),
+		ServerReceiveTimeUnixNano: time.Now().UnixNano(),
+	}, nil
+}
+
+// slowSayHello 返回耗时 d 后成功的 SayHelloFunc，ctx 取消时提前返回
+func slowSayHello(d time.Duration) func(ctx context.Context, in *pb.HelloRequest) (*pb.HelloReply, error) {
+	return func(ctx context.Context, in *pb.HelloRequest) (*pb.HelloReply, error) {
+		select {
+		case <-time.After(d):
+			return &pb.HelloReply{Message: "Hello " + in.GetName() + "!"}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// waitFor 轮询 cond 直到返回 true，超时时使测试失败
+func waitFor(t *testing.T, timeout time.Duration, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待%s超时", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// runClient 在后台运行 c，返回 Run 的结果通道
+func runClient(c *GRPCClient) <-chan error {
+	done := make(chan error, 1)
+	go func() { done <- c.Run() }()
+	return done
+}
@@ -0,0 +1,128 @@
+// Package mocks 提供手写的 gRPC 存根 mock，用于在没有服务端的情况下测试客户端逻辑
+package mocks
+
+import (
+	"context"
+	pb "srpc/proto"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// Greeter pb.GreeterClient 的 mock，各方法的行为由对应的 *Func 字段决定，未设置时返回 Unimplemented
+// 字段应在开始使用前设置；调用次数可通过 Calls 并发安全地读取
+type Greeter struct {
+	SayHelloFunc      func(ctx context.Context, in *pb.HelloRequest) (*pb.HelloReply, error)
+	GetStreamFunc     func(ctx context.Context, in *pb.StreamReqData) (grpc.ServerStreamingClient[pb.StreamResData], error)
+	PutStreamFunc     func(ctx context.Context) (grpc.ClientStreamingClient[pb.StreamReqData, pb.StreamResData], error)
+	AllStreamFunc     func(ctx context.Context) (grpc.BidiStreamingClient[pb.StreamReqData, pb.StreamResData], error)
+	PingFunc          func(ctx context.Context, in *pb.PingRequest) (*pb.PingReply, error)
+	GetServerInfoFunc func(ctx context.Context, in *emptypb.Empty) (*pb.ServerInfo, error)
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+var _ pb.GreeterClient = (*Greeter)(nil)
+
+// Calls 返回指定方法（例如 "SayHello"）被调用的次数
+func (g *Greeter) Calls(method string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.calls[method]
+}
+
+// record 记录一次调用
+func (g *Greeter) record(method string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.calls == nil {
+		g.calls = make(map[string]int)
+	}
+	g.calls[method]++
+}
+
+// SayHello 实现 pb.GreeterClient
+func (g *Greeter) SayHello(ctx context.Context, in *pb.HelloRequest, _ ...grpc.CallOption) (*pb.HelloReply, error) {
+	g.record("SayHello")
+	if g.SayHelloFunc == nil {
+		return nil, unimplemented("SayHello")
+	}
+	return g.SayHelloFunc(ctx, in)
+}
+
+// GetStream 实现 pb.GreeterClient
+func (g *Greeter) GetStream(ctx context.Context, in *pb.StreamReqData, _ ...grpc.CallOption) (grpc.ServerStreamingClient[pb.StreamResData], error) {
+	g.record("GetStream")
+	if g.GetStreamFunc == nil {
+		return nil, unimplemented("GetStream")
+	}
+	return g.GetStreamFunc(ctx, in)
+}
+
+// PutStream 实现 pb.GreeterClient
+func (g *Greeter) PutStream(ctx context.Context, _ ...grpc.CallOption) (grpc.ClientStreamingClient[pb.StreamReqData, pb.StreamResData], error) {
+	g.record("PutStream")
+	if g.PutStreamFunc == nil {
+		return nil, unimplemented("PutStream")
+	}
+	return g.PutStreamFunc(ctx)
+}
+
+// AllStream 实现 pb.GreeterClient
+func (g *Greeter) AllStream(ctx context.Context, _ ...grpc.CallOption) (grpc.BidiStreamingClient[pb.StreamReqData, pb.StreamResData], error) {
+	g.record("AllStream")
+	if g.AllStreamFunc == nil {
+		return nil, unimplemented("AllStream")
+	}
+	return g.AllStreamFunc(ctx)
+}
+
+// Ping 实现 pb.GreeterClient
+func (g *Greeter) Ping(ctx context.Context, in *pb.PingRequest, _ ...grpc.CallOption) (*pb.PingReply, error) {
+	g.record("Ping")
+	if g.PingFunc == nil {
+		return nil, unimplemented("Ping")
+	}
+	return g.PingFunc(ctx, in)
+}
+
+// GetServerInfo 实现 pb.GreeterClient
+func (g *Greeter) GetServerInfo(ctx context.Context, in *emptypb.Empty, _ ...grpc.CallOption) (*pb.ServerInfo, error) {
+	g.record("GetServerInfo")
+	if g.GetServerInfoFunc == nil {
+		return nil, unimplemented("GetServerInfo")
+	}
+	return g.GetServerInfoFunc(ctx, in)
+}
+
+// SayHelloSequence 返回依次使用 errs 作为结果的 SayHelloFunc，nil 表示成功；序列用完后重复最后一项
+// 例如 SayHelloSequence(unavailable, unavailable, nil) 模拟前两次失败、第三次起成功
+func SayHelloSequence(errs ...error) func(ctx context.Context, in *pb.HelloRequest) (*pb.HelloReply, error) {
+	var (
+		mu   sync.Mutex
+		next int
+	)
+	return func(_ context.Context, in *pb.HelloRequest) (*pb.HelloReply, error) {
+		mu.Lock()
+		var err error
+		if len(errs) > 0 {
+			err = errs[min(next, len(errs)-1)]
+			next++
+		}
+		mu.Unlock()
+
+		if err != nil {
+			return nil, err
+		}
+		return &pb.HelloReply{Message: "Hello " + in.GetName() + "!"}, nil
+	}
+}
+
+// unimplemented 返回未设置行为的方法的错误
+func unimplemented(method string) error {
+	return status.Errorf(codes.Unimplemented, "mocks.Greeter: 未设置 %s 的行为", method)
+}
@@ -0,0 +1,26 @@
+package client
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// isMessageSizeError 判断错误是否为消息超过大小限制
+// gRPC 对发送和接收超限都返回 ResourceExhausted，错误信息形如 "... larger than max (X vs. Y)"
+func isMessageSizeError(err error) bool {
+	st, ok := status.FromError(err)
+	return ok && st.Code() == codes.ResourceExhausted && strings.Contains(st.Message(), "larger than max")
+}
+
+// logMessageSizeError 记录消息大小超限的详细信息，便于区分是本端还是对端的限制
+func (c *GRPCClient) logMessageSizeError(ctx context.Context, err error, requestSize int) {
+	c.slogger.ErrorContext(ctx, "消息大小超过限制", map[string]interface{}{
+		"request_size":      requestSize,
+		"max_send_msg_size": c.config.MaxSendMsgSize,
+		"max_recv_msg_size": c.config.MaxRecvMsgSize,
+		"error":             status.Convert(err).Message(),
+	})
+}
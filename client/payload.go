@@ -0,0 +1,41 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+)
+
+// defaultMaxLoggedPayloadSize 默认记录的载荷最大长度（字节）
+const defaultMaxLoggedPayloadSize = 256
+
+// logPayload 在启用 LogPayloads 时以调试级别记录请求和响应载荷
+// 字符串字段超过 MaxLoggedPayloadSize 时截断，避免大载荷刷屏
+func (c *GRPCClient) logPayload(ctx context.Context, operation string, payload map[string]interface{}) {
+	if !c.config.LogPayloads {
+		return
+	}
+
+	fields := make(map[string]interface{}, len(payload)+1)
+	fields["operation"] = operation
+	for k, v := range payload {
+		if str, ok := v.(string); ok {
+			v = truncatePayload(str, c.config.MaxLoggedPayloadSize)
+		}
+		fields[k] = v
+	}
+	c.slogger.DebugContext(ctx, "请求载荷", fields)
+}
+
+// truncatePayload 将载荷截断到 maxLen 字节，并注明原始长度
+func truncatePayload(payload string, maxLen int) string {
+	if len(payload) <= maxLen {
+		return payload
+	}
+	// 避免截断在多字节字符中间
+	cut := maxLen
+	for cut > 0 && !utf8.RuneStart(payload[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s...(已截断，共 %d 字节)", payload[:cut], len(payload))
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+)
+
+// poolConn 连接池中的单个连接，字段由 GRPCClient.mu 保护
+type poolConn struct {
+	index          int              // 连接在池中的序号
+	conn           *grpc.ClientConn // 底层 gRPC 连接
+	greeter        greeterClient    // Greeter 客户端存根
+	state          ConnectionState  // 连接状态
+	lastError      error            // 最后错误
+	reconnectCount int              // 重连次数
+	instanceLogged bool             // 本次连接后是否已记录服务端实例 ID，重连时清除
+	reconnecting   bool             // 是否有重连正在进行，保证同一连接同时只有一个重连
+}
+
+// connPool 连接池，按轮询方式为每次调用选择连接
+type connPool struct {
+	conns []*poolConn
+	next  atomic.Uint64
+}
+
+// newConnPool 创建指定大小的连接池，size 小于 1 时按 1 处理
+func newConnPool(size int) *connPool {
+	if size < 1 {
+		size = 1
+	}
+	conns := make([]*poolConn, size)
+	for i := range conns {
+		conns[i] = &poolConn{index: i, state: StateDisconnected}
+	}
+	return &connPool{conns: conns}
+}
+
+// size 返回连接池大小
+func (p *connPool) size() int {
+	return len(p.conns)
+}
+
+// pickConn 轮询选择下一个已连接的连接
+// 如果没有可用连接，返回轮询到的连接，由调用方根据其状态处理
+func (c *GRPCClient) pickConn() *poolConn {
+	p := c.pool
+	start := int(p.next.Add(1)-1) % p.size()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for i := 0; i < p.size(); i++ {
+		pc := p.conns[(start+i)%p.size()]
+		if pc.state == StateConnected {
+			return pc
+		}
+	}
+	return p.conns[start]
+}
+
+// refreshStateLocked 根据池中各连接的状态计算客户端整体连接状态，调用方需持有 c.mu
+// 任一连接可用即视为已连接；返回变更前后的状态，调用方应在释放锁后调用 notifyStateChange
+func (c *GRPCClient) refreshStateLocked() (old, current ConnectionState) {
+	old = c.connectionState
+	current = StateDisconnected
+	for _, pc := range c.pool.conns {
+		if pc.state == StateConnected {
+			current = StateConnected
+			break
+		}
+		switch pc.state {
+		case StateConnecting:
+			current = StateConnecting
+		case StateDegraded:
+			if current == StateDisconnected {
+				current = StateDegraded
+			}
+		}
+	}
+	c.connectionState = current
+	// 在锁内记录可用性，保证状态切换按发生顺序计入
+	if old != current {
+		switch {
+		case current == StateConnected:
+			c.metrics.RecordConnected()
+		case old == StateConnected:
+			c.metrics.RecordDisconnected()
+		}
+	}
+	return old, current
+}
+
+// notifyStateChange 在整体连接状态变化时调用 Config.OnStateChange
+// 必须在不持有 c.mu 的情况下调用，回调中可以安全地访问客户端
+func (c *GRPCClient) notifyStateChange(old, current ConnectionState) {
+	if old == current || c.config.OnStateChange == nil {
+		return
+	}
+	c.config.OnStateChange(old, current)
+}
@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// newRateLimiter 根据每秒请求数上限创建限流器，rps 小于等于 0 时返回 nil 表示不限流
+// 突发容量取每秒配额（至少为 1），允许短时间内用完一秒的配额
+func newRateLimiter(rps float64) *rate.Limiter {
+	if rps <= 0 {
+		return nil
+	}
+	burst := int(math.Ceil(rps))
+	if burst < 1 {
+		burst = 1
+	}
+	return rate.NewLimiter(rate.Limit(rps), burst)
+}
+
+// waitRateLimit 等待限流令牌，ctx 取消时放弃等待并归还预留的令牌
+func (c *GRPCClient) waitRateLimit(ctx context.Context) error {
+	if c.rateLimiter == nil {
+		return nil
+	}
+
+	r := c.rateLimiter.Reserve()
+	delay := r.Delay()
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		c.metrics.RecordRateLimitWait(delay)
+		return nil
+	case <-ctx.Done():
+		r.Cancel()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"fmt"
+	"time"
+)
+
+// RuntimeConfig 可以在运行时调整而无需重新连接的配置
+type RuntimeConfig struct {
+	RequestInterval       time.Duration // 请求间隔，必须大于 0
+	JitterPercent         int           // 请求间隔抖动百分比（0-100）
+	MaxRetries            int           // 最大重试次数，不能为负数
+	MaxConcurrentRequests int           // 并发请求总权重上限，只能在创建时已启用并发限制的客户端上调整
+}
+
+// runtimeConfig 返回当前生效的运行时配置
+func (c *GRPCClient) runtimeConfig() *RuntimeConfig {
+	return c.runtime.Load()
+}
+
+// RuntimeConfig 返回当前生效的运行时配置，MaxConcurrentRequests 反映 SetMaxConcurrency 调整后的上限
+func (c *GRPCClient) RuntimeConfig() RuntimeConfig {
+	rc := *c.runtimeConfig()
+	rc.MaxConcurrentRequests = 0
+	if c.semaphore != nil {
+		rc.MaxConcurrentRequests = c.semaphore.Capacity()
+	}
+	return rc
+}
+
+// UpdateRuntimeConfig 校验并应用运行时配置，不重新连接；主循环立即按新间隔重新计时，之后开始的请求按新的重试次数执行
+// 配置无效时返回错误且不做任何修改，生效的变更以一条日志记录
+func (c *GRPCClient) UpdateRuntimeConfig(rc RuntimeConfig) error {
+	if rc.RequestInterval <= 0 {
+		return fmt.Errorf("请求间隔必须大于 0: %s", rc.RequestInterval)
+	}
+	if rc.JitterPercent < 0 || rc.JitterPercent > 100 {
+		return fmt.Errorf("抖动百分比必须在 0-100 之间: %d", rc.JitterPercent)
+	}
+	if rc.MaxRetries < 0 {
+		return fmt.Errorf("最大重试次数不能为负数: %d", rc.MaxRetries)
+	}
+	if c.semaphore == nil && rc.MaxConcurrentRequests > 0 {
+		return ErrConcurrencyUnlimited
+	}
+	if c.semaphore != nil && rc.MaxConcurrentRequests < 1 {
+		return fmt.Errorf("并发上限必须大于 0: %d", rc.MaxConcurrentRequests)
+	}
+
+	c.runtimeMu.Lock()
+	defer c.runtimeMu.Unlock()
+
+	old := c.RuntimeConfig()
+	changes := make(map[string]interface{})
+	if old.RequestInterval != rc.RequestInterval {
+		changes["request_interval"] = fmt.Sprintf("%s -> %s", old.RequestInterval, rc.RequestInterval)
+	}
+	if old.JitterPercent != rc.JitterPercent {
+		changes["jitter_percent"] = fmt.Sprintf("%d -> %d", old.JitterPercent, rc.JitterPercent)
+	}
+	if old.MaxRetries != rc.MaxRetries {
+		changes["max_retries"] = fmt.Sprintf("%d -> %d", old.MaxRetries, rc.MaxRetries)
+	}
+	if old.MaxConcurrentRequests != rc.MaxConcurrentRequests {
+		changes["max_concurrent_requests"] = fmt.Sprintf("%d -> %d", old.MaxConcurrentRequests, rc.MaxConcurrentRequests)
+	}
+	if len(changes) == 0 {
+		c.slogger.Info("运行时配置未变化")
+		return nil
+	}
+
+	next := rc
+	c.runtime.Store(&next)
+	if c.semaphore != nil {
+		c.semaphore.Resize(rc.MaxConcurrentRequests)
+	}
+	if old.RequestInterval != rc.RequestInterval || old.JitterPercent != rc.JitterPercent {
+		// 主循环可能正在等待按旧间隔计算的定时器，通知其按新间隔重新计时
+		select {
+		case c.intervalChanged <- struct{}{}:
+		default:
+		}
+	}
+	c.slogger.Info("运行时配置已更新", changes)
+	return nil
+}
+
+// reloadConfig 处理 SIGHUP：通过 Config.OnReload 获取新的运行时配置并应用，失败时保留原配置
+func (c *GRPCClient) reloadConfig() {
+	if c.config.OnReload == nil {
+		c.slogger.Warn("收到 SIGHUP，但未配置 OnReload，忽略")
+		return
+	}
+	rc, err := c.config.OnReload(c.RuntimeConfig())
+	if err == nil {
+		err = c.UpdateRuntimeConfig(rc)
+	}
+	if err != nil {
+		c.slogger.Error("重新加载配置失败，保留原配置", map[string]interface{}{"error": err})
+	}
+}
@@ -0,0 +1,30 @@
+package client
+
+// startMetricsReporter 按 MetricsLogInterval 定期记录指标快照，未配置时不启动
+func (c *GRPCClient) startMetricsReporter() {
+	if c.config.MetricsLogInterval <= 0 {
+		return
+	}
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		ticker := c.clock.NewTicker(c.config.MetricsLogInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-ticker.C():
+				c.logMetrics("指标快照")
+			}
+		}
+	}()
+}
+
+// logMetrics 以一条结构化日志记录当前指标快照
+func (c *GRPCClient) logMetrics(msg string) {
+	c.slogger.Info(msg, c.metrics.GetMetrics().Map())
+}
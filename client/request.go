@@ -2,46 +2,100 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"math/rand"
+	"math/rand/v2"
+	"srpc/pkg/log"
+	"srpc/pkg/stats"
 	pb "srpc/proto"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
+// tracer 客户端逻辑请求使用的 tracer
+var tracer = otel.Tracer("srpc/client")
+
+// ErrClientShutting 客户端正在关闭，拒绝新请求
+var ErrClientShutting = errors.New("客户端正在关闭")
+
+// ErrNotConnected 没有可用的连接
+var ErrNotConnected = errors.New("连接不可用")
+
 // mainLoop 主循环
 func (c *GRPCClient) mainLoop() {
 	defer c.wg.Done()
 
+	pool := c.newDispatcher()
+	if pool != nil {
+		c.metrics.setDispatchStats(pool.Stats)
+		defer c.metrics.setDispatchStats(nil)
+		c.mu.Lock()
+		c.dispatcher = pool
+		c.mu.Unlock()
+		defer func() {
+			c.mu.Lock()
+			c.dispatcher = nil
+			c.mu.Unlock()
+		}()
+	}
+
 	for {
 		waitInterval := c.calculateJitteredInterval()
 
 		select {
-		case <-c.ctx.Done():
+		case <-c.stopCtx.Done():
+			// 关闭的第一阶段即取消 stopCtx，早于取消 ctx，使主循环不再发起新请求
 			c.slogger.Info("主循环收到关闭信号，正在退出")
+			if pool != nil {
+				// cancel 策略下排队中的请求直接丢弃，drain 策略下由 shutdown 在排空时限内等待其执行完
+				// 排空结束（ctx 取消）时 Shutdown 丢弃仍在排队的请求；正在执行的请求与其他进行中的请求一样参与排空
+				if c.config.DispatchShutdownPolicy != DispatchShutdownDrain {
+					pool.Close()
+				}
+				pool.Shutdown(c.ctx)
+				pool.Wait()
+			}
 			return
-		case <-time.After(waitInterval):
-			c.makeRequest()
+		case <-c.intervalChanged:
+			// 请求间隔已调整，按新间隔重新计时
+		case <-c.clock.After(waitInterval):
+			c.dispatch(pool)
 		}
 	}
 }
 
 // calculateJitteredInterval 计算带抖动的间隔时间
 func (c *GRPCClient) calculateJitteredInterval() time.Duration {
-	if c.config.JitterPercent <= 0 {
-		return c.config.RequestInterval
+	// 启用自适应间隔时，以当前倍数放大后的间隔作为抖动的基准
+	rc := c.runtimeConfig()
+	base := rc.RequestInterval
+	if c.adaptive != nil {
+		base = time.Duration(float64(base) * c.adaptive.current())
+	}
+
+	if rc.JitterPercent <= 0 {
+		return base
 	}
 
 	// 计算抖动的范围
-	jitterRange := float64(c.config.JitterPercent) / 100.0 * float64(c.config.RequestInterval)
+	jitterRange := float64(rc.JitterPercent) / 100.0 * float64(base)
 
 	// 生成随机抖动值（-jitterRange/2 到 +jitterRange/2）
-	rand.Seed(time.Now().UnixNano())
-	jitter := rand.Float64()*jitterRange - jitterRange/2
+	c.jitterMu.Lock()
+	jitter := c.jitterRand.Float64()*jitterRange - jitterRange/2
+	c.jitterMu.Unlock()
 
 	// 计算最终间隔
-	interval := float64(c.config.RequestInterval) + jitter
+	interval := float64(base) + jitter
 
 	// 确保间隔不小于 1 毫秒
 	if interval < float64(time.Millisecond) {
@@ -51,14 +105,25 @@ func (c *GRPCClient) calculateJitteredInterval() time.Duration {
 	return time.Duration(interval)
 }
 
+// newJitterRand 创建抖动使用的随机数源，seed 为 0 时使用随机种子
+func newJitterRand(seed uint64) *rand.Rand {
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	return rand.New(rand.NewPCG(seed, seed))
+}
+
 // makeRequest 发起 gRPC 请求
 func (c *GRPCClient) makeRequest() {
+	// 从连接池中轮询选择本次调用使用的连接
+	pc := c.pickConn()
+
 	c.mu.RLock()
-	isShutting := c.isShutting
-	state := c.connectionState
+	state := pc.state
 	c.mu.RUnlock()
 
-	if isShutting {
+	// 关闭的排空阶段仍执行已排队的请求（drain 策略），排空结束后放弃
+	if c.ctx.Err() != nil {
 		return
 	}
 
@@ -66,9 +131,27 @@ func (c *GRPCClient) makeRequest() {
 	if !c.circuitBreaker.AllowRequest() {
 		cbState := c.circuitBreaker.GetState()
 		c.slogger.Info("熔断器状态，跳过本次请求", map[string]interface{}{"circuit_breaker_state": cbState})
+		c.recordAdaptiveResult(ErrCircuitOpen)
 		return
 	}
 
+	// 等待限流令牌，客户端关闭时放弃本次请求
+	if err := c.waitRateLimit(c.ctx); err != nil {
+		return
+	}
+
+	// 启用 WaitForReady 时，短暂断连期间的请求交给 gRPC 等待连接就绪，而不是直接丢弃
+	if c.config.WaitForReady && (state == StateDisconnected || state == StateConnecting) {
+		c.mu.RLock()
+		hasConn := pc.greeter != nil
+		c.mu.RUnlock()
+		if hasConn {
+			c.slogger.Info("连接未就绪，等待连接就绪后发送请求", map[string]interface{}{"state": state})
+			c.executeSayHello(pc)
+			return
+		}
+	}
+
 	// 检查连接状态
 	switch state {
 	case StateDisconnected:
@@ -78,66 +161,299 @@ func (c *GRPCClient) makeRequest() {
 		c.slogger.Info("正在连接中，跳过本次请求")
 		return
 	case StateDegraded:
-		c.slogger.Info("连接降级，尝试恢复")
-		c.reconnect()
+		// 降级连接由健康检查负责恢复，这里重连会与健康检查重复拨号
+		c.slogger.Info("连接降级，等待健康检查恢复，跳过本次请求")
 		return
 	case StateConnected:
 		// 连接正常，执行请求
-		c.executeSayHello()
+		c.executeSayHello(pc)
 	default:
 		c.slogger.Warn("未知连接状态", map[string]interface{}{"state": state})
 	}
 }
 
-// executeSayHello 执行 SayHello RPC 调用
-func (c *GRPCClient) executeSayHello() {
-	ctx, cancel := context.WithTimeout(c.ctx, 5*time.Second)
+// executeSayHello 使用指定连接执行后台循环的 SayHello RPC 调用
+func (c *GRPCClient) executeSayHello(pc *poolConn) {
+	release, err := c.acquireSlot(c.ctx)
+	if err != nil {
+		return
+	}
+	defer release()
+
+	_, err = c.sayHello(c.ctx, pc, fmt.Sprintf("Client-%d", time.Now().Unix()))
+	if c.ctx.Err() == nil {
+		c.recordAdaptiveResult(err)
+	}
+}
+
+// acquireSlot 在配置了并发上限时获取一元请求的许可，返回的 release 必须在请求结束后调用
+func (c *GRPCClient) acquireSlot(ctx context.Context) (release func(), err error) {
+	return c.acquireWeighted(ctx, 1)
+}
+
+// acquireStreamSlot 按 StreamRequestWeight 获取流调用的许可
+func (c *GRPCClient) acquireStreamSlot(ctx context.Context) (release func(), err error) {
+	return c.acquireWeighted(ctx, c.config.StreamRequestWeight)
+}
+
+// acquireWeighted 获取 weight 个许可，权重超过信号量容量时按容量计算，避免永远无法获取
+func (c *GRPCClient) acquireWeighted(ctx context.Context, weight int) (release func(), err error) {
+	if c.semaphore == nil {
+		return func() {}, nil
+	}
+	weight = min(max(weight, 1), c.semaphore.Capacity())
+	if !c.semaphore.AcquireN(ctx, weight) {
+		return nil, ctx.Err()
+	}
+	return func() { c.semaphore.ReleaseN(weight) }, nil
+}
+
+// ErrConcurrencyUnlimited 创建客户端时未设置 MaxConcurrentRequests，无法在运行时调整并发上限
+var ErrConcurrencyUnlimited = errors.New("未启用并发限制，需在创建客户端时设置 MaxConcurrentRequests")
+
+// SetMaxConcurrency 在运行时调整并发请求的总权重上限，无需重启客户端，例如服务端降级时临时收紧
+// 缩容不会中断进行中的请求，新请求需等待占用回落到新上限以下；n 小于 1 时返回错误
+func (c *GRPCClient) SetMaxConcurrency(n int) error {
+	if c.semaphore == nil {
+		return ErrConcurrencyUnlimited
+	}
+	if n < 1 {
+		return fmt.Errorf("并发上限必须大于 0: %d", n)
+	}
+	old := c.semaphore.Resize(n)
+	if old != n {
+		c.slogger.Info("并发上限已调整", map[string]interface{}{
+			"old":    old,
+			"new":    n,
+			"in_use": c.semaphore.InUse(),
+		})
+	}
+	return nil
+}
+
+// SendNow 立即发送一次 SayHello 请求并返回响应，不依赖后台请求循环
+// 请求同样经过限流、熔断器、重试、指标和请求 ID 逻辑；opts 追加在默认调用选项之后，可覆盖压缩等设置
+// RPC 失败时返回的错误按类别包装为 RPCError，可通过 errors.Is(err, ErrTimeout) 等判断，RPCError.Attempts 为实际尝试次数
+// 启用响应缓存时，命中缓存直接返回而不发起请求；传入 NoCache() 可跳过缓存
+// 超时取调用方 ctx 截止时间与 OverallTimeout 中较早者，不会延长调用方设定的截止时间
+func (c *GRPCClient) SendNow(ctx context.Context, name string, opts ...grpc.CallOption) (*pb.HelloReply, error) {
+	if c.IsShutting() {
+		return nil, ErrClientShutting
+	}
+
+	useCache := c.cache != nil && !hasNoCache(opts)
+	if useCache {
+		if reply, ok := c.cache.get(name); ok {
+			c.metrics.RecordCacheHit()
+			return reply, nil
+		}
+		c.metrics.RecordCacheMiss()
+	}
+
+	if !c.circuitBreaker.AllowRequest() {
+		return nil, ErrCircuitOpen
+	}
+	if err := c.waitRateLimit(ctx); err != nil {
+		return nil, err
+	}
+
+	release, err := c.acquireSlot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	pc, _, err := c.readyConn()
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := c.sayHello(ctx, pc, name, opts...)
+	if err == nil && useCache {
+		c.cache.put(name, reply)
+	}
+	return reply, err
+}
+
+// readyConn 从连接池中选择一个可用于按需调用的连接
+// 启用 WaitForReady 时允许在短暂断连期间发送，由 gRPC 等待连接就绪
+func (c *GRPCClient) readyConn() (*poolConn, greeterClient, error) {
+	pc := c.pickConn()
+	c.mu.RLock()
+	state := pc.state
+	greeter := pc.greeter
+	c.mu.RUnlock()
+
+	if greeter == nil || (state != StateConnected && !c.config.WaitForReady) {
+		return nil, nil, fmt.Errorf("%w: %s", ErrNotConnected, state)
+	}
+	return pc, greeter, nil
+}
+
+// withRequestID 生成请求 ID（如果启用），同时写入 outgoing metadata 和日志上下文
+func (c *GRPCClient) withRequestID(ctx context.Context) context.Context {
+	if !c.config.GenerateRequestID || c.idGenerator == nil {
+		return ctx
+	}
+	requestID := c.idGenerator.Generate()
+	// 将请求 ID 添加到 context metadata 中，以便服务端追踪
+	ctx = metadata.AppendToOutgoingContext(ctx, log.RequestIDHeader, requestID)
+	// 同时写入日志上下文，使本次请求的所有日志自动携带请求 ID
+	return log.WithRequestID(ctx, requestID)
+}
+
+// recordServerDuration 从 trailer 中读取服务端处理耗时，记录指标并写入日志字段
+// 服务端未返回耗时（例如旧版本服务端）时只按端到端耗时判断慢请求；慢请求以一条警告日志记录网络开销
+func (c *GRPCClient) recordServerDuration(ctx context.Context, elapsed time.Duration, trailer metadata.MD, fields map[string]interface{}) {
+	serverDuration, ok := stats.ServerDuration(trailer)
+	if ok {
+		// 客户端和服务端计时存在误差，网络开销不小于 0
+		overhead := max(elapsed-serverDuration, 0)
+		c.metrics.RecordServerDuration(serverDuration, overhead)
+		fields["server_duration"] = serverDuration.String()
+		fields["network_overhead"] = overhead.String()
+	}
+
+	if threshold := c.config.SlowRequestThreshold; threshold > 0 && elapsed >= threshold {
+		slow := map[string]interface{}{
+			"duration":  elapsed.String(),
+			"threshold": threshold.String(),
+		}
+		if ok {
+			slow["server_duration"] = fields["server_duration"]
+			slow["network_overhead"] = fields["network_overhead"]
+		}
+		c.slogger.WarnContext(ctx, "慢请求", slow)
+	}
+}
+
+// recordServerInstance 按响应 header 中的服务端实例 ID 计数并写入日志字段，旧版本服务端不返回时不记录
+// 连接建立或重连后第一次看到实例 ID 时单独记录一条日志，便于确认重连后由哪个副本提供服务
+func (c *GRPCClient) recordServerInstance(ctx context.Context, pc *poolConn, header metadata.MD, fields map[string]interface{}) {
+	instance := stats.ServerInstance(header)
+	if instance == "" {
+		return
+	}
+	c.metrics.RecordInstance(instance)
+	fields["server_instance"] = instance
+
+	c.mu.RLock()
+	logged := pc.instanceLogged
+	c.mu.RUnlock()
+	if logged {
+		return
+	}
+	c.mu.Lock()
+	logged, pc.instanceLogged = pc.instanceLogged, true
+	c.mu.Unlock()
+	if !logged {
+		c.slogger.InfoContext(ctx, "连接后首个响应来自服务端实例", map[string]interface{}{
+			"conn_index":      pc.index,
+			"server_instance": instance,
+		})
+	}
+}
+
+// sayHello 使用指定连接执行带重试的 SayHello RPC 调用
+// parent 为调用方的 context，客户端关闭时同样会取消本次请求
+func (c *GRPCClient) sayHello(parent context.Context, pc *poolConn, name string, opts ...grpc.CallOption) (*pb.HelloReply, error) {
+	c.inflight.begin()
+	defer c.inflight.end()
+
+	// 整体超时覆盖所有重试尝试，单次尝试超时由 executeWithRetry 派生
+	ctx, cancel := context.WithTimeout(parent, c.config.OverallTimeout)
 	defer cancel()
+	stop := context.AfterFunc(c.runContext(), cancel)
+	defer stop()
+
+	ctx = c.withRequestID(ctx)
+	// 所有重试复用同一幂等键，服务端在去重窗口内只处理一次
+	if c.config.IdempotencyKeys {
+		ctx = metadata.AppendToOutgoingContext(ctx, log.IdempotencyKeyHeader, log.RequestIDFromContext(ctx))
+	}
 
-	// 生成请求 ID（如果启用）
-	var requestID string
-	if c.config.GenerateRequestID && c.idGenerator != nil {
-		requestID = c.idGenerator.Generate()
-		// 将请求 ID 添加到 context metadata 中，以便服务端追踪
-		ctx = metadata.AppendToOutgoingContext(ctx, "x-request-id", requestID)
+	// 创建逻辑请求 span，每次重试的 RPC span 作为其子 span
+	// 未启用追踪时全局 TracerProvider 为空实现，开销可以忽略
+	ctx, span := tracer.Start(ctx, "SayHello", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+	span.SetAttributes(attribute.String("circuit_breaker.state", c.circuitBreaker.GetState().String()))
+	if requestID := log.RequestIDFromContext(ctx); requestID != "" {
+		span.SetAttributes(attribute.String("request_id", requestID))
 	}
 
 	// 创建请求
 	req := &pb.HelloRequest{
-		Name: fmt.Sprintf("Client-%d", time.Now().Unix()),
+		Name: name,
 	}
+	var reply *pb.HelloReply
 
 	// 执行带重试的请求
-	c.executeWithRetry(func() error {
+	attempts := 0
+	defer func() {
+		span.SetAttributes(attribute.Int("retry.attempts", attempts))
+	}()
+	err := c.executeWithRetry(ctx, func(ctx context.Context) error {
+		attempts++
+		span.AddEvent("attempt", trace.WithAttributes(
+			attribute.Int("retry.attempt", attempts),
+			attribute.String("circuit_breaker.state", c.circuitBreaker.GetState().String()),
+		))
+		// 每次尝试重新读取存根，重试等待期间连接可能已被健康检查重建
+		c.mu.RLock()
+		greeter := pc.greeter
+		c.mu.RUnlock()
+		if greeter == nil {
+			return status.Errorf(codes.Unavailable, "%v: 连接 %d 尚未建立", ErrNotConnected, pc.index)
+		}
 		start := time.Now()
-		resp, err := c.greeter.SayHello(ctx, req)
+		resp, md, err := c.hedgedSayHello(ctx, greeter, req, opts...)
 		elapsed := time.Since(start)
 
 		// 构建日志字段
 		logFields := map[string]interface{}{
-			"duration":  elapsed.String(),
-			"operation": "SayHello",
-		}
-		if requestID != "" {
-			logFields["request_id"] = requestID
+			"duration":   elapsed.String(),
+			"operation":  "SayHello",
+			"conn_index": pc.index,
 		}
+		c.recordServerDuration(ctx, elapsed, md.trailer, logFields)
+		c.recordServerInstance(ctx, pc, md.header, logFields)
 
 		if err != nil {
 			logFields["error"] = err.Error()
-			c.slogger.Error("SayHello请求失败", logFields)
+			if isMessageSizeError(err) {
+				c.logMessageSizeError(ctx, err, proto.Size(req))
+			}
+			c.slogger.ErrorContext(ctx, "SayHello请求失败", logFields)
+			span.SetStatus(otelcodes.Error, err.Error())
+			// 先记录指标，按错误率触发熔断时计入本次失败
+			c.metrics.RecordRequest(err, elapsed)
 			// 记录熔断器失败
 			c.circuitBreaker.RecordFailure()
-			// 记录指标
-			c.metrics.RecordRequest(false, elapsed)
+			c.metrics.RecordConnRequest(pc.index, false)
 			return err
 		}
 
-		logFields["response"] = resp.GetMessage()
-		c.slogger.Info("SayHello请求成功", logFields)
+		c.slogger.InfoContext(ctx, "SayHello请求成功", logFields)
+		c.logPayload(ctx, "SayHello", map[string]interface{}{
+			"request_name":     req.GetName(),
+			"response_message": resp.GetMessage(),
+		})
+		span.SetStatus(otelcodes.Ok, "")
 		// 记录熔断器成功
 		c.circuitBreaker.RecordSuccess()
 		// 记录指标
-		c.metrics.RecordRequest(true, elapsed)
+		c.metrics.RecordRequest(nil, elapsed)
+		c.metrics.RecordConnRequest(pc.index, true)
+		reply = resp
 		return nil
 	})
+	if err != nil {
+		err = classifyError(err)
+		var rpcErr *RPCError
+		if errors.As(err, &rpcErr) {
+			rpcErr.Attempts = attempts
+		}
+		return nil, err
+	}
+	return reply, nil
 }
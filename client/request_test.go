@@ -0,0 +1,140 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"srpc/client/mocks"
+	pb "srpc/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSendNowHonorsCallerDeadline(t *testing.T) {
+	// 记录服务端看到的截止时间，验证调用方的截止时间传递到了 RPC
+	deadlines := make(chan time.Time, 1)
+	greeter := &mocks.Greeter{SayHelloFunc: func(ctx context.Context, in *pb.HelloRequest) (*pb.HelloReply, error) {
+		deadline, _ := ctx.Deadline()
+		deadlines <- deadline
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}}
+	cfg := mockConfig()
+	cfg.MaxRetries = 3
+	c := newMockClient(t, cfg, greeter)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	want, _ := ctx.Deadline()
+
+	start := time.Now()
+	_, err := c.SendNow(ctx, "deadline")
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("错误 = %v，期望 ErrTimeout 类别", err)
+	}
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != codes.DeadlineExceeded {
+		t.Errorf("错误 = %#v，期望 Code 为 DeadlineExceeded", err)
+	}
+	// 单次超时和整体超时都远大于 1 秒，应由调用方的截止时间结束，且不再重试
+	if elapsed < time.Second || elapsed > 2*time.Second {
+		t.Errorf("耗时 %v，期望约 1 秒", elapsed)
+	}
+	if got := greeter.Calls("SayHello"); got != 1 {
+		t.Errorf("SayHello 调用 %d 次，调用方截止后不应重试", got)
+	}
+	if got := <-deadlines; got.After(want) {
+		t.Errorf("RPC 截止时间 %v 晚于调用方的 %v", got, want)
+	}
+}
+
+func TestSendNowExpiredContext(t *testing.T) {
+	greeter := &mocks.Greeter{SayHelloFunc: slowSayHello(time.Minute)}
+	c := newMockClient(t, mockConfig(), greeter)
+
+	ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+	if _, err := c.SendNow(ctx, "expired"); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("错误 = %v，期望 ErrTimeout 类别", err)
+	}
+}
+
+func TestPerAttemptTimeoutRetriesWithFreshContext(t *testing.T) {
+	// 第一次尝试阻塞到单次超时，第二次尝试检查拿到的是未过期的新 context 并立即成功
+	var attempt atomic.Int64
+	remaining := make(chan time.Duration, 1)
+	greeter := &mocks.Greeter{SayHelloFunc: func(ctx context.Context, in *pb.HelloRequest) (*pb.HelloReply, error) {
+		if attempt.Add(1) == 1 {
+			<-ctx.Done()
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, status.FromContextError(err).Err()
+		}
+		deadline, _ := ctx.Deadline()
+		remaining <- time.Until(deadline)
+		return &pb.HelloReply{Message: "Hello " + in.GetName() + "!"}, nil
+	}}
+	cfg := mockConfig()
+	cfg.MaxRetries = 3
+	cfg.PerAttemptTimeout = 100 * time.Millisecond
+	c := newMockClient(t, cfg, greeter)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	_, err := c.SendNow(ctx, "fresh")
+	elapsed := time.Since(start)
+
+	if err != nil {
+		t.Fatalf("第二次尝试应成功: %v", err)
+	}
+	if got := greeter.Calls("SayHello"); got != 2 {
+		t.Errorf("SayHello 调用 %d 次，期望 2 次", got)
+	}
+	// 第二次尝试的截止时间按单次超时重新计算，而不是沿用第一次已过期的 context
+	if got := <-remaining; got <= cfg.PerAttemptTimeout/2 || got > cfg.PerAttemptTimeout {
+		t.Errorf("第二次尝试剩余时间 %v，期望接近单次超时 %v", got, cfg.PerAttemptTimeout)
+	}
+	if elapsed < cfg.PerAttemptTimeout || elapsed > 300*time.Millisecond {
+		t.Errorf("耗时 %v，期望在单次超时 %v 后由第二次尝试很快返回", elapsed, cfg.PerAttemptTimeout)
+	}
+
+	m := c.GetMetrics()
+	if m.TotalRetries != 1 || m.ErrorsByCode["DeadlineExceeded"] != 1 || m.ErrorsRetriedAway != 1 {
+		t.Errorf("重试 %d 次、DeadlineExceeded %d 次、重试挽回 %d 次，期望均为 1",
+			m.TotalRetries, m.ErrorsByCode["DeadlineExceeded"], m.ErrorsRetriedAway)
+	}
+}
+
+func TestRequestIDFormats(t *testing.T) {
+	for _, format := range []string{"", RequestIDFormatSimple, RequestIDFormatSimple62, RequestIDFormatUUID,
+		RequestIDFormatUUIDv7, RequestIDFormatULID, RequestIDFormatShort} {
+		cfg := mockConfig()
+		cfg.GenerateRequestID = true
+		cfg.RequestIDFormat = format
+		c, err := newClient(cfg)
+		if err != nil {
+			t.Errorf("格式 %q 创建客户端失败: %v", format, err)
+			continue
+		}
+		c.Shutdown()
+	}
+}
+
+func TestInvalidRequestIDFormat(t *testing.T) {
+	cfg := mockConfig()
+	cfg.GenerateRequestID = true
+	cfg.RequestIDFormat = "snowflake"
+	_, err := newClient(cfg)
+	if err == nil || !strings.Contains(err.Error(), "snowflake") {
+		t.Errorf("错误 = %v，期望指出不支持的格式 snowflake", err)
+	}
+}
@@ -1,57 +1,151 @@
 package client
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"srpc/pkg/tools"
 	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-// executeWithRetry 执行带重试的操作
-func (c *GRPCClient) executeWithRetry(operation func() error) {
+// executeWithRetry 执行带重试的操作，返回最终错误
+// ctx 承载整体超时和关闭信号，并用于日志关联请求 ID；每次尝试都会派生独立的单次超时 context
+// 每次重试前依次检查熔断器和全局重试预算，熔断器开启优先于预算判断
+// 返回前记录最终结果：失败时的最终状态码，或成功前被重试消化的失败次数
+func (c *GRPCClient) executeWithRetry(ctx context.Context, operation func(ctx context.Context) error) (finalErr error) {
 	var lastErr error
+	failedAttempts := 0
+	defer func() {
+		c.metrics.RecordRetryOutcome(finalErr, failedAttempts)
+	}()
+	backoff := tools.NewBackoff(c.config.RetryBaseDelay, c.config.RetryMaxDelay, c.config.RetryMultiplier)
+	backoff.Clock = c.clock
+	// 整个请求使用同一个重试上限，期间重新加载配置不影响已开始的请求
+	maxRetries := c.runtimeConfig().MaxRetries
 
-	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
-		if c.IsShutting() {
-			c.slogger.Info("客户端正在关闭，取消重试")
-			return
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		// 关闭期间已开始的请求仍完成本次尝试并参与排空，但不再重试
+		if attempt > 0 && c.IsShutting() {
+			c.slogger.InfoContext(ctx, "客户端正在关闭，取消重试")
+			return lastErr
 		}
 
 		// 如果不是第一次尝试，等待重试延迟
 		if attempt > 0 {
-			// 指数退避: 1,4,9 秒，最大 10 秒
-			backoff := min(time.Duration(attempt*attempt)*time.Second, 10*time.Second)
-			c.slogger.Info("重试等待", map[string]interface{}{"attempt": attempt, "backoff": backoff})
-			time.Sleep(backoff)
+			// 熔断器开启时不再重试
+			if !c.circuitBreaker.AllowRequest() {
+				c.slogger.WarnContext(ctx, "熔断器已开启，停止重试", map[string]interface{}{"circuit_breaker_state": c.circuitBreaker.GetState()})
+				return fmt.Errorf("%w: %w", ErrCircuitOpen, lastErr)
+			}
+
+			// 重试预算不足时快速失败
+			if !c.retryBudget.withdraw() {
+				c.metrics.RecordRetrySuppressed()
+				c.slogger.WarnContext(ctx, "重试预算已耗尽，放弃重试", map[string]interface{}{"attempt": attempt, "error": lastErr})
+				return fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, lastErr)
+			}
+			c.metrics.RecordRetryBudgetWithdrawal()
+
+			// 服务端通过 RetryInfo 给出建议的等待时间时优先采用，否则使用指数退避并加入全抖动，避免大量客户端同步重试
+			wait, fromServer := retryDelayFromError(lastErr)
+			if !fromServer {
+				wait = backoff.Next()
+			}
+			c.slogger.InfoContext(ctx, "重试等待", map[string]interface{}{"attempt": attempt, "backoff": wait, "server_suggested": fromServer})
+			select {
+			case <-c.clock.After(wait):
+			case <-ctx.Done():
+				c.logBudgetExhausted(ctx, lastErr)
+				return lastErr
+			}
+			c.metrics.RecordRetry()
 		}
 
-		err := operation()
+		attemptCtx, cancel := context.WithTimeout(ctx, c.config.PerAttemptTimeout)
+		err := operation(attemptCtx)
+		cancel()
 		if err == nil {
-			return
+			c.retryBudget.deposit()
+			return nil
 		}
 
 		lastErr = err
+		failedAttempts++
+
+		// 整体超时或客户端关闭后不再重试
+		if ctx.Err() != nil {
+			c.logBudgetExhausted(ctx, err)
+			return err
+		}
+
+		// 单次尝试超时但整体预算仍有剩余，可以继续重试
+		if isDeadlineError(err) {
+			c.slogger.WarnContext(ctx, "单次尝试超时", map[string]interface{}{"per_attempt_timeout": c.config.PerAttemptTimeout, "current_attempt": attempt + 1})
+		}
 
 		// 检查是否是致命错误（无需重试）
 		if isFatalError(err) {
-			c.slogger.Error("遇到致命错误，停止重试", map[string]interface{}{"error": err})
+			c.slogger.ErrorContext(ctx, "遇到致命错误，停止重试", map[string]interface{}{"error": err})
 			break
 		}
 
 		// 如果是最后一次尝试，退出循环
-		if attempt == c.config.MaxRetries {
-			c.slogger.Error("达到最大重试次数，最终失败", map[string]interface{}{"max_retries": c.config.MaxRetries, "error": err})
+		if attempt == maxRetries {
+			c.slogger.ErrorContext(ctx, "达到最大重试次数，最终失败", map[string]interface{}{"max_retries": maxRetries, "error": err})
 			break
 		}
 
-		c.slogger.Warn("请求失败，准备重试", map[string]interface{}{"current_attempt": attempt + 1, "total_attempts": c.config.MaxRetries + 1, "error": err})
+		c.slogger.WarnContext(ctx, "请求失败，准备重试", map[string]interface{}{"current_attempt": attempt + 1, "total_attempts": maxRetries + 1, "error": err})
 	}
 
 	if lastErr != nil {
-		c.slogger.Error("所有重试尝试均失败", map[string]interface{}{"error": lastErr})
+		c.slogger.ErrorContext(ctx, "所有重试尝试均失败", map[string]interface{}{"error": lastErr})
+	}
+	return lastErr
+}
+
+// logBudgetExhausted 记录整体超时预算耗尽或客户端关闭导致的终止
+func (c *GRPCClient) logBudgetExhausted(ctx context.Context, lastErr error) {
+	fields := map[string]interface{}{"error": lastErr}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		fields["overall_timeout"] = c.config.OverallTimeout
+		c.slogger.ErrorContext(ctx, "整体超时预算已耗尽，停止重试", fields)
+		return
 	}
+	c.slogger.InfoContext(ctx, "请求已取消，停止重试", fields)
+}
+
+// retryDelayFromError 从 gRPC 状态详情中提取服务端建议的重试等待时间（errdetails.RetryInfo）
+func retryDelayFromError(err error) (time.Duration, bool) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return 0, false
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok && info.GetRetryDelay() != nil {
+			return info.GetRetryDelay().AsDuration(), true
+		}
+	}
+	return 0, false
+}
+
+// isDeadlineError 判断错误是否为超时
+func isDeadlineError(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || status.Code(err) == codes.DeadlineExceeded
 }
 
 // isFatalError 检查是否为致命错误（无需重试）
+// 这些错误码表示请求本身有问题，重试只会得到相同的结果
 func isFatalError(err error) bool {
-	// TODO: 可以根据具体的gRPC错误码来判断
-	// 例如：无效参数、权限拒绝等错误无需重试
-	return false
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.PermissionDenied,
+		codes.Unauthenticated, codes.FailedPrecondition, codes.OutOfRange, codes.Unimplemented:
+		return true
+	default:
+		return false
+	}
 }
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"srpc/client/mocks"
+	"srpc/pkg/tools"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// recordingClock 记录重试退避的等待时长并立即触发，不足一分钟的等待都视为退避，更长的等待（健康检查、请求循环）使用真实时钟
+type recordingClock struct {
+	tools.RealClock
+	mu    sync.Mutex
+	waits []time.Duration
+}
+
+func (c *recordingClock) After(d time.Duration) <-chan time.Time {
+	if d >= time.Minute {
+		return c.RealClock.After(d)
+	}
+	c.mu.Lock()
+	c.waits = append(c.waits, d)
+	c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	ch <- time.Now()
+	return ch
+}
+
+// takeWaits 返回并清空已记录的等待时长
+func (c *recordingClock) takeWaits() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	waits := c.waits
+	c.waits = nil
+	return waits
+}
+
+// failUnavailable 总是返回 Unavailable 的操作
+func failUnavailable(context.Context) error {
+	return status.Error(codes.Unavailable, "服务不可用")
+}
+
+func TestRetryBackoffWithinExponentialCeilings(t *testing.T) {
+	clock := &recordingClock{}
+	cfg := mockConfig()
+	cfg.Clock = clock
+	cfg.MaxRetries = 4
+	cfg.RetryBaseDelay = 10 * time.Millisecond
+	cfg.RetryMaxDelay = 40 * time.Millisecond
+	cfg.RetryMultiplier = 2
+	c := newMockClient(t, cfg, &mocks.Greeter{})
+
+	if err := c.executeWithRetry(context.Background(), failUnavailable); status.Code(err) != codes.Unavailable {
+		t.Fatalf("executeWithRetry 错误 = %v，期望 Unavailable", err)
+	}
+
+	// 上限从 RetryBaseDelay 开始按 RetryMultiplier 增长，封顶 RetryMaxDelay，全抖动使实际等待落在 [0, 上限]
+	ceilings := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 40 * time.Millisecond}
+	waits := clock.takeWaits()
+	if len(waits) != len(ceilings) {
+		t.Fatalf("退避次数 = %d，期望 %d: %v", len(waits), len(ceilings), waits)
+	}
+	for i, wait := range waits {
+		if wait < 0 || wait > ceilings[i] {
+			t.Errorf("第 %d 次退避 = %v，超出 [0, %v]", i+1, wait, ceilings[i])
+		}
+	}
+}
+
+func TestRetryBackoffIsJittered(t *testing.T) {
+	clock := &recordingClock{}
+	cfg := mockConfig()
+	cfg.Clock = clock
+	cfg.MaxRetries = 1
+	cfg.RetryBaseDelay = time.Second
+	cfg.RetryMaxDelay = time.Second
+	c := newMockClient(t, cfg, &mocks.Greeter{})
+
+	// 同样的配置重复执行，全抖动下第一次退避不应每次相同
+	seen := make(map[time.Duration]bool)
+	for range 20 {
+		c.executeWithRetry(context.Background(), failUnavailable)
+		for _, wait := range clock.takeWaits() {
+			if wait > cfg.RetryBaseDelay {
+				t.Fatalf("退避 = %v，超过基础延迟 %v", wait, cfg.RetryBaseDelay)
+			}
+			seen[wait] = true
+		}
+	}
+	if len(seen) < 2 {
+		t.Errorf("20 次退避只出现 %d 种取值，未加入抖动", len(seen))
+	}
+}
+
+func TestRetryBackoffPrefersServerRetryInfo(t *testing.T) {
+	clock := &recordingClock{}
+	cfg := mockConfig()
+	cfg.Clock = clock
+	cfg.MaxRetries = 1
+	c := newMockClient(t, cfg, &mocks.Greeter{})
+
+	suggested := 250 * time.Millisecond
+	st, err := status.New(codes.ResourceExhausted, "限流").WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(suggested)})
+	if err != nil {
+		t.Fatalf("构造 RetryInfo 失败: %v", err)
+	}
+	err = c.executeWithRetry(context.Background(), func(context.Context) error { return st.Err() })
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("executeWithRetry 错误 = %v，期望 ResourceExhausted", err)
+	}
+	if waits := clock.takeWaits(); len(waits) != 1 || waits[0] != suggested {
+		t.Errorf("退避 = %v，期望采用服务端建议的 %v", waits, suggested)
+	}
+}
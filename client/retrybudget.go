@@ -0,0 +1,64 @@
+package client
+
+import (
+	"errors"
+	"srpc/pkg/tools"
+	"sync"
+	"time"
+)
+
+// ErrRetryBudgetExhausted 重试预算耗尽，请求放弃重试并快速失败
+var ErrRetryBudgetExhausted = errors.New("重试预算已耗尽")
+
+// ErrCircuitOpen 熔断器处于开启状态，拒绝请求
+var ErrCircuitOpen = errors.New("熔断器已开启")
+
+// retryBudget 基于令牌桶的全局重试预算，防止重试放大故障期间的负载
+// 每次成功请求存入 ratio 个令牌，每次重试消耗 1 个令牌；
+// 此外每秒至少补充到 minReserve 个令牌，保证低流量时仍能进行少量重试
+type retryBudget struct {
+	mu         sync.Mutex
+	tokens     float64
+	maxTokens  float64
+	ratio      float64
+	minReserve float64
+	lastRefill time.Time
+	clock      tools.Clock
+}
+
+// newRetryBudget 创建新的重试预算，令牌桶容量至少为 minReserve，按 clock 计时每秒补充令牌
+func newRetryBudget(ratio float64, minReserve, maxTokens int, clock tools.Clock) *retryBudget {
+	return &retryBudget{
+		tokens:     float64(minReserve),
+		maxTokens:  float64(max(maxTokens, minReserve)),
+		ratio:      ratio,
+		minReserve: float64(minReserve),
+		lastRefill: clock.Now(),
+		clock:      clock,
+	}
+}
+
+// deposit 记录一次成功请求，按比例存入令牌
+func (b *retryBudget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.tokens+b.ratio, b.maxTokens)
+}
+
+// withdraw 尝试为一次重试消耗令牌，预算不足时返回 false
+func (b *retryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// 每秒将令牌补充到最低保留量
+	if now := b.clock.Now(); now.Sub(b.lastRefill) >= time.Second {
+		b.tokens = max(b.tokens, b.minReserve)
+		b.lastRefill = now
+	}
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
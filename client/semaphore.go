@@ -0,0 +1,184 @@
+package client
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// defaultStreamRequestWeight 流调用默认占用的并发权重，流通常持续更久、传输更多数据
+const defaultStreamRequestWeight = 4
+
+// Semaphore 加权计数信号量，限制同时进行的请求占用的总权重
+// 等待者按先进先出的顺序获取许可，权重大的请求不会被源源不断的小请求饿死
+type Semaphore struct {
+	mu      sync.Mutex
+	size    int
+	cur     int
+	waiters list.List // 元素为 *semaphoreWaiter
+}
+
+// semaphoreWaiter 等待获取许可的请求
+type semaphoreWaiter struct {
+	n     int
+	ready chan struct{} // 获取成功时关闭
+}
+
+// NewSemaphore 创建总权重为 n 的信号量，n 小于 1 时按 1 处理
+func NewSemaphore(n int) *Semaphore {
+	if n < 1 {
+		n = 1
+	}
+	return &Semaphore{size: n}
+}
+
+// newRequestSemaphore 根据并发上限创建请求信号量，n 小于等于 0 时返回 nil 表示不限制
+func newRequestSemaphore(n int) *Semaphore {
+	if n <= 0 {
+		return nil
+	}
+	return NewSemaphore(n)
+}
+
+// Acquire 获取一个许可，ctx 取消前获取成功返回 true
+func (s *Semaphore) Acquire(ctx context.Context) bool {
+	return s.AcquireN(ctx, 1)
+}
+
+// AcquireContext 获取一个许可，ctx 取消或超时时返回 ctx.Err()，例如客户端关闭时中止等待
+func (s *Semaphore) AcquireContext(ctx context.Context) error {
+	if !s.AcquireN(ctx, 1) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// AcquireN 获取 n 个许可，ctx 取消前获取成功返回 true
+// n 大于容量时（例如 Resize 缩小之后）需等到没有任何许可被占用，再独占整个信号量
+func (s *Semaphore) AcquireN(ctx context.Context, n int) bool {
+	s.mu.Lock()
+	if s.fitsLocked(n) && s.waiters.Len() == 0 {
+		s.cur += n
+		s.mu.Unlock()
+		return true
+	}
+
+	w := &semaphoreWaiter{n: n, ready: make(chan struct{})}
+	elem := s.waiters.PushBack(w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return true
+	case <-ctx.Done():
+		s.mu.Lock()
+		select {
+		case <-w.ready:
+			// 取消的同时已获取成功，归还许可
+			s.cur -= n
+			s.notifyWaitersLocked()
+		default:
+			isFront := s.waiters.Front() == elem
+			s.waiters.Remove(elem)
+			// 队首的等待者放弃后，后面较小的请求可能已经可以获取
+			if isFront && s.size > s.cur {
+				s.notifyWaitersLocked()
+			}
+		}
+		s.mu.Unlock()
+		return false
+	}
+}
+
+// TryAcquire 尝试立即获取一个许可，不阻塞
+func (s *Semaphore) TryAcquire() bool {
+	return s.TryAcquireN(1)
+}
+
+// TryAcquireN 尝试立即获取 n 个许可，不阻塞；有等待者排队时同样失败
+func (s *Semaphore) TryAcquireN(n int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fitsLocked(n) && s.waiters.Len() == 0 {
+		s.cur += n
+		return true
+	}
+	return false
+}
+
+// fitsLocked 当前剩余容量是否足以获取 n 个许可，调用方需持有 s.mu
+// 缩容后占用可能超过容量，此时剩余容量为负，任何请求都需等待；
+// 超过容量的请求按容量计算，避免缩容前排队的大请求永远无法获取
+func (s *Semaphore) fitsLocked(n int) bool {
+	return s.size-s.cur >= min(n, s.size)
+}
+
+// Release 归还一个许可，必须与成功的 Acquire/TryAcquire 成对调用
+func (s *Semaphore) Release() {
+	s.ReleaseN(1)
+}
+
+// ReleaseN 归还 n 个许可，必须与成功的 AcquireN/TryAcquireN 使用相同的 n
+func (s *Semaphore) ReleaseN(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cur -= n
+	if s.cur < 0 {
+		panic("client: Semaphore 归还的许可多于已获取的许可")
+	}
+	s.notifyWaitersLocked()
+}
+
+// notifyWaitersLocked 按顺序唤醒可以获取许可的等待者，调用方需持有 s.mu
+func (s *Semaphore) notifyWaitersLocked() {
+	for {
+		front := s.waiters.Front()
+		if front == nil {
+			return
+		}
+		w := front.Value.(*semaphoreWaiter)
+		if !s.fitsLocked(w.n) {
+			// 队首放不下时停止，避免小请求持续插队导致大请求饥饿
+			return
+		}
+		s.cur += w.n
+		s.waiters.Remove(front)
+		close(w.ready)
+	}
+}
+
+// InUse 返回当前已被占用的许可数
+func (s *Semaphore) InUse() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cur
+}
+
+// Waiting 返回正在排队等待许可的请求数
+func (s *Semaphore) Waiting() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.waiters.Len()
+}
+
+// Capacity 返回信号量容量
+func (s *Semaphore) Capacity() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// Resize 将容量调整为 n（小于 1 时按 1 处理），返回调整前的容量
+// 扩容立即唤醒可以获取许可的等待者；缩容不会收回已发放的许可，
+// 占用超过新容量时新的请求需等待许可陆续归还，直到占用回落到新容量以下
+func (s *Semaphore) Resize(n int) int {
+	if n < 1 {
+		n = 1
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old := s.size
+	s.size = n
+	s.notifyWaitersLocked()
+	return old
+}
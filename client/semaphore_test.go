@@ -0,0 +1,299 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"srpc/client/mocks"
+)
+
+// waitWaiting 等待信号量上排队的请求数达到 n
+func waitWaiting(t *testing.T, s *Semaphore, n int) {
+	t.Helper()
+	waitFor(t, 5*time.Second, "等待者排队", func() bool { return s.Waiting() == n })
+}
+
+func TestSemaphoreAcquireContextCancelWhileFull(t *testing.T) {
+	s := NewSemaphore(2)
+	for range 2 {
+		if err := s.AcquireContext(context.Background()); err != nil {
+			t.Fatalf("获取许可失败: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() { result <- s.AcquireContext(ctx) }()
+	waitWaiting(t, s, 1)
+
+	cancel()
+	select {
+	case err := <-result:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("取消后 AcquireContext 错误 = %v，期望 context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("取消后 AcquireContext 仍在等待")
+	}
+	if s.Waiting() != 0 || s.InUse() != 2 {
+		t.Errorf("取消后 Waiting=%d InUse=%d，期望 0 和 2", s.Waiting(), s.InUse())
+	}
+
+	// 取消的等待者不占用许可，归还后新的请求可以获取
+	s.Release()
+	if !s.TryAcquire() {
+		t.Error("归还许可后 TryAcquire 失败")
+	}
+}
+
+func TestSemaphoreAcquireContextTimeout(t *testing.T) {
+	s := NewSemaphore(1)
+	if !s.TryAcquire() {
+		t.Fatal("获取许可失败")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.AcquireContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("超时后 AcquireContext 错误 = %v，期望 DeadlineExceeded", err)
+	}
+	if s.Waiting() != 0 {
+		t.Errorf("超时后 Waiting = %d，期望 0", s.Waiting())
+	}
+}
+
+func TestSemaphoreCancelledFrontWaiterUnblocksSmaller(t *testing.T) {
+	s := NewSemaphore(4)
+	if !s.TryAcquireN(2) {
+		t.Fatal("获取许可失败")
+	}
+
+	// 队首的大请求放不下，后面的小请求排在它后面
+	bigCtx, cancelBig := context.WithCancel(context.Background())
+	bigDone := make(chan bool, 1)
+	go func() { bigDone <- s.AcquireN(bigCtx, 4) }()
+	waitWaiting(t, s, 1)
+	smallDone := make(chan bool, 1)
+	go func() { smallDone <- s.AcquireN(context.Background(), 1) }()
+	waitWaiting(t, s, 2)
+
+	cancelBig()
+	if <-bigDone {
+		t.Fatal("取消的大请求不应获取成功")
+	}
+	select {
+	case ok := <-smallDone:
+		if !ok {
+			t.Fatal("小请求获取失败")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("队首取消后，放得下的小请求仍在等待")
+	}
+	if s.InUse() != 3 {
+		t.Errorf("InUse = %d，期望 3", s.InUse())
+	}
+}
+
+func TestSemaphoreTryAcquireChurn(t *testing.T) {
+	s := NewSemaphore(8)
+	before := runtime.NumGoroutine()
+
+	const goroutines, iterations = 32, 5000
+	var acquired, maxInUse atomic.Int64
+	var wg sync.WaitGroup
+	for range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range iterations {
+				if !s.TryAcquire() {
+					continue
+				}
+				acquired.Add(1)
+				if n := int64(s.InUse()); n > maxInUse.Load() {
+					maxInUse.Store(n)
+				}
+				s.Release()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if acquired.Load() == 0 {
+		t.Fatal("TryAcquire 从未成功")
+	}
+	if got := maxInUse.Load(); got > 8 {
+		t.Errorf("占用峰值 %d 超过容量 8", got)
+	}
+	if s.InUse() != 0 || s.Waiting() != 0 {
+		t.Errorf("结束后 InUse=%d Waiting=%d，期望均为 0", s.InUse(), s.Waiting())
+	}
+	// TryAcquire 不阻塞也不创建定时器或 goroutine，大量调用后不应残留 goroutine
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("goroutine 数 %d 多于开始时的 %d", after, before)
+	}
+}
+
+func TestSemaphoreMetricsExposeWaiters(t *testing.T) {
+	cfg := mockConfig()
+	cfg.MaxConcurrentRequests = 1
+	c := newMockClient(t, cfg, &mocks.Greeter{})
+	if !c.semaphore.TryAcquire() {
+		t.Fatal("获取许可失败")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.SendNow(ctx, "waiting")
+	waitWaiting(t, c.semaphore, 1)
+
+	m := c.GetMetrics()
+	if m.ConcurrencyInUse != 1 || m.ConcurrencyLimit != 1 || m.ConcurrencyWaiting != 1 {
+		t.Errorf("并发指标 in_use=%d limit=%d waiting=%d，期望 1、1、1", m.ConcurrencyInUse, m.ConcurrencyLimit, m.ConcurrencyWaiting)
+	}
+	cancel()
+	waitWaiting(t, c.semaphore, 0)
+	c.semaphore.Release()
+}
+
+func TestSemaphoreShrinkBelowInUse(t *testing.T) {
+	s := NewSemaphore(4)
+	if !s.TryAcquireN(4) {
+		t.Fatal("获取许可失败")
+	}
+	if old := s.Resize(2); old != 4 {
+		t.Errorf("Resize 返回 %d，期望调整前的容量 4", old)
+	}
+	// 缩容不收回已发放的许可
+	if s.InUse() != 4 || s.Capacity() != 2 {
+		t.Fatalf("缩容后 InUse=%d Capacity=%d，期望 4 和 2", s.InUse(), s.Capacity())
+	}
+
+	// 缩容前排队的等待者在占用回落到新容量以下前不能获取
+	acquired := make(chan bool, 1)
+	go func() { acquired <- s.Acquire(context.Background()) }()
+	waitWaiting(t, s, 1)
+	for _, inUse := range []int{3, 2} {
+		s.Release()
+		time.Sleep(20 * time.Millisecond)
+		if len(acquired) != 0 {
+			t.Fatalf("占用 %d 未回落到新容量以下时等待者已获取", inUse)
+		}
+		if s.InUse() != inUse {
+			t.Fatalf("InUse = %d，期望 %d", s.InUse(), inUse)
+		}
+	}
+	s.Release()
+	if !<-acquired {
+		t.Fatal("占用回落后等待者获取失败")
+	}
+	if s.InUse() != 2 || s.TryAcquire() {
+		t.Fatalf("InUse = %d，期望等待者获取后占满新容量 2", s.InUse())
+	}
+
+	// 全部归还后恰好能获取新容量，没有多出或丢失许可
+	s.ReleaseN(2)
+	if !s.TryAcquireN(2) || s.TryAcquire() {
+		t.Error("全部归还后应恰好能获取 2 个许可")
+	}
+}
+
+func TestSemaphoreGrowWakesWaiters(t *testing.T) {
+	s := NewSemaphore(1)
+	if !s.TryAcquire() {
+		t.Fatal("获取许可失败")
+	}
+	done := make(chan bool, 3)
+	for range 3 {
+		go func() { done <- s.Acquire(context.Background()) }()
+	}
+	waitWaiting(t, s, 3)
+
+	s.Resize(3)
+	for range 2 {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("扩容后等待者仍未获取")
+		}
+	}
+	if s.Waiting() != 1 || s.InUse() != 3 {
+		t.Errorf("扩容后 Waiting=%d InUse=%d，期望 1 和 3", s.Waiting(), s.InUse())
+	}
+	s.Release()
+	<-done
+}
+
+func TestSemaphoreResizeUnderLoad(t *testing.T) {
+	s := NewSemaphore(8)
+	var holders, maxHolders atomic.Int64
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var wg sync.WaitGroup
+	for range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				if !s.Acquire(ctx) {
+					return
+				}
+				// 每个持有者占用 1 个许可，同时持有者不能超过调整过程中出现的最大容量 8
+				if n := holders.Add(1); n > maxHolders.Load() {
+					maxHolders.Store(n)
+				}
+				time.Sleep(10 * time.Microsecond)
+				holders.Add(-1)
+				s.Release()
+			}
+		}()
+	}
+
+	sizes := []int{2, 8, 1, 5, 3, 8}
+	for i := range 300 {
+		s.Resize(sizes[i%len(sizes)])
+		time.Sleep(50 * time.Microsecond)
+	}
+	cancel()
+	wg.Wait()
+
+	if got := maxHolders.Load(); got > 8 {
+		t.Errorf("同时持有许可的请求数峰值 %d 超过最大容量 8", got)
+	}
+	if s.InUse() != 0 || s.Waiting() != 0 {
+		t.Fatalf("结束后 InUse=%d Waiting=%d，期望均为 0", s.InUse(), s.Waiting())
+	}
+	s.Resize(3)
+	if !s.TryAcquireN(3) || s.TryAcquire() {
+		t.Error("反复调整后应恰好能获取容量 3 个许可")
+	}
+}
+
+func TestSetMaxConcurrency(t *testing.T) {
+	cfg := mockConfig()
+	cfg.MaxConcurrentRequests = 4
+	c := newMockClient(t, cfg, &mocks.Greeter{})
+	if !c.semaphore.TryAcquireN(3) {
+		t.Fatal("获取许可失败")
+	}
+
+	if err := c.SetMaxConcurrency(2); err != nil {
+		t.Fatalf("SetMaxConcurrency 失败: %v", err)
+	}
+	if s := c.Status(); s.ConcurrencyLimit != 2 || s.ConcurrencyInUse != 3 {
+		t.Errorf("Status 并发上限 %d、占用 %d，期望 2 和 3", s.ConcurrencyLimit, s.ConcurrencyInUse)
+	}
+	if err := c.SetMaxConcurrency(0); err == nil {
+		t.Error("并发上限为 0 应返回错误")
+	}
+	c.semaphore.ReleaseN(3)
+
+	unlimited := newMockClient(t, mockConfig(), &mocks.Greeter{})
+	if err := unlimited.SetMaxConcurrency(2); !errors.Is(err, ErrConcurrencyUnlimited) {
+		t.Errorf("未启用并发限制时错误 = %v，期望 ErrConcurrencyUnlimited", err)
+	}
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// buildServiceConfig 合并 ServiceConfigJSON 和 LoadBalancingPolicy，返回传给 grpc.WithDefaultServiceConfig 的 JSON
+// 二者均未配置时返回空字符串；ServiceConfigJSON 自身已指定负载均衡策略时不能再设置 LoadBalancingPolicy
+func buildServiceConfig(config Config) (string, error) {
+	if config.ServiceConfigJSON == "" {
+		if config.LoadBalancingPolicy == "" {
+			return "", nil
+		}
+		return fmt.Sprintf(`{"loadBalancingConfig": [{"%s": {}}]}`, config.LoadBalancingPolicy), nil
+	}
+
+	var sc map[string]interface{}
+	if err := json.Unmarshal([]byte(config.ServiceConfigJSON), &sc); err != nil {
+		return "", fmt.Errorf("服务配置不是有效的 JSON 对象: %w", err)
+	}
+	if config.LoadBalancingPolicy != "" {
+		_, hasConfig := sc["loadBalancingConfig"]
+		_, hasPolicy := sc["loadBalancingPolicy"]
+		if hasConfig || hasPolicy {
+			return "", fmt.Errorf("服务配置已指定负载均衡策略，不能同时设置 LoadBalancingPolicy")
+		}
+		sc["loadBalancingConfig"] = []interface{}{map[string]interface{}{config.LoadBalancingPolicy: map[string]interface{}{}}}
+		merged, err := json.Marshal(sc)
+		if err != nil {
+			return "", err
+		}
+		return string(merged), nil
+	}
+	return config.ServiceConfigJSON, nil
+}
+
+// validateServiceConfig 按 gRPC 的规则解析服务配置，字段类型错误、未知的负载均衡策略等问题在创建客户端时即可发现
+// grpc.NewClient 不会发起连接，只解析拨号选项
+func validateServiceConfig(serviceConfig string) error {
+	if serviceConfig == "" {
+		return nil
+	}
+	conn, err := grpc.NewClient("passthrough:///service-config-validation",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultServiceConfig(serviceConfig),
+	)
+	if err != nil {
+		return fmt.Errorf("服务配置无效: %w", err)
+	}
+	return conn.Close()
+}
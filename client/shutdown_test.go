@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"srpc/client/mocks"
+)
+
+// sendConcurrently 并发发起 n 次 SendNow，返回各次结果的通道
+func sendConcurrently(c *GRPCClient, n int) <-chan error {
+	results := make(chan error, n)
+	for range n {
+		go func() {
+			_, err := c.SendNow(context.Background(), "drain")
+			results <- err
+		}()
+	}
+	return results
+}
+
+func TestShutdownGracefullyCompletesSlowRequests(t *testing.T) {
+	greeter := &mocks.Greeter{SayHelloFunc: slowSayHello(200 * time.Millisecond)}
+	c := newMockClient(t, mockConfig(), greeter)
+
+	const n = 3
+	results := sendConcurrently(c, n)
+	waitFor(t, time.Second, "请求开始执行", func() bool { return greeter.Calls("SayHello") == n })
+
+	start := time.Now()
+	c.ShutdownGracefully(5 * time.Second)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("ShutdownGracefully 耗时 %v，应在请求完成后立即返回", elapsed)
+	}
+
+	// 关闭返回时进行中的请求均已完成，结果随即送达
+	for i := range n {
+		select {
+		case err := <-results:
+			if err != nil {
+				t.Errorf("第 %d 个请求在排空期间失败: %v", i+1, err)
+			}
+		case <-time.After(100 * time.Millisecond):
+			t.Fatalf("ShutdownGracefully 返回后仍有 %d 个请求未完成", n-i)
+		}
+	}
+	snap := c.GetMetrics()
+	if snap.RequestsDrained != n || snap.RequestsAborted != 0 {
+		t.Errorf("RequestsDrained = %d，RequestsAborted = %d，期望 %d 和 0", snap.RequestsDrained, snap.RequestsAborted, n)
+	}
+	if _, err := c.SendNow(context.Background(), "after"); err == nil {
+		t.Error("关闭后 SendNow 应失败")
+	}
+}
+
+func TestShutdownGracefullyAbortsAfterTimeout(t *testing.T) {
+	greeter := &mocks.Greeter{SayHelloFunc: slowSayHello(time.Minute)}
+	c := newMockClient(t, mockConfig(), greeter)
+
+	results := sendConcurrently(c, 1)
+	waitFor(t, time.Second, "请求开始执行", func() bool { return greeter.Calls("SayHello") == 1 })
+
+	start := time.Now()
+	c.ShutdownGracefully(100 * time.Millisecond)
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("ShutdownGracefully 耗时 %v，期望在排空超时后返回", elapsed)
+	}
+
+	select {
+	case err := <-results:
+		if !errors.Is(err, ErrCanceled) {
+			t.Errorf("被中止的请求错误 = %v，期望 ErrCanceled 类别", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("排空超时后请求未被取消")
+	}
+	if snap := c.GetMetrics(); snap.RequestsAborted != 1 {
+		t.Errorf("RequestsAborted = %d，期望 1", snap.RequestsAborted)
+	}
+}
+
+// isRunning 报告 Run 是否正在执行
+func isRunning(c *GRPCClient) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.running
+}
+
+// shutdownConcurrently 从 n 个 goroutine 交替调用三种关闭方法，等待全部返回
+func shutdownConcurrently(t *testing.T, c *GRPCClient, n int) {
+	t.Helper()
+	returned := make(chan struct{}, n)
+	for i := range n {
+		go func() {
+			defer func() { returned <- struct{}{} }()
+			switch i % 3 {
+			case 0:
+				c.Shutdown()
+			case 1:
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				c.ShutdownContext(ctx)
+			default:
+				c.ShutdownGracefully(time.Second)
+			}
+		}()
+	}
+	for range n {
+		select {
+		case <-returned:
+		case <-time.After(5 * time.Second):
+			t.Fatal("并发关闭未返回")
+		}
+	}
+}
+
+func TestConcurrentShutdown(t *testing.T) {
+	c := newMockClient(t, mockConfig(), &mocks.Greeter{})
+
+	done := runClient(c)
+	waitFor(t, time.Second, "Run 启动", func() bool { return isRunning(c) })
+
+	// 关闭只执行一次，其余调用等待其完成，任何调用都不应 panic
+	shutdownConcurrently(t, c, 9)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run 返回错误: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("关闭后 Run 未返回")
+	}
+	if c.ConnectionState() == StateConnected {
+		t.Error("关闭后连接状态仍为已连接")
+	}
+
+	// 关闭完成后再次并发调用立即返回
+	shutdownConcurrently(t, c, 9)
+}
+
+func TestConcurrentShutdownBeforeRun(t *testing.T) {
+	c := newMockClient(t, mockConfig(), &mocks.Greeter{})
+	shutdownConcurrently(t, c, 9)
+	if c.ConnectionState() == StateConnected {
+		t.Error("关闭后连接状态仍为已连接")
+	}
+}
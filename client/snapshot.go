@@ -0,0 +1,269 @@
+package client
+
+import (
+	"time"
+
+	"srpc/pkg/stats"
+)
+
+// Snapshot 某一时刻的指标快照，由 Metrics.GetMetrics 返回
+// 计数和累计时长可以通过 Delta 计算两次快照之间的增量；Map 转换为日志和调试接口使用的字段
+type Snapshot struct {
+	TakenAt time.Time // 快照时间
+
+	TotalRequests        int64
+	SuccessfulRequests   int64
+	FailedRequests       int64
+	SuccessRate          float64                // 成功率（百分比）
+	Windows              map[string]WindowStats // 最近 1m、5m、15m 的滑动窗口统计，不受 Delta 影响
+	TotalRequestDuration time.Duration          // 全部尝试的耗时累计
+	AvgDuration          time.Duration
+	P50Duration          time.Duration
+	P90Duration          time.Duration
+	P99Duration          time.Duration
+	MaxDuration          time.Duration
+
+	// 服务端通过 trailer 返回处理耗时的尝试；旧版本服务端不返回，不计入
+	ServerTimedRequests  int64
+	TotalServerDuration  time.Duration
+	TotalNetworkOverhead time.Duration // 端到端耗时减去服务端处理耗时
+	AvgServerDuration    time.Duration
+	AvgNetworkOverhead   time.Duration
+
+	InstanceRequests map[string]int64 // 按服务端实例 ID 统计的尝试数，服务端未返回实例 ID 时不计入
+
+	ErrorsByCode      map[string]int64 // 单次尝试失败数，键为状态码名称
+	FinalErrorsByCode map[string]int64 // 重试结束后仍失败的逻辑请求数，键为状态码名称
+	ErrorsRetriedAway int64
+	HedgedRequests    int64
+	HedgeWins         int64
+
+	TotalRetries          int64
+	ReconnectCount        int64
+	ReconnectBudgetResets int64
+	RetryBudgetConsumed   int64
+	RetriesSuppressed     int64
+	RateLimitedRequests   int64
+	RateLimitWait         time.Duration
+	RequestsDrained       int64
+	RequestsAborted       int64
+	CacheHits             int64
+	CacheMisses           int64
+	DispatchDropped       int64
+
+	// 连接可用性：TotalDowntime 包含进行中的断开，Delta 中为区间内新增的断开时长
+	Outages            int64         // 已结束的断开次数
+	TotalDowntime      time.Duration // 断开时长累计
+	LastOutageDuration time.Duration // 最近一次已结束的断开时长，从离开已连接状态到重连成功
+	LastConnectedTime  time.Time     // 最近一次成功连接的时间
+	ConnectionUptime   time.Duration // 当前连接已持续的时间，未连接时为 0
+
+	// 瞬时值，Delta 中保留较新快照的值
+	DispatchQueueDepth int
+	DispatchRunning    int
+	ConcurrencyInUse   int
+	ConcurrencyLimit   int
+	ConcurrencyWaiting int
+	LastRequestTime    time.Time
+	LastPingRTT        time.Duration
+	LastClockSkew      time.Duration
+
+	PingCount        int64
+	TotalPingRTT     time.Duration
+	AvgPingRTT       time.Duration
+	Connections      map[int]ConnSnapshot    // 按连接池序号统计
+	Transfer         stats.Totals            // 传输层字节统计（全部方法）
+	CompressionRatio float64                 // 压缩后与压缩前字节数之比
+	MethodTransfer   map[string]stats.Totals // 传输层字节统计（按方法）
+
+	latency latencyHistogram // 延迟直方图副本，Delta 据此估算区间内的分位数
+}
+
+// WindowStats 一个滑动窗口内的请求统计
+type WindowStats struct {
+	Requests    int64
+	SuccessRate float64 // 成功率（百分比）
+	ErrorRate   float64 // 错误率（百分比）
+	RPS         float64 // 每秒请求数
+}
+
+// ConnSnapshot 单个连接的统计
+type ConnSnapshot struct {
+	Requests   int64
+	Failures   int64
+	Reconnects int64
+}
+
+// Delta 返回相对于较早快照 prev 的增量：计数、累计时长和按状态码、连接、方法的统计逐项相减，
+// 成功率、平均值和分位数按区间内的数据重新计算，瞬时值和滑动窗口保留当前快照的值
+// prev 与当前快照之间调用过 Metrics.Reset 时增量没有意义，可能出现负数
+func (s Snapshot) Delta(prev Snapshot) Snapshot {
+	d := s
+	d.TotalRequests -= prev.TotalRequests
+	d.SuccessfulRequests -= prev.SuccessfulRequests
+	d.FailedRequests -= prev.FailedRequests
+	d.TotalRequestDuration -= prev.TotalRequestDuration
+	d.ServerTimedRequests -= prev.ServerTimedRequests
+	d.TotalServerDuration -= prev.TotalServerDuration
+	d.TotalNetworkOverhead -= prev.TotalNetworkOverhead
+	d.InstanceRequests = deltaCounts(s.InstanceRequests, prev.InstanceRequests)
+
+	d.ErrorsByCode = deltaCounts(s.ErrorsByCode, prev.ErrorsByCode)
+	d.FinalErrorsByCode = deltaCounts(s.FinalErrorsByCode, prev.FinalErrorsByCode)
+	d.ErrorsRetriedAway -= prev.ErrorsRetriedAway
+	d.HedgedRequests -= prev.HedgedRequests
+	d.HedgeWins -= prev.HedgeWins
+
+	d.TotalRetries -= prev.TotalRetries
+	d.ReconnectCount -= prev.ReconnectCount
+	d.ReconnectBudgetResets -= prev.ReconnectBudgetResets
+	d.RetryBudgetConsumed -= prev.RetryBudgetConsumed
+	d.RetriesSuppressed -= prev.RetriesSuppressed
+	d.RateLimitedRequests -= prev.RateLimitedRequests
+	d.RateLimitWait -= prev.RateLimitWait
+	d.RequestsDrained -= prev.RequestsDrained
+	d.RequestsAborted -= prev.RequestsAborted
+	d.CacheHits -= prev.CacheHits
+	d.CacheMisses -= prev.CacheMisses
+	d.DispatchDropped -= prev.DispatchDropped
+	d.Outages -= prev.Outages
+	d.TotalDowntime -= prev.TotalDowntime
+
+	d.PingCount -= prev.PingCount
+	d.TotalPingRTT -= prev.TotalPingRTT
+
+	d.Connections = make(map[int]ConnSnapshot, len(s.Connections))
+	for index, conn := range s.Connections {
+		p := prev.Connections[index]
+		d.Connections[index] = ConnSnapshot{
+			Requests:   conn.Requests - p.Requests,
+			Failures:   conn.Failures - p.Failures,
+			Reconnects: conn.Reconnects - p.Reconnects,
+		}
+	}
+
+	d.Transfer = s.Transfer.Sub(prev.Transfer)
+	d.MethodTransfer = make(map[string]stats.Totals, len(s.MethodTransfer))
+	for method, totals := range s.MethodTransfer {
+		d.MethodTransfer[method] = totals.Sub(prev.MethodTransfer[method])
+	}
+
+	// 直方图逐桶相减后估算区间内的分位数；最大值只能取到所在桶的上界
+	d.latency = s.latency.sub(prev.latency)
+	d.P50Duration = d.latency.quantile(0.50)
+	d.P90Duration = d.latency.quantile(0.90)
+	d.P99Duration = d.latency.quantile(0.99)
+	d.MaxDuration = d.latency.quantile(1)
+
+	d.computeDerived()
+	return d
+}
+
+// computeDerived 根据计数和累计值计算成功率、平均耗时、平均 Ping RTT 和压缩比
+func (s *Snapshot) computeDerived() {
+	s.SuccessRate, s.AvgDuration, s.AvgPingRTT = 0, 0, 0
+	s.AvgServerDuration, s.AvgNetworkOverhead = 0, 0
+	if s.TotalRequests > 0 {
+		s.SuccessRate = float64(s.SuccessfulRequests) / float64(s.TotalRequests) * 100
+		s.AvgDuration = s.TotalRequestDuration / time.Duration(s.TotalRequests)
+	}
+	if s.ServerTimedRequests > 0 {
+		s.AvgServerDuration = s.TotalServerDuration / time.Duration(s.ServerTimedRequests)
+		s.AvgNetworkOverhead = s.TotalNetworkOverhead / time.Duration(s.ServerTimedRequests)
+	}
+	if s.PingCount > 0 {
+		s.AvgPingRTT = s.TotalPingRTT / time.Duration(s.PingCount)
+	}
+	s.CompressionRatio = s.Transfer.CompressionRatio()
+}
+
+// deltaCounts 逐键计算计数增量，省略增量为 0 的键
+func deltaCounts(current, prev map[string]int64) map[string]int64 {
+	out := make(map[string]int64, len(current))
+	for key, n := range current {
+		if diff := n - prev[key]; diff != 0 {
+			out[key] = diff
+		}
+	}
+	return out
+}
+
+// Map 转换为便于日志输出和 JSON 序列化的字段，时长以字符串表示
+func (s Snapshot) Map() map[string]interface{} {
+	windows := make(map[string]interface{}, len(s.Windows))
+	for name, w := range s.Windows {
+		windows[name] = map[string]interface{}{
+			"requests":     w.Requests,
+			"success_rate": w.SuccessRate,
+			"error_rate":   w.ErrorRate,
+			"rps":          w.RPS,
+		}
+	}
+
+	connections := make(map[int]interface{}, len(s.Connections))
+	for index, conn := range s.Connections {
+		connections[index] = map[string]interface{}{
+			"requests":   conn.Requests,
+			"failures":   conn.Failures,
+			"reconnects": conn.Reconnects,
+		}
+	}
+
+	methodTransfer := make(map[string]interface{}, len(s.MethodTransfer))
+	for method, totals := range s.MethodTransfer {
+		methodTransfer[method] = totals.Map()
+	}
+
+	return map[string]interface{}{
+		"total_requests":          s.TotalRequests,
+		"successful_requests":     s.SuccessfulRequests,
+		"failed_requests":         s.FailedRequests,
+		"success_rate":            s.SuccessRate,
+		"windows":                 windows,
+		"avg_duration":            s.AvgDuration.String(),
+		"p50_duration":            s.P50Duration.String(),
+		"p90_duration":            s.P90Duration.String(),
+		"p99_duration":            s.P99Duration.String(),
+		"max_duration":            s.MaxDuration.String(),
+		"server_timed_requests":   s.ServerTimedRequests,
+		"avg_server_duration":     s.AvgServerDuration.String(),
+		"avg_network_overhead":    s.AvgNetworkOverhead.String(),
+		"requests_by_instance":    s.InstanceRequests,
+		"errors_by_code":          s.ErrorsByCode,
+		"final_errors_by_code":    s.FinalErrorsByCode,
+		"errors_retried_away":     s.ErrorsRetriedAway,
+		"hedged_requests":         s.HedgedRequests,
+		"hedge_wins":              s.HedgeWins,
+		"total_retries":           s.TotalRetries,
+		"reconnect_count":         s.ReconnectCount,
+		"reconnect_budget_resets": s.ReconnectBudgetResets,
+		"retry_budget_consumed":   s.RetryBudgetConsumed,
+		"retries_suppressed":      s.RetriesSuppressed,
+		"rate_limited_requests":   s.RateLimitedRequests,
+		"rate_limit_wait":         s.RateLimitWait.String(),
+		"requests_drained":        s.RequestsDrained,
+		"requests_aborted":        s.RequestsAborted,
+		"cache_hits":              s.CacheHits,
+		"cache_misses":            s.CacheMisses,
+		"dispatch_queue_depth":    s.DispatchQueueDepth,
+		"dispatch_running":        s.DispatchRunning,
+		"dispatch_dropped":        s.DispatchDropped,
+		"concurrency_in_use":      s.ConcurrencyInUse,
+		"concurrency_limit":       s.ConcurrencyLimit,
+		"concurrency_waiting":     s.ConcurrencyWaiting,
+		"outages":                 s.Outages,
+		"total_downtime_ms":       s.TotalDowntime.Milliseconds(),
+		"last_outage_duration_ms": s.LastOutageDuration.Milliseconds(),
+		"last_connected_time":     s.LastConnectedTime,
+		"connection_uptime_s":     s.ConnectionUptime.Seconds(),
+		"last_request_time":       s.LastRequestTime,
+		"ping_count":              s.PingCount,
+		"avg_ping_rtt":            s.AvgPingRTT.String(),
+		"last_ping_rtt":           s.LastPingRTT.String(),
+		"last_clock_skew":         s.LastClockSkew.String(),
+		"connections":             connections,
+		"transfer":                s.Transfer.Map(),
+		"compression_ratio":       s.CompressionRatio,
+		"method_transfer":         methodTransfer,
+	}
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"reflect"
+	"time"
+)
+
+// redacted 状态快照中替换敏感配置值的占位符
+const redacted = "[REDACTED]"
+
+// Status 客户端运行状态快照，可直接序列化为 JSON
+type Status struct {
+	ConnectionState      string                 `json:"connection_state"`
+	CircuitBreakerState  string                 `json:"circuit_breaker_state"`
+	CircuitBreakerForced bool                   `json:"circuit_breaker_forced"`
+	LastError            string                 `json:"last_error,omitempty"`
+	Shutting             bool                   `json:"shutting"`
+	Connections          []ConnStatus           `json:"connections"`
+	Config               map[string]interface{} `json:"config"`
+
+	// 并发限制的当前上限和已占用权重，未设置 MaxConcurrentRequests 时均为 0
+	// 上限可能已被 SetMaxConcurrency 调整，与 Config 中的 MaxConcurrentRequests 不同
+	ConcurrencyLimit int `json:"concurrency_limit"`
+	ConcurrencyInUse int `json:"concurrency_in_use"`
+}
+
+// ConnStatus 连接池中单个连接的状态
+type ConnStatus struct {
+	Index          int    `json:"index"`
+	State          string `json:"state"`
+	LastError      string `json:"last_error,omitempty"`
+	ReconnectCount int    `json:"reconnect_count"`
+}
+
+// Status 返回客户端当前状态快照，配置中的 metadata 值已脱敏
+func (c *GRPCClient) Status() Status {
+	c.mu.RLock()
+	status := Status{
+		ConnectionState: c.connectionState.String(),
+		LastError:       errorString(c.lastError),
+		Shutting:        c.isShutting,
+		Connections:     make([]ConnStatus, 0, len(c.pool.conns)),
+	}
+	for _, pc := range c.pool.conns {
+		status.Connections = append(status.Connections, ConnStatus{
+			Index:          pc.index,
+			State:          pc.state.String(),
+			LastError:      errorString(pc.lastError),
+			ReconnectCount: pc.reconnectCount,
+		})
+	}
+	c.mu.RUnlock()
+
+	status.CircuitBreakerState = c.circuitBreaker.GetState().String()
+	status.CircuitBreakerForced = c.circuitBreaker.IsForced()
+	status.Config = redactConfig(c.config)
+	// 运行时配置可能已被重新加载，以当前生效的值为准
+	rc := c.runtimeConfig()
+	status.Config["RequestInterval"] = rc.RequestInterval.String()
+	status.Config["JitterPercent"] = rc.JitterPercent
+	status.Config["MaxRetries"] = rc.MaxRetries
+	if c.semaphore != nil {
+		status.ConcurrencyLimit = c.semaphore.Capacity()
+		status.ConcurrencyInUse = c.semaphore.InUse()
+	}
+	return status
+}
+
+// GetMetrics 返回客户端指标快照，两次快照可通过 Snapshot.Delta 计算区间增量
+func (c *GRPCClient) GetMetrics() Snapshot {
+	return c.metrics.GetMetrics()
+}
+
+// ResetMetrics 清零客户端指标，见 Metrics.Reset
+func (c *GRPCClient) ResetMetrics() {
+	c.metrics.Reset()
+	c.slogger.Info("指标已重置")
+}
+
+// redactConfig 将配置转换为可序列化的键值对
+// 函数、接口等无法序列化的字段（回调、时钟、DialOptions）被省略，metadata 值可能包含凭证，只保留键名；签名密钥只显示是否配置
+func redactConfig(config Config) map[string]interface{} {
+	out := make(map[string]interface{})
+	v := reflect.ValueOf(config)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+		switch {
+		case field.Name == "Metadata":
+			keys := make(map[string]string, value.Len())
+			for _, k := range value.MapKeys() {
+				keys[k.String()] = redacted
+			}
+			out[field.Name] = keys
+		case field.Name == "HMACSecret":
+			if value.Len() > 0 {
+				out[field.Name] = redacted
+			} else {
+				out[field.Name] = ""
+			}
+		case field.Type == reflect.TypeOf(time.Duration(0)):
+			out[field.Name] = time.Duration(value.Int()).String()
+		case field.Type.Kind() == reflect.Func, field.Type.Kind() == reflect.Interface,
+			field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Interface:
+			continue
+		default:
+			out[field.Name] = value.Interface()
+		}
+	}
+	return out
+}
+
+// errorString 返回错误信息，err 为 nil 时返回空字符串
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,173 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"srpc/pkg/stats"
+	pb "srpc/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/metadata"
+)
+
+// CallGetStream 调用服务端流 GetStream，对每条响应依次调用 handle
+// handle 返回错误时停止接收并原样返回该错误；流正常结束时返回 nil，RPC 错误按类别包装为 RPCError
+// opts 追加在默认调用选项之后，例如传入 WithoutCompression() 为已压缩的载荷关闭压缩
+// 配置了 MaxConcurrentRequests 时，流在整个生命周期内占用 StreamRequestWeight 个并发许可
+func (c *GRPCClient) CallGetStream(ctx context.Context, data string, handle func(*pb.StreamResData) error, opts ...grpc.CallOption) error {
+	release, err := c.acquireStreamSlot(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return c.callGetStream(ctx, data, handle, opts...)
+}
+
+// CallGetStreamChan 以 channel 形式返回 GetStream 的响应，channel 最多缓冲 bufferSize 条（小于 0 时按 0 处理，即不缓冲）
+// 消费方跟不上时后台 goroutine 停止调用 Recv，由 HTTP/2 流控向服务端施加背压，而不是无限缓冲：
+// 客户端为该流占用的内存约为 bufferSize 条消息加上 gRPC 的流控窗口（默认 64KB，启用 BDP 估算时可能增大）
+// 响应 channel 关闭后，错误 channel 恰好返回一个值：流正常结束为 nil，其余与 CallGetStream 相同；
+// 消费方提前放弃时应取消 ctx，否则后台 goroutine 会一直阻塞在发送上并持有流和并发许可，直到客户端关闭
+func (c *GRPCClient) CallGetStreamChan(ctx context.Context, data string, bufferSize int, opts ...grpc.CallOption) (<-chan *pb.StreamResData, <-chan error) {
+	out := make(chan *pb.StreamResData, max(bufferSize, 0))
+	errc := make(chan error, 1)
+
+	// 客户端关闭时同样停止等待消费方，使关闭时的排空不被阻塞
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(c.runContext(), cancel)
+	go func() {
+		defer cancel()
+		defer stop()
+
+		err := c.CallGetStream(ctx, data, func(resp *pb.StreamResData) error {
+			select {
+			case out <- resp:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}, opts...)
+		close(out)
+		errc <- err
+	}()
+	return out, errc
+}
+
+// callGetStream 执行 GetStream，调用方负责获取并发许可
+func (c *GRPCClient) callGetStream(ctx context.Context, data string, handle func(*pb.StreamResData) error, opts ...grpc.CallOption) error {
+	greeter, ctx, cancel, err := c.prepareStream(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	c.logPayload(ctx, "GetStream", map[string]interface{}{"request_data": data})
+	stream, err := greeter.GetStream(ctx, &pb.StreamReqData{Data: data}, opts...)
+	if err != nil {
+		return classifyError(err)
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			c.logStreamTrailer(ctx, "GetStream", stream.Trailer())
+			return nil
+		}
+		if err != nil {
+			return classifyError(err)
+		}
+		c.logPayload(ctx, "GetStream", map[string]interface{}{"response_data": resp.GetData()})
+		if err := handle(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// CallPutStream 调用客户端流 PutStream，依次发送 data 中的每条消息并返回服务端的汇总响应
+// opts 和并发许可的含义与 CallGetStream 相同
+func (c *GRPCClient) CallPutStream(ctx context.Context, data []string, opts ...grpc.CallOption) (*pb.StreamResData, error) {
+	release, err := c.acquireStreamSlot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	greeter, ctx, cancel, err := c.prepareStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	stream, err := greeter.PutStream(ctx, opts...)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	for _, item := range data {
+		c.logPayload(ctx, "PutStream", map[string]interface{}{"request_data": item})
+		if err := stream.Send(&pb.StreamReqData{Data: item}); err != nil {
+			// Send 返回 io.EOF 表示服务端已结束流，真实错误需要通过 CloseAndRecv 获取
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, classifyError(err)
+		}
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	c.logStreamTrailer(ctx, "PutStream", stream.Trailer())
+	c.logPayload(ctx, "PutStream", map[string]interface{}{"response_data": resp.GetData()})
+	return resp, nil
+}
+
+// logStreamTrailer 以调试级别记录服务端在 trailer 中返回的整个流的处理耗时和收发消息数，旧版本服务端不返回时不记录
+func (c *GRPCClient) logStreamTrailer(ctx context.Context, operation string, trailer metadata.MD) {
+	serverDuration, ok := stats.ServerDuration(trailer)
+	if !ok {
+		return
+	}
+	fields := map[string]interface{}{
+		"operation":       operation,
+		"server_duration": serverDuration.String(),
+	}
+	if sent, received, ok := stats.ServerMessages(trailer); ok {
+		fields["server_messages_sent"] = sent
+		fields["server_messages_received"] = received
+	}
+	c.slogger.DebugContext(ctx, "流已结束", fields)
+}
+
+// WithoutCompression 返回关闭本次调用压缩的调用选项，覆盖客户端配置的默认压缩器
+// 适用于图片、压缩包等已压缩的载荷，避免无意义的 CPU 开销
+func WithoutCompression() grpc.CallOption {
+	return grpc.UseCompressor(encoding.Identity)
+}
+
+// prepareStream 为流式调用选择连接并准备 context
+// 返回的 context 携带请求 ID，客户端关闭时会被取消；调用方必须在流结束后调用返回的 cancel，关闭时据此等待流排空
+func (c *GRPCClient) prepareStream(parent context.Context) (greeterClient, context.Context, context.CancelFunc, error) {
+	if c.IsShutting() {
+		return nil, nil, nil, ErrClientShutting
+	}
+	if err := c.waitRateLimit(parent); err != nil {
+		return nil, nil, nil, err
+	}
+	_, greeter, err := c.readyConn()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	c.inflight.begin()
+	ctx, cancel := context.WithCancel(parent)
+	stop := context.AfterFunc(c.runContext(), cancel)
+	return greeter, c.withRequestID(ctx), func() {
+		stop()
+		cancel()
+		c.inflight.end()
+	}, nil
+}
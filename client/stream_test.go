@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"srpc/client/mocks"
+	pb "srpc/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// endlessStream 永不结束的 GetStream 响应流，Recv 立即返回下一条消息并计数，模拟比消费方快得多的服务端
+type endlessStream struct {
+	grpc.ClientStream
+	ctx   context.Context
+	recvs atomic.Int64
+}
+
+// Recv 返回下一条消息，ctx 结束后返回对应的状态错误
+func (s *endlessStream) Recv() (*pb.StreamResData, error) {
+	if err := s.ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+	n := s.recvs.Add(1)
+	return &pb.StreamResData{Data: strconv.FormatInt(n, 10)}, nil
+}
+
+// endlessGreeter 返回 GetStream 使用 endlessStream 的 mock，streams 接收每次调用创建的流
+func endlessGreeter(streams chan<- *endlessStream) *mocks.Greeter {
+	return &mocks.Greeter{GetStreamFunc: func(ctx context.Context, _ *pb.StreamReqData) (grpc.ServerStreamingClient[pb.StreamResData], error) {
+		s := &endlessStream{ctx: ctx}
+		streams <- s
+		return s, nil
+	}}
+}
+
+func TestCallGetStreamChanBoundsSlowConsumer(t *testing.T) {
+	for _, bufferSize := range []int{0, 8} {
+		streams := make(chan *endlessStream, 1)
+		c := newMockClient(t, mockConfig(), endlessGreeter(streams))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		out, errc := c.CallGetStreamChan(ctx, "slow", bufferSize)
+		stream := <-streams
+
+		// 消费方远慢于服务端：每条消息处理 5ms，已从流中读取的消息数始终不超过已消费数加缓冲区
+		// （后台 goroutine 还可能持有一条正在发送的消息）
+		for consumed := int64(1); consumed <= 20; consumed++ {
+			resp := <-out
+			if want := strconv.FormatInt(consumed, 10); resp.GetData() != want {
+				t.Fatalf("buffer=%d 第 %d 条消息为 %q，期望按顺序收到 %q", bufferSize, consumed, resp.GetData(), want)
+			}
+			time.Sleep(5 * time.Millisecond)
+			if recvs, limit := stream.recvs.Load(), consumed+int64(bufferSize)+1; recvs > limit {
+				t.Fatalf("buffer=%d 消费 %d 条时已读取 %d 条，超过上限 %d", bufferSize, consumed, recvs, limit)
+			}
+		}
+
+		// 放弃消费后取消 ctx，后台 goroutine 退出并关闭响应 channel
+		cancel()
+		select {
+		case err := <-errc:
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("buffer=%d 取消后错误 = %v，期望 context.Canceled", bufferSize, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("buffer=%d 取消后后台 goroutine 未退出", bufferSize)
+		}
+		for range out {
+		}
+	}
+}
+
+func TestCallGetStreamChanReleasesSlotOnCancel(t *testing.T) {
+	streams := make(chan *endlessStream, 2)
+	cfg := mockConfig()
+	cfg.MaxConcurrentRequests = defaultStreamRequestWeight
+	c := newMockClient(t, cfg, endlessGreeter(streams))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, errc := c.CallGetStreamChan(ctx, "abandoned", 1)
+	<-streams
+	waitFor(t, 5*time.Second, "流占用并发许可", func() bool { return c.semaphore.InUse() == defaultStreamRequestWeight })
+
+	// 消费方不再读取：取消后许可归还，新的流可以开始
+	cancel()
+	<-errc
+	if got := c.semaphore.InUse(); got != 0 {
+		t.Fatalf("取消后仍占用 %d 个许可", got)
+	}
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	out, _ := c.CallGetStreamChan(ctx2, "next", 1)
+	if resp := <-out; resp.GetData() != "1" {
+		t.Errorf("新流的第一条消息 = %q", resp.GetData())
+	}
+}
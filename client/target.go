@@ -0,0 +1,40 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// unixSocketPath 解析 unix:///path/to.sock 或 unix:path 形式的目标地址，非 unix 地址返回 false
+// unix-abstract: 抽象命名空间地址没有对应的文件，不在此处理
+func unixSocketPath(target string) (string, bool) {
+	switch {
+	case strings.HasPrefix(target, "unix://"):
+		return strings.TrimPrefix(target, "unix://"), true
+	case strings.HasPrefix(target, "unix:"):
+		return strings.TrimPrefix(target, "unix:"), true
+	default:
+		return "", false
+	}
+}
+
+// checkTarget 在拨号前检查目标地址，unix socket 文件不存在时给出明确的错误，而不是等待拨号超时
+func checkTarget(target string) error {
+	path, ok := unixSocketPath(target)
+	if !ok {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("unix socket 文件不存在: %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("无法访问 unix socket %s: %v", path, err)
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s 不是 socket 文件", path)
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+package client
+
+// 构建信息，通过 -ldflags 注入：
+//
+//	go build -ldflags "-X srpc/client.Version=v1.2.0 -X srpc/client.GitCommit=$(git rev-parse --short HEAD)"
+var (
+	Version   = "dev"
+	GitCommit = "unknown"
+)
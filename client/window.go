@@ -0,0 +1,89 @@
+package client
+
+import (
+	"time"
+
+	"srpc/pkg/tools"
+)
+
+// rollingWindowSeconds 滑动窗口保留的秒数，覆盖最长的 15 分钟窗口
+const rollingWindowSeconds = 15 * 60
+
+// rollingWindows 快照中输出的窗口及其名称
+var rollingWindows = []struct {
+	name string
+	span time.Duration
+}{
+	{"1m", time.Minute},
+	{"5m", 5 * time.Minute},
+	{"15m", 15 * time.Minute},
+}
+
+// windowBucket 一秒内的请求计数
+type windowBucket struct {
+	second   int64 // Unix 秒，用于判断桶是否已过期
+	requests int64
+	failures int64
+}
+
+// rollingWindow 按秒分桶的环形缓冲区，统计最近 15 分钟的请求数和失败数，内存占用固定
+// 桶在下一次写入同一槽位时复用，读取时按桶的时间戳跳过过期数据；非并发安全，由调用方加锁
+type rollingWindow struct {
+	buckets [rollingWindowSeconds]windowBucket
+	clock   tools.Clock
+	start   time.Time // 开始统计的时间，运行时间不足一个窗口时按实际时长计算请求速率
+}
+
+// newRollingWindow 创建滑动窗口
+func newRollingWindow(clock tools.Clock) *rollingWindow {
+	return &rollingWindow{clock: clock, start: clock.Now()}
+}
+
+// observe 记录一次请求结果
+func (w *rollingWindow) observe(success bool) {
+	now := w.clock.Now().Unix()
+	b := &w.buckets[now%rollingWindowSeconds]
+	if b.second != now {
+		*b = windowBucket{second: now}
+	}
+	b.requests++
+	if !success {
+		b.failures++
+	}
+}
+
+// sum 返回最近 span 内（且不早于 since）的请求数和失败数，span 超过 15 分钟时按 15 分钟计算
+func (w *rollingWindow) sum(span time.Duration, since time.Time) (requests, failures int64) {
+	now := w.clock.Now().Unix()
+	oldest := now - int64(min(span, rollingWindowSeconds*time.Second)/time.Second)
+	if s := since.Unix(); !since.IsZero() && s-1 > oldest {
+		// since 所在的一秒可能包含其之前的请求，为避免漏掉之后的请求保留该秒的桶
+		oldest = s - 1
+	}
+	for i := range w.buckets {
+		b := &w.buckets[i]
+		if b.second > oldest && b.second <= now {
+			requests += b.requests
+			failures += b.failures
+		}
+	}
+	return requests, failures
+}
+
+// snapshot 返回 span 内的请求数、成功率、错误率（百分比）和每秒请求数
+func (w *rollingWindow) snapshot(span time.Duration) WindowStats {
+	requests, failures := w.sum(span, time.Time{})
+	var successRate, errorRate float64
+	if requests > 0 {
+		errorRate = float64(failures) / float64(requests) * 100
+		successRate = 100 - errorRate
+	}
+	// 运行时间不足一个窗口时按实际时长计算，避免刚启动时速率偏低
+	elapsed := min(span, w.clock.Now().Sub(w.start).Truncate(time.Second)+time.Second)
+	return WindowStats{
+		Requests:    requests,
+		SuccessRate: successRate,
+		ErrorRate:   errorRate,
+		RPS:         float64(requests) / elapsed.Seconds(),
+	}
+}
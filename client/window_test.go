@@ -0,0 +1,128 @@
+package client
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"srpc/pkg/tools"
+)
+
+// observeN 在当前秒内记录 requests 次请求，其中 failures 次失败
+func observeN(w *rollingWindow, requests, failures int) {
+	for i := range requests {
+		w.observe(i >= failures)
+	}
+}
+
+func TestRollingWindowRollover(t *testing.T) {
+	clock := tools.NewFakeClock(time.Unix(1_750_000_000, 0))
+	w := newRollingWindow(clock)
+
+	observeN(w, 10, 5)
+	clock.Advance(30 * time.Second)
+	observeN(w, 10, 0)
+
+	if got := w.snapshot(time.Minute); got.Requests != 20 || got.ErrorRate != 25 || got.SuccessRate != 75 {
+		t.Errorf("1m 窗口 = %+v，期望 20 次请求、错误率 25%%", got)
+	}
+
+	// 第一批请求滑出 1m 窗口，但仍在 5m 窗口内
+	clock.Advance(31 * time.Second)
+	if got := w.snapshot(time.Minute); got.Requests != 10 || got.ErrorRate != 0 {
+		t.Errorf("61s 后 1m 窗口 = %+v，期望只剩第二批 10 次成功", got)
+	}
+	if got := w.snapshot(5 * time.Minute); got.Requests != 20 || got.ErrorRate != 25 {
+		t.Errorf("61s 后 5m 窗口 = %+v，期望 20 次请求", got)
+	}
+
+	// 15 分钟后全部过期
+	clock.Advance(15 * time.Minute)
+	for _, span := range []time.Duration{time.Minute, 5 * time.Minute, 15 * time.Minute} {
+		if got := w.snapshot(span); got.Requests != 0 || got.ErrorRate != 0 || got.SuccessRate != 0 {
+			t.Errorf("15m 后 %v 窗口 = %+v，期望为空", span, got)
+		}
+	}
+}
+
+func TestRollingWindowReusesExpiredBucket(t *testing.T) {
+	clock := tools.NewFakeClock(time.Unix(1_750_000_000, 0))
+	w := newRollingWindow(clock)
+
+	observeN(w, 7, 7)
+	// 恰好绕环一圈，写入同一槽位时应丢弃过期的计数
+	clock.Advance(rollingWindowSeconds * time.Second)
+	observeN(w, 3, 0)
+	if got := w.snapshot(15 * time.Minute); got.Requests != 3 || got.ErrorRate != 0 {
+		t.Errorf("复用槽位后 15m 窗口 = %+v，期望只有 3 次成功", got)
+	}
+}
+
+func TestRollingWindowRPS(t *testing.T) {
+	clock := tools.NewFakeClock(time.Unix(1_750_000_000, 0))
+	w := newRollingWindow(clock)
+
+	// 运行不足一个窗口时按实际时长计算：10 秒内每秒 6 次
+	for range 10 {
+		observeN(w, 6, 0)
+		clock.Advance(time.Second)
+	}
+	clock.Advance(-time.Second)
+	if got := w.snapshot(time.Minute).RPS; math.Abs(got-6) > 1e-9 {
+		t.Errorf("10 秒后 1m RPS = %v，期望 6", got)
+	}
+
+	// 运行超过窗口后按窗口时长计算
+	clock.Advance(5 * time.Minute)
+	observeN(w, 120, 0)
+	if got := w.snapshot(time.Minute).RPS; math.Abs(got-2) > 1e-9 {
+		t.Errorf("1m RPS = %v，期望 120/60 = 2", got)
+	}
+}
+
+func TestRollingWindowSumSince(t *testing.T) {
+	clock := tools.NewFakeClock(time.Unix(1_750_000_000, 0))
+	w := newRollingWindow(clock)
+
+	observeN(w, 10, 10)
+	clock.Advance(10 * time.Second)
+	since := clock.Now()
+	clock.Advance(time.Second)
+	observeN(w, 4, 1)
+
+	// since 之前的失败不计入，例如熔断器半开后只看之后的请求
+	if requests, failures := w.sum(time.Minute, since); requests != 4 || failures != 1 {
+		t.Errorf("sum(since) = %d/%d，期望 4 次请求 1 次失败", requests, failures)
+	}
+	if requests, failures := w.sum(time.Minute, time.Time{}); requests != 14 || failures != 11 {
+		t.Errorf("sum = %d/%d，期望 14 次请求 11 次失败", requests, failures)
+	}
+}
+
+func TestMetricsWindowsInSnapshot(t *testing.T) {
+	clock := tools.NewFakeClock(time.Unix(1_750_000_000, 0))
+	m := NewMetrics()
+	m.setClock(clock)
+
+	for i := range 4 {
+		var err error
+		if i == 0 {
+			err = errors.New("失败")
+		}
+		m.RecordRequest(err, time.Millisecond)
+	}
+	clock.Advance(2 * time.Minute)
+	m.RecordRequest(nil, time.Millisecond)
+
+	snap := m.GetMetrics()
+	if got := snap.Windows["1m"]; got.Requests != 1 || got.ErrorRate != 0 {
+		t.Errorf("1m 窗口 = %+v，期望 1 次成功", got)
+	}
+	if got := snap.Windows["5m"]; got.Requests != 5 || got.ErrorRate != 20 {
+		t.Errorf("5m 窗口 = %+v，期望 5 次请求、错误率 20%%", got)
+	}
+	if got, ok := snap.Windows["15m"]; !ok || got.Requests != 5 {
+		t.Errorf("15m 窗口 = %+v，期望 5 次请求", got)
+	}
+}
@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// 默认的签名 metadata 键和时间窗口
+const (
+	DefaultSignatureHeader = "x-srpc-signature"
+	DefaultTimestampHeader = "x-srpc-timestamp"
+	DefaultMaxClockSkew    = 5 * time.Minute
+)
+
+// 签名校验失败的原因，服务端据此返回 Unauthenticated
+var (
+	ErrMissingSignature = errors.New("缺少请求签名")
+	ErrInvalidSignature = errors.New("请求签名不匹配")
+	ErrStaleTimestamp   = errors.New("请求时间戳超出允许的时间窗口")
+)
+
+// HMACConfig 基于共享密钥的请求签名配置，客户端和服务端需使用相同的密钥和 metadata 键
+// 签名内容为 "方法名\n毫秒时间戳\n请求体 SHA-256"，流式 RPC 只在建立时签名一次，请求体为空
+type HMACConfig struct {
+	Secret          []byte        // 共享密钥，为空表示不启用签名
+	SignatureHeader string        // 携带签名的 metadata 键，为空时使用 DefaultSignatureHeader
+	TimestampHeader string        // 携带时间戳的 metadata 键，为空时使用 DefaultTimestampHeader
+	MaxClockSkew    time.Duration // 服务端接受的时间戳与本地时间的最大偏差，同时是重放窗口；为 0 时使用 DefaultMaxClockSkew
+}
+
+// Enabled 是否配置了共享密钥
+func (c HMACConfig) Enabled() bool {
+	return len(c.Secret) > 0
+}
+
+// WithDefaults 返回填充了默认 metadata 键和时间窗口的配置
+func (c HMACConfig) WithDefaults() HMACConfig {
+	if c.SignatureHeader == "" {
+		c.SignatureHeader = DefaultSignatureHeader
+	}
+	if c.TimestampHeader == "" {
+		c.TimestampHeader = DefaultTimestampHeader
+	}
+	if c.MaxClockSkew <= 0 {
+		c.MaxClockSkew = DefaultMaxClockSkew
+	}
+	return c
+}
+
+// Sign 计算请求签名，返回十六进制编码的 HMAC-SHA256
+func (c HMACConfig) Sign(method string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, c.Secret)
+	digest := sha256.Sum256(body)
+	fmt.Fprintf(mac, "%s\n%d\n%s", method, timestamp, hex.EncodeToString(digest[:]))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify 校验签名和时间戳，timestamp 和 signature 为 metadata 中的原始值
+// 时间戳与 now 相差超过 MaxClockSkew 时返回 ErrStaleTimestamp，窗口外的重放请求因此被拒绝
+func (c HMACConfig) Verify(method, timestamp, signature string, body []byte, now time.Time) error {
+	if timestamp == "" || signature == "" {
+		return ErrMissingSignature
+	}
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: 时间戳格式无效", ErrInvalidSignature)
+	}
+	if skew := now.Sub(time.UnixMilli(ts)).Abs(); skew > c.MaxClockSkew {
+		return fmt.Errorf("%w: 偏差 %s", ErrStaleTimestamp, skew.Round(time.Millisecond))
+	}
+
+	expected, err := hex.DecodeString(c.Sign(method, ts, body))
+	if err != nil {
+		return err
+	}
+	got, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(got, expected) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// MessageBody 返回用于签名的请求体：proto 消息的确定性序列化结果，非 proto 消息返回 nil
+// 客户端和服务端对同一条消息得到相同的字节，未知字段也会被保留
+func MessageBody(msg any) ([]byte, error) {
+	m, ok := msg.(proto.Message)
+	if !ok || m == nil {
+		return nil, nil
+	}
+	return proto.MarshalOptions{Deterministic: true}.Marshal(m)
+}
@@ -0,0 +1,126 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Config 故障注入配置，零值表示不注入任何故障
+type Config struct {
+	ErrorRate     float64       // 注入错误的概率（0-1）
+	Codes         []codes.Code  // 注入的 gRPC 状态码，每次随机选取一个；为空时使用 Unavailable
+	Latency       time.Duration // 每次调用前增加的固定延迟
+	LatencyJitter time.Duration // 在固定延迟之上额外增加 [0, LatencyJitter] 内均匀分布的随机延迟
+}
+
+// Enabled 是否配置了任何故障注入
+func (c Config) Enabled() bool {
+	return c.ErrorRate > 0 || c.Latency > 0 || c.LatencyJitter > 0
+}
+
+// Delay 返回本次调用需要注入的延迟
+func (c Config) Delay() time.Duration {
+	d := c.Latency
+	if c.LatencyJitter > 0 {
+		d += time.Duration(rand.Int64N(int64(c.LatencyJitter) + 1))
+	}
+	return d
+}
+
+// Error 按 ErrorRate 掷骰决定是否注入错误，返回注入的错误或 nil
+func (c Config) Error() error {
+	if c.ErrorRate <= 0 || rand.Float64() >= c.ErrorRate {
+		return nil
+	}
+	code := codes.Unavailable
+	if len(c.Codes) > 0 {
+		code = c.Codes[rand.IntN(len(c.Codes))]
+	}
+	return InjectedError(code)
+}
+
+// Inject 先等待注入的延迟（ctx 取消时提前返回 ctx 的错误），再按概率返回注入的错误
+func (c Config) Inject(ctx context.Context) error {
+	if err := Sleep(ctx, c.Delay()); err != nil {
+		return err
+	}
+	return c.Error()
+}
+
+// InjectedError 返回带有故障注入标记的状态错误，便于在日志中与真实错误区分
+func InjectedError(code codes.Code) error {
+	return status.Errorf(code, "chaos: 故障注入 (%s)", code)
+}
+
+// Sleep 等待 d，ctx 先结束时返回对应的 gRPC 状态错误
+func Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return status.FromContextError(ctx.Err()).Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// ParseCodes 解析逗号分隔的状态码列表，支持名称（UNAVAILABLE、Internal）和数字（14）
+func ParseCodes(s string) ([]codes.Code, error) {
+	var result []codes.Code
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		code, err := ParseCode(item)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, code)
+	}
+	return result, nil
+}
+
+// ParseCode 解析单个状态码，支持名称（UNAVAILABLE、Internal）和数字（14）
+func ParseCode(s string) (codes.Code, error) {
+	if n, err := strconv.ParseUint(s, 10, 32); err == nil {
+		if n > uint64(codes.Unauthenticated) {
+			return 0, fmt.Errorf("无效的 gRPC 状态码: %s", s)
+		}
+		return codes.Code(n), nil
+	}
+	name := toUpperSnake(s)
+	if name == "CANCELED" {
+		name = "CANCELLED" // gRPC 的 JSON 名称沿用英式拼写
+	}
+	var code codes.Code
+	if err := code.UnmarshalJSON([]byte(strconv.Quote(name))); err != nil {
+		return 0, fmt.Errorf("无效的 gRPC 状态码: %s", s)
+	}
+	return code, nil
+}
+
+// toUpperSnake 将 DeadlineExceeded 形式的名称转换为 DEADLINE_EXCEEDED，已是大写下划线形式的保持不变
+func toUpperSnake(s string) string {
+	if strings.ToUpper(s) == s {
+		return s
+	}
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToUpper(b.String())
+}
@@ -0,0 +1,78 @@
+package compress
+
+import (
+	"compress/flate"
+	"fmt"
+	"io"
+	"sync"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// deflateCompressor 实现 gRPC 的 Compressor 接口，用于与只支持 deflate 的对端互通
+type deflateCompressor struct {
+	mu      sync.RWMutex
+	level   int
+	writers sync.Pool // 复用 flate.Writer，避免每条消息分配较大的压缩状态
+}
+
+// DeflateCompressor 是 deflate 压缩器的单例实例
+var DeflateCompressor = &deflateCompressor{level: flate.DefaultCompression}
+
+func init() {
+	// 注册 deflate 压缩器到 gRPC
+	encoding.RegisterCompressor(DeflateCompressor)
+}
+
+// SetDeflateLevel 设置 deflate 压缩级别，取值范围与 compress/flate 一致（-2 到 9）
+// 只影响发送方向，接收方向无论对端使用何种级别都能解压
+func SetDeflateLevel(level int) error {
+	if level < flate.HuffmanOnly || level > flate.BestCompression {
+		return fmt.Errorf("无效的 deflate 压缩级别: %d", level)
+	}
+	DeflateCompressor.mu.Lock()
+	defer DeflateCompressor.mu.Unlock()
+	DeflateCompressor.level = level
+	return nil
+}
+
+// deflateWriter 在 Close 时将 flate.Writer 放回池中
+type deflateWriter struct {
+	*flate.Writer
+	level int
+	pool  *sync.Pool
+}
+
+// Close 刷新剩余数据并归还 writer
+func (w *deflateWriter) Close() error {
+	err := w.Writer.Close()
+	w.pool.Put(w)
+	return err
+}
+
+// Compress 返回一个 deflate 压缩的 WriteCloser
+func (d *deflateCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	// 压缩级别变更后，丢弃按旧级别创建的 writer
+	if dw, ok := d.writers.Get().(*deflateWriter); ok && dw.level == d.level {
+		dw.Reset(w)
+		return dw, nil
+	}
+	fw, err := flate.NewWriter(w, d.level)
+	if err != nil {
+		return nil, err
+	}
+	return &deflateWriter{Writer: fw, level: d.level, pool: &d.writers}, nil
+}
+
+// Decompress 返回一个 deflate 解压缩的 Reader
+func (d *deflateCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	return flate.NewReader(r), nil
+}
+
+// Name 返回压缩器的名称，用于在 gRPC 调用中标识
+func (d *deflateCompressor) Name() string {
+	return "deflate"
+}
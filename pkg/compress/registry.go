@@ -0,0 +1,17 @@
+package compress
+
+import "google.golang.org/grpc/encoding"
+
+// knownCompressors gRPC 未提供枚举已注册压缩器的接口，这里按名称逐一探测
+var knownCompressors = []string{"gzip", "snappy", "deflate", "zstd"}
+
+// Registered 返回当前进程中已注册到 gRPC 的压缩器名称
+func Registered() []string {
+	var names []string
+	for _, name := range knownCompressors {
+		if encoding.GetCompressor(name) != nil {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,82 @@
+package log
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDHeader 请求 ID 在 gRPC metadata 中使用的键
+const RequestIDHeader = "x-request-id"
+
+// IdempotencyKeyHeader 幂等键在 gRPC metadata 中使用的键，同一逻辑请求的所有重试携带相同的值，服务端据此去重
+const IdempotencyKeyHeader = "x-idempotency-key"
+
+// contextKey 日志上下文键类型，避免与其他包的 context 键冲突
+type contextKey string
+
+const (
+	requestIDKey contextKey = "request_id" // 请求 ID
+	methodKey    contextKey = "method"     // RPC 方法名
+)
+
+// WithRequestID 将请求 ID 写入 context，供 *Context 日志方法自动提取
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	if requestID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// WithMethod 将 RPC 方法名写入 context
+func WithMethod(ctx context.Context, method string) context.Context {
+	if method == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, methodKey, method)
+}
+
+// RequestIDFromContext 从 context 中获取请求 ID
+// 优先读取 WithRequestID 写入的值，其次读取服务端收到的 metadata
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if id, ok := ctx.Value(requestIDKey).(string); ok && id != "" {
+		return id
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(RequestIDHeader); len(ids) > 0 {
+			return ids[0]
+		}
+	}
+	return ""
+}
+
+// MethodFromContext 从 context 中获取 RPC 方法名
+// 优先读取 WithMethod 写入的值，其次读取 gRPC 服务端注入的方法名
+func MethodFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if method, ok := ctx.Value(methodKey).(string); ok && method != "" {
+		return method
+	}
+	if method, ok := grpc.Method(ctx); ok {
+		return method
+	}
+	return ""
+}
+
+// contextFields 从 context 中提取约定的日志字段
+func contextFields(ctx context.Context) map[string]interface{} {
+	fields := make(map[string]interface{}, 2)
+	if id := RequestIDFromContext(ctx); id != "" {
+		fields["request_id"] = id
+	}
+	if method := MethodFromContext(ctx); method != "" {
+		fields["method"] = method
+	}
+	return fields
+}
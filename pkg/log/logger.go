@@ -21,6 +21,11 @@ func NewLogger() *Slogger {
 	}
 }
 
+// Debug 记录调试级别日志
+func (l *Slogger) Debug(message string, fields ...map[string]interface{}) {
+	l.log(slog.LevelDebug, message, fields...)
+}
+
 // Info 记录信息级别日志
 func (l *Slogger) Info(message string, fields ...map[string]interface{}) {
 	l.log(slog.LevelInfo, message, fields...)
@@ -36,8 +41,47 @@ func (l *Slogger) Error(message string, fields ...map[string]interface{}) {
 	l.log(slog.LevelError, message, fields...)
 }
 
+// DebugContext 记录调试级别日志，并自动附加 context 中的请求 ID 等字段
+func (l *Slogger) DebugContext(ctx context.Context, message string, fields ...map[string]interface{}) {
+	l.logContext(ctx, slog.LevelDebug, message, fields...)
+}
+
+// InfoContext 记录信息级别日志，并自动附加 context 中的请求 ID 等字段
+func (l *Slogger) InfoContext(ctx context.Context, message string, fields ...map[string]interface{}) {
+	l.logContext(ctx, slog.LevelInfo, message, fields...)
+}
+
+// WarnContext 记录警告级别日志，并自动附加 context 中的请求 ID 等字段
+func (l *Slogger) WarnContext(ctx context.Context, message string, fields ...map[string]interface{}) {
+	l.logContext(ctx, slog.LevelWarn, message, fields...)
+}
+
+// ErrorContext 记录错误级别日志，并自动附加 context 中的请求 ID 等字段
+func (l *Slogger) ErrorContext(ctx context.Context, message string, fields ...map[string]interface{}) {
+	l.logContext(ctx, slog.LevelError, message, fields...)
+}
+
 // log 内部日志记录方法
 func (l *Slogger) log(level slog.Level, message string, fields ...map[string]interface{}) {
+	l.write(context.Background(), level, message, fields...)
+}
+
+// logContext 合并 context 字段后记录日志，调用方显式传入的字段优先
+func (l *Slogger) logContext(ctx context.Context, level slog.Level, message string, fields ...map[string]interface{}) {
+	merged := contextFields(ctx)
+	if len(fields) > 0 && fields[0] != nil {
+		for k, v := range fields[0] {
+			merged[k] = v
+		}
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	l.write(ctx, level, message, merged)
+}
+
+// write 将字段转换为 slog 属性并输出
+func (l *Slogger) write(ctx context.Context, level slog.Level, message string, fields ...map[string]interface{}) {
 	var attrs []any
 	if len(fields) > 0 && fields[0] != nil {
 		// 将字段转换为 slog.Attr
@@ -46,14 +90,5 @@ func (l *Slogger) log(level slog.Level, message string, fields ...map[string]int
 		}
 	}
 
-	switch level {
-	case slog.LevelInfo:
-		l.logger.Info(message, attrs...)
-	case slog.LevelWarn:
-		l.logger.Warn(message, attrs...)
-	case slog.LevelError:
-		l.logger.Error(message, attrs...)
-	default:
-		l.logger.Log(context.Background(), level, message, attrs...)
-	}
+	l.logger.Log(ctx, level, message, attrs...)
 }
@@ -0,0 +1,81 @@
+package stats
+
+import (
+	"context"
+
+	grpcstats "google.golang.org/grpc/stats"
+)
+
+// Direction 消息传输方向
+type Direction int
+
+const (
+	Outbound Direction = iota // 发送
+	Inbound                   // 接收
+)
+
+// Payload 单条消息的传输统计
+type Payload struct {
+	Method           string    // 完整 RPC 方法名，例如 /Greeter/SayHello
+	Direction        Direction // 传输方向
+	Length           int       // 未压缩的消息大小
+	CompressedLength int       // 压缩后的消息大小，未启用压缩时与 Length 相同
+	WireLength       int       // 压缩后的消息大小加上 gRPC 帧头，不含 HTTP/2 帧
+}
+
+// Recorder 接收传输层统计的指标收集器
+type Recorder interface {
+	RecordPayload(p Payload)
+}
+
+// methodKey 在 RPC context 中保存方法名的键
+type methodKey struct{}
+
+// Handler 实现 grpc stats.Handler，把每条消息的字节数上报给 Recorder
+// 客户端通过 grpc.WithStatsHandler 安装，服务端通过 grpc.StatsHandler 安装；
+// 流式 RPC 的每条消息都会单独上报，因此流的总量等于所有消息之和
+type Handler struct {
+	recorder Recorder
+}
+
+// NewHandler 创建新的 stats.Handler
+func NewHandler(recorder Recorder) *Handler {
+	return &Handler{recorder: recorder}
+}
+
+// TagRPC 记录方法名，供 HandleRPC 使用
+func (h *Handler) TagRPC(ctx context.Context, info *grpcstats.RPCTagInfo) context.Context {
+	return context.WithValue(ctx, methodKey{}, info.FullMethodName)
+}
+
+// HandleRPC 处理 RPC 统计事件，只关注消息收发
+func (h *Handler) HandleRPC(ctx context.Context, s grpcstats.RPCStats) {
+	method, _ := ctx.Value(methodKey{}).(string)
+
+	switch s := s.(type) {
+	case *grpcstats.InPayload:
+		h.recorder.RecordPayload(Payload{
+			Method:           method,
+			Direction:        Inbound,
+			Length:           s.Length,
+			CompressedLength: s.CompressedLength,
+			WireLength:       s.WireLength,
+		})
+	case *grpcstats.OutPayload:
+		h.recorder.RecordPayload(Payload{
+			Method:           method,
+			Direction:        Outbound,
+			Length:           s.Length,
+			CompressedLength: s.CompressedLength,
+			WireLength:       s.WireLength,
+		})
+	}
+}
+
+// TagConn 不附加连接级信息
+func (h *Handler) TagConn(ctx context.Context, _ *grpcstats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+// HandleConn 不处理连接级事件
+func (h *Handler) HandleConn(context.Context, grpcstats.ConnStats) {}
@@ -0,0 +1,68 @@
+package stats
+
+// Totals 按方向累计的消息数和字节数，调用方负责加锁
+type Totals struct {
+	MessagesSent          int64
+	MessagesReceived      int64
+	BytesSent             int64 // 发送的线上字节数（WireLength）
+	BytesReceived         int64 // 接收的线上字节数（WireLength）
+	UncompressedBytesSent int64
+	CompressedBytesSent   int64
+	UncompressedBytesRecv int64
+	CompressedBytesRecv   int64
+}
+
+// Add 累加一条消息的统计
+func (t *Totals) Add(p Payload) {
+	switch p.Direction {
+	case Outbound:
+		t.MessagesSent++
+		t.BytesSent += int64(p.WireLength)
+		t.UncompressedBytesSent += int64(p.Length)
+		t.CompressedBytesSent += int64(p.CompressedLength)
+	case Inbound:
+		t.MessagesReceived++
+		t.BytesReceived += int64(p.WireLength)
+		t.UncompressedBytesRecv += int64(p.Length)
+		t.CompressedBytesRecv += int64(p.CompressedLength)
+	}
+}
+
+// Sub 返回相对于较早累计值 prev 的增量，用于计算两次快照之间的传输量
+func (t *Totals) Sub(prev Totals) Totals {
+	return Totals{
+		MessagesSent:          t.MessagesSent - prev.MessagesSent,
+		MessagesReceived:      t.MessagesReceived - prev.MessagesReceived,
+		BytesSent:             t.BytesSent - prev.BytesSent,
+		BytesReceived:         t.BytesReceived - prev.BytesReceived,
+		UncompressedBytesSent: t.UncompressedBytesSent - prev.UncompressedBytesSent,
+		CompressedBytesSent:   t.CompressedBytesSent - prev.CompressedBytesSent,
+		UncompressedBytesRecv: t.UncompressedBytesRecv - prev.UncompressedBytesRecv,
+		CompressedBytesRecv:   t.CompressedBytesRecv - prev.CompressedBytesRecv,
+	}
+}
+
+// CompressionRatio 返回压缩后字节数与压缩前字节数之比（收发合计），越小说明压缩越有效
+// 未启用压缩时约为 1，尚无消息时返回 0
+func (t *Totals) CompressionRatio() float64 {
+	uncompressed := t.UncompressedBytesSent + t.UncompressedBytesRecv
+	if uncompressed == 0 {
+		return 0
+	}
+	return float64(t.CompressedBytesSent+t.CompressedBytesRecv) / float64(uncompressed)
+}
+
+// Map 转换为便于日志输出的字段
+func (t *Totals) Map() map[string]interface{} {
+	return map[string]interface{}{
+		"messages_sent":           t.MessagesSent,
+		"messages_received":       t.MessagesReceived,
+		"bytes_sent":              t.BytesSent,
+		"bytes_received":          t.BytesReceived,
+		"uncompressed_bytes_sent": t.UncompressedBytesSent,
+		"compressed_bytes_sent":   t.CompressedBytesSent,
+		"uncompressed_bytes_recv": t.UncompressedBytesRecv,
+		"compressed_bytes_recv":   t.CompressedBytesRecv,
+		"compression_ratio":       t.CompressionRatio(),
+	}
+}
@@ -0,0 +1,57 @@
+package stats
+
+import (
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// ServerInstanceHeader 服务端在响应 header 中返回的实例 ID，便于在负载均衡后确认由哪个副本处理
+const ServerInstanceHeader = "x-server-instance"
+
+// ServerInstance 从响应 header 中读取服务端实例 ID，未携带时返回空字符串
+func ServerInstance(md metadata.MD) string {
+	if values := md.Get(ServerInstanceHeader); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// 服务端在 trailer 中返回的处理统计，客户端据此区分服务端处理耗时和网络开销
+const (
+	ServerDurationTrailer         = "x-server-duration-us"       // 服务端处理耗时（微秒），流为整个流的耗时
+	ServerMessagesSentTrailer     = "x-server-messages-sent"     // 流中服务端发送的消息数
+	ServerMessagesReceivedTrailer = "x-server-messages-received" // 流中服务端接收的消息数
+)
+
+// DurationTrailer 返回携带处理耗时的 trailer
+func DurationTrailer(d time.Duration) metadata.MD {
+	return metadata.Pairs(ServerDurationTrailer, strconv.FormatInt(d.Microseconds(), 10))
+}
+
+// ServerDuration 从 trailer 中读取服务端处理耗时，未携带或格式无效（例如旧版本服务端）时返回 false
+func ServerDuration(md metadata.MD) (time.Duration, bool) {
+	n, ok := trailerInt(md, ServerDurationTrailer)
+	return time.Duration(n) * time.Microsecond, ok
+}
+
+// ServerMessages 从流的 trailer 中读取服务端发送和接收的消息数，未携带时返回 false
+func ServerMessages(md metadata.MD) (sent, received int64, ok bool) {
+	sent, okSent := trailerInt(md, ServerMessagesSentTrailer)
+	received, okRecv := trailerInt(md, ServerMessagesReceivedTrailer)
+	return sent, received, okSent && okRecv
+}
+
+// trailerInt 读取 trailer 中的非负整数
+func trailerInt(md metadata.MD, key string) (int64, bool) {
+	values := md.Get(key)
+	if len(values) == 0 {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(values[0], 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,101 @@
+package testutil_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"srpc/client"
+	"srpc/pkg/testutil"
+	"srpc/pkg/tools"
+	pb "srpc/proto"
+	"srpc/server"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toggleSayHello 返回 failing 为 true 时让 SayHello 返回 Unavailable 的服务端拦截器，calls 统计到达服务端的调用
+func toggleSayHello(failing *atomic.Bool, calls *atomic.Int64) server.Option {
+	return server.WithUnaryInterceptors(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if info.FullMethod != pb.Greeter_SayHello_FullMethodName {
+			return handler(ctx, req)
+		}
+		calls.Add(1)
+		if failing.Load() {
+			return nil, status.Error(codes.Unavailable, "服务端暂时不可用")
+		}
+		return handler(ctx, req)
+	})
+}
+
+// sendNow 调用一次 SayHello 并返回错误
+func sendNow(t *testing.T, c *client.GRPCClient) error {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := c.SendNow(ctx, "breaker")
+	return err
+}
+
+func TestCircuitBreakerTripsAndRecovers(t *testing.T) {
+	t.Parallel()
+	var failing atomic.Bool
+	var calls atomic.Int64
+	failing.Store(true)
+	ts := testutil.StartTestServer(t, toggleSayHello(&failing, &calls))
+
+	// 熔断器的开启时长由假时钟控制，不需要真实等待 30 秒
+	clock := tools.NewFakeClock(time.Now())
+	cfg := ts.ClientConfig()
+	cfg.Clock = clock
+	cfg.MaxRetries = 0
+	c := testutil.NewClient(t, cfg)
+
+	// 连续 5 次失败后开启
+	for i := 1; i <= 5; i++ {
+		if err := sendNow(t, c); status.Code(err) != codes.Unavailable {
+			t.Fatalf("第 %d 次请求错误 = %v，期望 Unavailable", i, err)
+		}
+	}
+	if got := c.CircuitBreakerState(); got != client.CBStateOpen {
+		t.Fatalf("连续 5 次失败后熔断器状态 = %v，期望开启", got)
+	}
+
+	// 开启期间请求被直接拒绝，不到达服务端
+	before := calls.Load()
+	if err := sendNow(t, c); !errors.Is(err, client.ErrCircuitOpen) {
+		t.Fatalf("熔断器开启时错误 = %v，期望 ErrCircuitOpen", err)
+	}
+	if got := calls.Load(); got != before {
+		t.Errorf("熔断器开启时服务端仍收到 %d 次调用", got-before)
+	}
+
+	// 开启时长结束后进入半开，半开状态下的失败重新开启
+	clock.Advance(30 * time.Second)
+	if err := sendNow(t, c); status.Code(err) != codes.Unavailable {
+		t.Fatalf("半开状态的试探请求错误 = %v，期望到达服务端并返回 Unavailable", err)
+	}
+	if got := c.CircuitBreakerState(); got != client.CBStateOpen {
+		t.Fatalf("半开状态失败后熔断器状态 = %v，期望重新开启", got)
+	}
+
+	// 服务端恢复后，半开状态下连续 3 次成功关闭熔断器
+	failing.Store(false)
+	clock.Advance(30 * time.Second)
+	for i := 1; i <= 3; i++ {
+		if err := sendNow(t, c); err != nil {
+			t.Fatalf("恢复后第 %d 次请求失败: %v", i, err)
+		}
+		want := client.CBStateHalfOpen
+		if i == 3 {
+			want = client.CBStateClosed
+		}
+		if got := c.CircuitBreakerState(); got != want {
+			t.Errorf("恢复后第 %d 次成功后熔断器状态 = %v，期望 %v", i, got, want)
+		}
+	}
+}
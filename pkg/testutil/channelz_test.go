@@ -0,0 +1,106 @@
+package testutil_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"srpc/pkg/testutil"
+	"srpc/server"
+
+	"google.golang.org/grpc"
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// topChannelTargets 通过 channelz 服务列出进程内所有顶层通道的目标地址
+func topChannelTargets(ctx context.Context, t *testing.T, cz channelzpb.ChannelzClient) map[string]bool {
+	t.Helper()
+	targets := make(map[string]bool)
+	var start int64
+	for {
+		resp, err := cz.GetTopChannels(ctx, &channelzpb.GetTopChannelsRequest{StartChannelId: start})
+		if err != nil {
+			t.Fatalf("GetTopChannels 失败: %v", err)
+		}
+		for _, ch := range resp.GetChannel() {
+			targets[ch.GetData().GetTarget()] = true
+			start = ch.GetRef().GetChannelId() + 1
+		}
+		if resp.GetEnd() || len(resp.GetChannel()) == 0 {
+			return targets
+		}
+	}
+}
+
+func TestServerChannelzListsClientChannel(t *testing.T) {
+	t.Parallel()
+	cfg := server.DefaultConfig()
+	cfg.EnableChannelz = true
+	ts := testutil.StartTestServerWithConfig(t, cfg)
+	c := testutil.NewClient(t, ts.ClientConfig())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := c.SendNow(ctx, "channelz"); err != nil {
+		t.Fatalf("SendNow 失败: %v", err)
+	}
+
+	// channelz 数据在进程内全局收集，客户端的通道同样可以从服务端注册的 channelz 服务查到
+	cz := channelzpb.NewChannelzClient(ts.Conn(t))
+	if targets := topChannelTargets(ctx, t, cz); !targets[testutil.Target] {
+		t.Errorf("顶层通道中没有目标为 %s 的客户端通道: %v", testutil.Target, targets)
+	}
+
+	servers, err := cz.GetServers(ctx, &channelzpb.GetServersRequest{})
+	if err != nil {
+		t.Fatalf("GetServers 失败: %v", err)
+	}
+	var started int64
+	for _, s := range servers.GetServer() {
+		started += s.GetData().GetCallsStarted()
+	}
+	if started == 0 {
+		t.Error("channelz 中服务端的 calls_started 均为 0")
+	}
+}
+
+func TestClientChannelzServer(t *testing.T) {
+	t.Parallel()
+	ts := testutil.StartTestServer(t)
+
+	// 占用随机端口后释放，作为客户端 channelz 服务的监听地址
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	addr := lis.Addr().String()
+	lis.Close()
+
+	cfg := ts.ClientConfig()
+	cfg.ChannelzAddr = addr
+	c := testutil.NewClient(t, cfg)
+	done := startRun(c)
+	t.Cleanup(func() {
+		c.Shutdown()
+		<-done
+	})
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("连接 channelz 服务失败: %v", err)
+	}
+	defer conn.Close()
+	cz := channelzpb.NewChannelzClient(conn)
+
+	// channelz 服务随 Run 启动，WaitForReady 等待监听就绪
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := cz.GetTopChannels(ctx, &channelzpb.GetTopChannelsRequest{}, grpc.WaitForReady(true)); err != nil {
+		t.Fatalf("客户端 channelz 服务不可用: %v", err)
+	}
+	if targets := topChannelTargets(ctx, t, cz); !targets[testutil.Target] {
+		t.Errorf("顶层通道中没有目标为 %s 的客户端通道: %v", testutil.Target, targets)
+	}
+}
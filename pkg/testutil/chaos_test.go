@@ -0,0 +1,143 @@
+package testutil_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"srpc/client"
+	"srpc/pkg/testutil"
+	"srpc/server"
+
+	"google.golang.org/grpc/codes"
+)
+
+// startInjectServer 启动允许按请求头注入故障的测试服务端
+func startInjectServer(t *testing.T) *testutil.TestServer {
+	cfg := server.DefaultConfig()
+	cfg.AllowInjectHeaders = true
+	return testutil.StartTestServerWithConfig(t, cfg)
+}
+
+// injectedErrors 返回服务端注入的错误数
+func injectedErrors(ts *testutil.TestServer) int64 {
+	return ts.Server.Metrics().GetMetrics()["injected_errors"].(int64)
+}
+
+func TestInjectFailCountRecoversThroughRetry(t *testing.T) {
+	t.Parallel()
+	ts := startInjectServer(t)
+	cfg := fastRetryConfig(ts, 3)
+	cfg.GenerateRequestID = true
+	cfg.Metadata = map[string]string{server.InjectFailCountHeader: "2"}
+	c := testutil.NewClient(t, cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// 每次 SendNow 使用新的请求 ID，都应走完失败两次、第三次尝试成功的路径
+	for i := 0; i < 2; i++ {
+		if _, err := c.SendNow(ctx, "fail-count"); err != nil {
+			t.Fatalf("第 %d 次 SendNow 失败: %v", i+1, err)
+		}
+	}
+	snap := c.GetMetrics()
+	if snap.TotalRetries != 4 {
+		t.Errorf("TotalRetries = %d，期望 4", snap.TotalRetries)
+	}
+	if got := snap.ErrorsByCode[codes.Unavailable.String()]; got != 4 {
+		t.Errorf("ErrorsByCode[Unavailable] = %d，期望 4", got)
+	}
+	if got := injectedErrors(ts); got != 4 {
+		t.Errorf("服务端 injected_errors = %d，期望 4", got)
+	}
+}
+
+func TestInjectFailCountExhaustsRetries(t *testing.T) {
+	t.Parallel()
+	ts := startInjectServer(t)
+	cfg := fastRetryConfig(ts, 1)
+	cfg.GenerateRequestID = true
+	cfg.Metadata = map[string]string{
+		server.InjectFailCountHeader: "2",
+		server.InjectErrorHeader:     "RESOURCE_EXHAUSTED",
+	}
+	c := testutil.NewClient(t, cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := c.SendNow(ctx, "fail-count")
+	if !errors.Is(err, client.ErrResourceExhausted) {
+		t.Fatalf("错误 = %v，期望 ErrResourceExhausted 类别", err)
+	}
+	var rpcErr *client.RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Attempts != 2 {
+		t.Errorf("错误 = %#v，期望 RPCError.Attempts = 2", err)
+	}
+}
+
+func TestInjectFailCountIgnoredWithoutRequestID(t *testing.T) {
+	t.Parallel()
+	ts := startInjectServer(t)
+	cfg := fastRetryConfig(ts, 3)
+	cfg.Metadata = map[string]string{server.InjectFailCountHeader: "2"}
+	c := testutil.NewClient(t, cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for i := 0; i < 3; i++ {
+		if _, err := c.SendNow(ctx, "no-request-id"); err != nil {
+			t.Fatalf("第 %d 次 SendNow 失败: %v", i+1, err)
+		}
+	}
+	if got := c.GetMetrics().TotalRetries; got != 0 {
+		t.Errorf("TotalRetries = %d，没有请求 ID 时不应注入失败", got)
+	}
+	if got := injectedErrors(ts); got != 0 {
+		t.Errorf("服务端 injected_errors = %d，期望 0", got)
+	}
+}
+
+func TestClientChaosTripsCircuitBreaker(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int64
+	ts := testutil.StartTestServer(t, failSayHello(0, codes.OK, &calls))
+	cfg := fastRetryConfig(ts, 0)
+	cfg.DisableAutoRequests = true
+	cfg.ChaosErrorRate = 0.5
+	cfg.CircuitBreakerErrorRate = 0.3
+	cfg.CircuitBreakerMinRequests = 10
+	c := testutil.NewClient(t, cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	// 一半请求被注入 Unavailable，错误率远超阈值，熔断器应在有限次请求内开启
+	var sent int
+	for sent = 0; sent < 200 && c.CircuitBreakerState() != client.CBStateOpen; sent++ {
+		_, err := c.SendNow(ctx, "chaos")
+		if err != nil && !errors.Is(err, client.ErrUnavailable) && !errors.Is(err, client.ErrCircuitOpen) {
+			t.Fatalf("第 %d 次 SendNow 错误 = %v，期望注入的 Unavailable", sent+1, err)
+		}
+	}
+	if got := c.CircuitBreakerState(); got != client.CBStateOpen {
+		t.Fatalf("%d 次请求后熔断器状态 = %v，期望开启", sent, got)
+	}
+
+	snap := c.GetMetrics()
+	if snap.FailedRequests == 0 || snap.SuccessfulRequests == 0 {
+		t.Errorf("成功 %d 次、失败 %d 次，期望注入后两者都有", snap.SuccessfulRequests, snap.FailedRequests)
+	}
+	if got := calls.Load(); got != snap.SuccessfulRequests {
+		t.Errorf("服务端收到 %d 次调用，期望只有未注入错误的 %d 次到达服务端", got, snap.SuccessfulRequests)
+	}
+
+	// 熔断期间的请求在本地被拒绝，不再到达服务端
+	before := calls.Load()
+	if _, err := c.SendNow(ctx, "open"); !errors.Is(err, client.ErrCircuitOpen) {
+		t.Errorf("熔断开启后 SendNow 错误 = %v，期望 ErrCircuitOpen", err)
+	}
+	if got := calls.Load(); got != before {
+		t.Errorf("熔断开启后服务端仍收到 %d 次调用", got-before)
+	}
+}
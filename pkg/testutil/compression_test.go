@@ -0,0 +1,108 @@
+package testutil_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"srpc/client"
+	"srpc/pkg/testutil"
+	pb "srpc/proto"
+	"srpc/server"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/stats"
+)
+
+// compressionRecorder 服务端 stats.Handler，按方法记录每次调用请求头中的压缩算法
+type compressionRecorder struct {
+	mu     sync.Mutex
+	byCall map[string][]string
+}
+
+type methodKey struct{}
+
+func (r *compressionRecorder) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return context.WithValue(ctx, methodKey{}, info.FullMethodName)
+}
+
+func (r *compressionRecorder) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	if in, ok := s.(*stats.InHeader); ok {
+		method, _ := ctx.Value(methodKey{}).(string)
+		r.mu.Lock()
+		r.byCall[method] = append(r.byCall[method], in.Compression)
+		r.mu.Unlock()
+	}
+}
+
+func (r *compressionRecorder) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (r *compressionRecorder) HandleConn(context.Context, stats.ConnStats) {}
+
+// calls 返回 method 各次调用的压缩算法
+func (r *compressionRecorder) calls(method string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.byCall[method]...)
+}
+
+func TestStreamingCompressionOverride(t *testing.T) {
+	t.Parallel()
+	rec := &compressionRecorder{byCall: make(map[string][]string)}
+	ts := testutil.StartTestServer(t, server.WithServerOptions(grpc.StatsHandler(rec)))
+	cfg := ts.ClientConfig()
+	cfg.DisableAutoRequests = true
+	cfg.EnableCompression = true
+	cfg.CompressionType = "snappy"
+	c := testutil.NewClient(t, cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// 同一客户端先按默认配置压缩，再用 UseCompressor("identity") 关闭单次调用的压缩
+	overrides := [][]grpc.CallOption{nil, {grpc.UseCompressor(encoding.Identity)}}
+	for _, opts := range overrides {
+		if _, err := c.CallPutStream(ctx, []string{"a", "b"}, opts...); err != nil {
+			t.Fatalf("CallPutStream 失败: %v", err)
+		}
+
+		stream, err := c.OpenAllStream(ctx, 4, opts...)
+		if err != nil {
+			t.Fatalf("OpenAllStream 失败: %v", err)
+		}
+		if err := stream.Send("ping"); err != nil {
+			t.Fatalf("AllStream 发送失败: %v", err)
+		}
+		stream.Close()
+		for range stream.Messages() {
+		}
+		<-stream.Err()
+	}
+	// GetStream 需要数秒，只验证关闭压缩的一次，收到第一条消息即停止
+	errStop := errors.New("停止接收")
+	err := c.CallGetStream(ctx, "get", func(*pb.StreamResData) error { return errStop }, client.WithoutCompression())
+	if !errors.Is(err, errStop) {
+		t.Fatalf("CallGetStream 错误 = %v，期望 handle 返回的错误", err)
+	}
+
+	for _, method := range []string{pb.Greeter_PutStream_FullMethodName, pb.Greeter_AllStream_FullMethodName} {
+		got := rec.calls(method)
+		if len(got) != 2 {
+			t.Fatalf("%s 调用次数 = %d，期望 2: %v", method, len(got), got)
+		}
+		if got[0] != "snappy" {
+			t.Errorf("%s 默认调用压缩 = %q，期望 snappy", method, got[0])
+		}
+		if got[1] == "snappy" {
+			t.Errorf("%s 覆盖后仍使用 snappy 压缩", method)
+		}
+	}
+	if got := rec.calls(pb.Greeter_GetStream_FullMethodName); len(got) != 1 || got[0] == "snappy" {
+		t.Errorf("GetStream 压缩 = %v，期望 WithoutCompression 关闭压缩", got)
+	}
+}
@@ -0,0 +1,79 @@
+package testutil_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"srpc/client"
+	"srpc/pkg/testutil"
+	pb "srpc/proto"
+)
+
+func TestClientConnectsToTestServer(t *testing.T) {
+	t.Parallel()
+	ts := testutil.StartTestServer(t)
+	c := testutil.NewClient(t, ts.ClientConfig())
+
+	if got := c.ConnectionState(); got != client.StateConnected {
+		t.Fatalf("连接状态 = %v，期望 %v", got, client.StateConnected)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	reply, err := c.SendNow(ctx, "bufconn")
+	if err != nil {
+		t.Fatalf("SendNow 失败: %v", err)
+	}
+	if got, want := reply.GetMessage(), "Hello bufconn!"; got != want {
+		t.Errorf("响应 = %q，期望 %q", got, want)
+	}
+
+	info, err := c.ServerInfo(ctx)
+	if err != nil {
+		t.Fatalf("ServerInfo 失败: %v", err)
+	}
+	if info.GetGoVersion() != runtime.Version() {
+		t.Errorf("GoVersion = %q，期望 %q", info.GetGoVersion(), runtime.Version())
+	}
+}
+
+func TestGreeterStub(t *testing.T) {
+	t.Parallel()
+	ts := testutil.StartTestServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	reply, err := ts.Greeter(t).SayHello(ctx, &pb.HelloRequest{Name: "stub"})
+	if err != nil {
+		t.Fatalf("SayHello 失败: %v", err)
+	}
+	if got, want := reply.GetMessage(), "Hello stub!"; got != want {
+		t.Errorf("响应 = %q，期望 %q", got, want)
+	}
+}
+
+func TestNewClientFailsWhenServerStopped(t *testing.T) {
+	t.Parallel()
+	ts := testutil.StartTestServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ts.Server.Stop(ctx)
+
+	cfg := ts.ClientConfig()
+	cfg.DialTimeout = 200 * time.Millisecond
+	start := time.Now()
+	c, err := client.NewGRPCClient(cfg)
+	if err == nil {
+		c.Shutdown()
+		t.Fatal("服务端已停止，NewGRPCClient 应返回错误")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("错误 = %v，期望包装 context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("拨号耗时 %v，应在 DialTimeout 附近返回", elapsed)
+	}
+}
@@ -0,0 +1,133 @@
+package testutil_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"srpc/pkg/testutil"
+	pb "srpc/proto"
+	"srpc/server"
+)
+
+// startDebugServer 启动在随机端口上开启调试服务的测试服务端，返回调试服务的 URL 前缀
+func startDebugServer(t *testing.T) (*testutil.TestServer, string) {
+	t.Helper()
+	cfg := server.DefaultConfig()
+	cfg.DebugAddr = "127.0.0.1:0"
+	ts := testutil.StartTestServerWithConfig(t, cfg)
+
+	// 调试服务随 Serve 在后台启动
+	deadline := time.Now().Add(5 * time.Second)
+	for ts.Server.DebugAddr() == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("等待调试服务启动超时")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return ts, "http://" + ts.Server.DebugAddr().String()
+}
+
+// httpGet 请求 url 并返回响应体，状态码不是 200 时使测试失败
+func httpGet(t *testing.T, url string) string {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s 失败: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("读取 %s 响应失败: %v", url, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s 状态码 = %d: %s", url, resp.StatusCode, body)
+	}
+	return string(body)
+}
+
+func TestDebugPprofGoroutine(t *testing.T) {
+	t.Parallel()
+	_, base := startDebugServer(t)
+
+	body := httpGet(t, base+"/debug/pprof/goroutine?debug=1")
+	if !strings.HasPrefix(body, "goroutine profile: total") {
+		t.Errorf("goroutine 输出不是文本格式的 profile: %.80q", body)
+	}
+	// 服务端的 Serve 循环应出现在调用栈中
+	if !strings.Contains(body, "google.golang.org/grpc.(*Server).Serve") {
+		t.Error("goroutine 输出中没有 gRPC Serve 的调用栈")
+	}
+}
+
+func TestDebugVarsIncludesServerMetrics(t *testing.T) {
+	t.Parallel()
+	ts, base := startDebugServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := ts.Greeter(t).SayHello(ctx, &pb.HelloRequest{Name: "vars"}); err != nil {
+		t.Fatalf("SayHello 失败: %v", err)
+	}
+
+	var vars map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(httpGet(t, base+"/debug/vars")), &vars); err != nil {
+		t.Fatalf("/debug/vars 不是合法 JSON: %v", err)
+	}
+	for _, key := range []string{"memstats", "cmdline", "srpc_server"} {
+		if _, ok := vars[key]; !ok {
+			t.Errorf("/debug/vars 缺少 %s", key)
+		}
+	}
+}
+
+func TestDebugGRPCShowsActiveStreams(t *testing.T) {
+	t.Parallel()
+	ts, base := startDebugServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := ts.Greeter(t).AllStream(ctx)
+	if err != nil {
+		t.Fatalf("打开 AllStream 失败: %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("接收 AllStream 消息失败: %v", err)
+	}
+
+	body := httpGet(t, base+"/debug/grpc")
+	if !strings.Contains(body, "Greeter") {
+		t.Errorf("/debug/grpc 未列出 Greeter 服务:\n%s", body)
+	}
+	var found bool
+	for _, line := range strings.Split(body, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 3 && fields[0] == "AllStream" {
+			found = true
+			if fields[2] != "active=1" {
+				t.Errorf("AllStream 行 = %q，期望 active=1", line)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("/debug/grpc 未列出 AllStream:\n%s", body)
+	}
+}
+
+func TestDebugServerStopsWithServer(t *testing.T) {
+	t.Parallel()
+	ts, base := startDebugServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ts.Server.Stop(ctx)
+
+	if resp, err := http.Get(base + "/debug/pprof/"); err == nil {
+		resp.Body.Close()
+		t.Error("服务端停止后调试服务仍在响应")
+	}
+}
@@ -0,0 +1,92 @@
+package testutil_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"srpc/pkg/testutil"
+)
+
+func TestHedgingBeatsSlowServer(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int64
+	// 只有第一次调用很慢，对冲请求应立即得到响应
+	ts := testutil.StartTestServer(t, slowSayHello(1, 500*time.Millisecond, &calls))
+	cfg := ts.ClientConfig()
+	cfg.HedgingMaxAttempts = 2
+	cfg.HedgingDelay = 50 * time.Millisecond
+	c := testutil.NewClient(t, cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	if _, err := c.SendNow(ctx, "hedge"); err != nil {
+		t.Fatalf("SendNow 失败: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond || elapsed > 300*time.Millisecond {
+		t.Errorf("耗时 %v，期望在对冲延迟 50ms 后由对冲请求很快返回", elapsed)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("服务端收到 %d 次 SayHello，期望 2", got)
+	}
+
+	// 一次逻辑请求只记录一次结果
+	m := c.GetMetrics()
+	if m.TotalRequests != 1 || m.SuccessfulRequests != 1 || m.FailedRequests != 0 {
+		t.Errorf("总数 %d、成功 %d、失败 %d，期望 1、1、0", m.TotalRequests, m.SuccessfulRequests, m.FailedRequests)
+	}
+	if m.HedgedRequests != 1 || m.HedgeWins != 1 {
+		t.Errorf("HedgedRequests=%d HedgeWins=%d，期望 1 和 1", m.HedgedRequests, m.HedgeWins)
+	}
+}
+
+func TestHedgingRespectsMaxAttempts(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int64
+	// 每次调用都慢 300ms，最多同时发出 3 个请求，最先发出的原始请求最先返回
+	ts := testutil.StartTestServer(t, slowSayHello(-1, 300*time.Millisecond, &calls))
+	cfg := ts.ClientConfig()
+	cfg.HedgingMaxAttempts = 3
+	cfg.HedgingDelay = 30 * time.Millisecond
+	c := testutil.NewClient(t, cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := c.SendNow(ctx, "hedge"); err != nil {
+		t.Fatalf("SendNow 失败: %v", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("服务端收到 %d 次 SayHello，期望 HedgingMaxAttempts 个", got)
+	}
+
+	m := c.GetMetrics()
+	if m.TotalRequests != 1 || m.SuccessfulRequests != 1 {
+		t.Errorf("总数 %d、成功 %d，期望 1 和 1", m.TotalRequests, m.SuccessfulRequests)
+	}
+	if m.HedgedRequests != 2 || m.HedgeWins != 0 {
+		t.Errorf("HedgedRequests=%d HedgeWins=%d，期望 2 和 0", m.HedgedRequests, m.HedgeWins)
+	}
+}
+
+func TestHedgingDisabledSendsOnce(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int64
+	ts := testutil.StartTestServer(t, slowSayHello(1, 200*time.Millisecond, &calls))
+	cfg := ts.ClientConfig()
+	cfg.HedgingDelay = 20 * time.Millisecond // HedgingMaxAttempts 为 0 时不启用
+	c := testutil.NewClient(t, cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := c.SendNow(ctx, "single"); err != nil {
+		t.Fatalf("SendNow 失败: %v", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("服务端收到 %d 次 SayHello，未启用对冲时期望 1", got)
+	}
+	if m := c.GetMetrics(); m.HedgedRequests != 0 {
+		t.Errorf("HedgedRequests = %d，期望 0", m.HedgedRequests)
+	}
+}
@@ -0,0 +1,181 @@
+package testutil_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"srpc/client"
+	"srpc/pkg/testutil"
+)
+
+// startRun 在后台运行 c，返回 Run 的结果通道
+func startRun(c *client.GRPCClient) <-chan error {
+	done := make(chan error, 1)
+	go func() { done <- c.Run() }()
+	return done
+}
+
+// waitRunning 等待后台的 Run 进入主流程：主循环只在 Run 期间发起请求，请求计数增长即说明已在运行
+func waitRunning(t *testing.T, c *client.GRPCClient) {
+	t.Helper()
+	before := c.GetMetrics().TotalRequests
+	deadline := time.Now().Add(5 * time.Second)
+	for c.GetMetrics().TotalRequests <= before {
+		if time.Now().After(deadline) {
+			t.Fatal("等待 Run 启动超时")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// waitRunReturn 等待 Run 返回，超时时使测试失败
+func waitRunReturn(t *testing.T, done <-chan error) {
+	t.Helper()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run 返回错误: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("等待 Run 返回超时")
+	}
+}
+
+// sendOK 发起一次按需请求，失败时使测试失败
+func sendOK(t *testing.T, c *client.GRPCClient, name string) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := c.SendNow(ctx, name); err != nil {
+		t.Fatalf("SendNow(%q) 失败: %v", name, err)
+	}
+}
+
+func TestRunShutdownRunLifecycle(t *testing.T) {
+	t.Parallel()
+	ts := testutil.StartTestServer(t)
+	c := testutil.NewClient(t, ts.ClientConfig())
+
+	for round := 1; round <= 2; round++ {
+		done := startRun(c)
+		waitRunning(t, c)
+		if got := c.ConnectionState(); got != client.StateConnected {
+			t.Fatalf("第 %d 轮连接状态 = %v，期望 %v", round, got, client.StateConnected)
+		}
+		sendOK(t, c, "lifecycle")
+
+		c.Shutdown()
+		waitRunReturn(t, done)
+		if got := c.ConnectionState(); got == client.StateConnected {
+			t.Fatalf("第 %d 轮关闭后连接状态仍为 %v", round, got)
+		}
+		if _, err := c.SendNow(context.Background(), "closed"); err == nil {
+			t.Fatalf("第 %d 轮关闭后 SendNow 应失败", round)
+		}
+	}
+
+	// 重新运行保留之前累计的指标：每轮至少有主循环的一次请求和 SendNow 的一次请求
+	if got := c.GetMetrics().SuccessfulRequests; got < 4 {
+		t.Errorf("SuccessfulRequests = %d，期望两轮累计至少 4", got)
+	}
+}
+
+func TestConcurrentRunReturnsErrAlreadyRunning(t *testing.T) {
+	t.Parallel()
+	ts := testutil.StartTestServer(t)
+	c := testutil.NewClient(t, ts.ClientConfig())
+
+	done := startRun(c)
+	waitRunning(t, c)
+
+	const callers = 8
+	errs := make(chan error, callers)
+	for range callers {
+		go func() { errs <- c.Run() }()
+	}
+	for range callers {
+		select {
+		case err := <-errs:
+			if !errors.Is(err, client.ErrAlreadyRunning) {
+				t.Errorf("并发 Run 返回 %v，期望 ErrAlreadyRunning", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("并发 Run 未立即返回")
+		}
+	}
+
+	// 被拒绝的 Run 不影响正在运行的实例
+	sendOK(t, c, "still-running")
+	c.Shutdown()
+	waitRunReturn(t, done)
+}
+
+func TestShutdownBeforeRun(t *testing.T) {
+	t.Parallel()
+	ts := testutil.StartTestServer(t)
+	c := testutil.NewClient(t, ts.ClientConfig())
+
+	// Run 之前关闭只释放 NewGRPCClient 建立的连接，之后的 Run 重新连接
+	c.Shutdown()
+	if got := c.ConnectionState(); got == client.StateConnected {
+		t.Fatalf("关闭后连接状态仍为 %v", got)
+	}
+
+	done := startRun(c)
+	waitRunning(t, c)
+	sendOK(t, c, "after-early-shutdown")
+
+	c.Shutdown()
+	waitRunReturn(t, done)
+}
+
+func TestShutdownDuringRunWaitsForRun(t *testing.T) {
+	t.Parallel()
+	ts := testutil.StartTestServer(t)
+	c := testutil.NewClient(t, ts.ClientConfig())
+
+	done := startRun(c)
+	waitRunning(t, c)
+
+	// Shutdown 在 Run 清理完资源后才返回，此时 Run 的结果已经可以取到
+	c.Shutdown()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run 返回错误: %v", err)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Shutdown 返回时 Run 仍未返回")
+	}
+}
+
+func TestConcurrentDoubleShutdown(t *testing.T) {
+	t.Parallel()
+	ts := testutil.StartTestServer(t)
+	c := testutil.NewClient(t, ts.ClientConfig())
+
+	done := startRun(c)
+	waitRunning(t, c)
+
+	// 两次关闭同时进行，只有一次执行关闭，另一次阻塞到关闭完成，均不应 panic
+	returned := make(chan struct{}, 2)
+	for range 2 {
+		go func() {
+			c.Shutdown()
+			returned <- struct{}{}
+		}()
+	}
+	for range 2 {
+		select {
+		case <-returned:
+		case <-time.After(5 * time.Second):
+			t.Fatal("并发 Shutdown 未返回")
+		}
+	}
+	waitRunReturn(t, done)
+
+	// 关闭完成后再次调用立即返回
+	c.Shutdown()
+}
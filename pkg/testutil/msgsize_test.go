@@ -0,0 +1,160 @@
+package testutil_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"srpc/client"
+	"srpc/pkg/testutil"
+	pb "srpc/proto"
+	"srpc/server"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// captureLogs 把之后创建的日志记录器的输出重定向到内存，返回读取已输出日志行的函数
+// 日志记录器在创建时绑定 os.Stdout，此后创建的客户端写入管道；使用它的测试不能并行
+func captureLogs(t *testing.T) func() []map[string]any {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("创建管道失败: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+
+	var mu sync.Mutex
+	var lines []map[string]any
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			var line map[string]any
+			if json.Unmarshal(scanner.Bytes(), &line) == nil {
+				mu.Lock()
+				lines = append(lines, line)
+				mu.Unlock()
+			}
+		}
+		io.Copy(io.Discard, r)
+	}()
+	// 先于客户端注册，客户端关闭后才关闭管道
+	t.Cleanup(func() {
+		w.Close()
+		<-done
+		r.Close()
+	})
+	t.Cleanup(func() { os.Stdout = stdout })
+
+	return func() []map[string]any {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]map[string]any(nil), lines...)
+	}
+}
+
+// findLog 返回第一条消息为 msg 的日志，等待异步写入最多 1 秒
+func findLog(t *testing.T, logs func() []map[string]any, msg string) map[string]any {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		for _, line := range logs() {
+			if line["msg"] == msg {
+				return line
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("未找到日志 %q", msg)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// nameOfSize 返回使 HelloRequest 编码后恰好为 size 字节的名字
+func nameOfSize(t *testing.T, size int) string {
+	t.Helper()
+	for n := size; n > 0; n-- {
+		name := strings.Repeat("a", n)
+		if got := proto.Size(&pb.HelloRequest{Name: name}); got == size {
+			return name
+		} else if got < size {
+			break
+		}
+	}
+	t.Fatalf("无法构造 %d 字节的请求", size)
+	return ""
+}
+
+// 名字长度均低于服务端默认的 MaxNameLength，失败只来自消息大小限制
+func TestClientMaxSendMsgSizeBoundary(t *testing.T) {
+	const limit = 200
+	ts := testutil.StartTestServer(t)
+	logs := captureLogs(t)
+
+	clientCfg := fastRetryConfig(ts, 0)
+	clientCfg.MaxSendMsgSize = limit
+	c := testutil.NewClient(t, clientCfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := c.SendNow(ctx, nameOfSize(t, limit)); err != nil {
+		t.Fatalf("恰好等于上限的请求失败: %v", err)
+	}
+
+	_, err := c.SendNow(ctx, nameOfSize(t, limit+1))
+	if !errors.Is(err, client.ErrResourceExhausted) || status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("错误 = %v，期望 ResourceExhausted", err)
+	}
+
+	line := findLog(t, logs, "消息大小超过限制")
+	if got := line["request_size"]; got != float64(limit+1) {
+		t.Errorf("request_size = %v，期望 %d", got, limit+1)
+	}
+	if got := line["max_send_msg_size"]; got != float64(limit) {
+		t.Errorf("max_send_msg_size = %v，期望 %d", got, limit)
+	}
+	if msg, _ := line["error"].(string); !strings.Contains(msg, "larger than max") {
+		t.Errorf("error = %q，期望包含 gRPC 的超限说明", msg)
+	}
+}
+
+func TestServerMaxRecvMsgSizeBoundary(t *testing.T) {
+	const limit = 200
+	cfg := server.DefaultConfig()
+	cfg.MaxRecvMsgSize = limit
+	ts := testutil.StartTestServerWithConfig(t, cfg)
+	logs := captureLogs(t)
+
+	c := testutil.NewClient(t, fastRetryConfig(ts, 0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := c.SendNow(ctx, nameOfSize(t, limit)); err != nil {
+		t.Fatalf("恰好等于服务端上限的请求失败: %v", err)
+	}
+
+	_, err := c.SendNow(ctx, nameOfSize(t, limit+1))
+	if !errors.Is(err, client.ErrResourceExhausted) || status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("错误 = %v，期望 ResourceExhausted", err)
+	}
+
+	// 本端未设置限制，日志中的本端上限为 0，说明是对端拒绝
+	line := findLog(t, logs, "消息大小超过限制")
+	if got := line["request_size"]; got != float64(limit+1) {
+		t.Errorf("request_size = %v，期望 %d", got, limit+1)
+	}
+	if got := line["max_send_msg_size"]; got != float64(0) {
+		t.Errorf("max_send_msg_size = %v，期望 0", got)
+	}
+}
@@ -0,0 +1,89 @@
+package testutil_test
+
+import (
+	"testing"
+	"time"
+
+	"srpc/client"
+	"srpc/pkg/testutil"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+)
+
+// waitConnState 等待客户端整体连接状态是否为已连接达到 connected
+func waitConnState(t *testing.T, c *client.GRPCClient, connected bool) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for (c.ConnectionState() == client.StateConnected) != connected {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待连接状态超时，当前为 %s", c.ConnectionState())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestOutageMetricsAcrossServerRestart(t *testing.T) {
+	t.Parallel()
+	ts := testutil.StartTestServer(t)
+	cfg := ts.ClientConfig()
+	// 缩短 gRPC 传输层的重连退避，服务端重启后尽快恢复
+	cfg.DialOptions = append(cfg.DialOptions, grpc.WithConnectParams(grpc.ConnectParams{
+		Backoff:           backoff.Config{BaseDelay: 10 * time.Millisecond, Multiplier: 1, MaxDelay: 10 * time.Millisecond},
+		MinConnectTimeout: time.Second,
+	}))
+	c := testutil.NewClient(t, cfg)
+
+	time.Sleep(50 * time.Millisecond)
+	m := c.GetMetrics()
+	if m.Outages != 0 || m.TotalDowntime != 0 || m.ConnectionUptime < 50*time.Millisecond {
+		t.Errorf("初始 outages=%d downtime=%v uptime=%v，期望无断开且已连接至少 50ms", m.Outages, m.TotalDowntime, m.ConnectionUptime)
+	}
+	firstConnected := m.LastConnectedTime
+
+	const outage = 200 * time.Millisecond
+	var totalOutages time.Duration
+	for cycle := 1; cycle <= 2; cycle++ {
+		stopped := time.Now()
+		ts.Stop(t)
+		waitConnState(t, c, false)
+		time.Sleep(outage)
+
+		// 断开期间：进行中的断开计入累计时长，但尚未计为一次已结束的断开
+		m = c.GetMetrics()
+		if m.ConnectionUptime != 0 || m.Outages != int64(cycle-1) {
+			t.Errorf("第 %d 次断开期间 uptime=%v outages=%d，期望 0 和 %d", cycle, m.ConnectionUptime, m.Outages, cycle-1)
+		}
+		if m.TotalDowntime < totalOutages+outage {
+			t.Errorf("第 %d 次断开期间累计断开 %v，期望至少 %v", cycle, m.TotalDowntime, totalOutages+outage)
+		}
+
+		ts.Restart(t)
+		waitConnState(t, c, true)
+		sendOK(t, c, "restarted")
+
+		m = c.GetMetrics()
+		if m.Outages != int64(cycle) {
+			t.Errorf("第 %d 次重连后 outages = %d", cycle, m.Outages)
+		}
+		if sinceStop := time.Since(stopped); m.LastOutageDuration < outage || m.LastOutageDuration > sinceStop {
+			t.Errorf("第 %d 次断开时长 %v，期望在 %v 和停止服务端以来的 %v 之间", cycle, m.LastOutageDuration, outage, sinceStop)
+		}
+		totalOutages += m.LastOutageDuration
+		if m.TotalDowntime != totalOutages {
+			t.Errorf("第 %d 次重连后累计断开 %v，期望各次断开之和 %v", cycle, m.TotalDowntime, totalOutages)
+		}
+		if !m.LastConnectedTime.After(firstConnected) || m.ConnectionUptime >= time.Since(stopped) {
+			t.Errorf("第 %d 次重连后 last_connected=%v uptime=%v，期望从重连成功起计算", cycle, m.LastConnectedTime, m.ConnectionUptime)
+		}
+	}
+
+	// 快照字段以毫秒和秒输出
+	fields := c.GetMetrics().Map()
+	if got := fields["total_downtime_ms"]; got != totalOutages.Milliseconds() {
+		t.Errorf("total_downtime_ms = %v，期望 %d", got, totalOutages.Milliseconds())
+	}
+	if _, ok := fields["connection_uptime_s"].(float64); !ok {
+		t.Errorf("connection_uptime_s = %#v，期望为秒数", fields["connection_uptime_s"])
+	}
+}
@@ -0,0 +1,61 @@
+package testutil_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"srpc/client"
+	"srpc/pkg/testutil"
+	pb "srpc/proto"
+	"srpc/server"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// panicOn 对名字为 name 的 SayHello 请求直接 panic 并计数，其余请求正常处理
+func panicOn(name string, panics *atomic.Int64) server.Option {
+	return server.WithUnaryInterceptors(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if in, ok := req.(*pb.HelloRequest); ok && in.GetName() == name {
+			panics.Add(1)
+			panic("模拟 panic")
+		}
+		return handler(ctx, req)
+	})
+}
+
+func TestPanicRecoveredAsInternal(t *testing.T) {
+	t.Parallel()
+	var panics atomic.Int64
+	ts := testutil.StartTestServer(t, panicOn("boom", &panics))
+	c := testutil.NewClient(t, ts.ClientConfig())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := c.SendNow(ctx, "boom")
+	var rpcErr *client.RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != codes.Internal {
+		t.Fatalf("错误 = %v，期望 Internal", err)
+	}
+
+	// 恢复后连接和服务端都保持可用
+	if _, err := c.SendNow(ctx, "after"); err != nil {
+		t.Fatalf("panic 之后的请求失败: %v", err)
+	}
+
+	m := ts.Server.Metrics().GetMetrics()
+	want := panics.Load()
+	if got := m["panics"].(int64); got != want {
+		t.Errorf("panics = %d，期望 %d", got, want)
+	}
+	// 指标拦截器位于恢复拦截器之内，panic 的请求仍计为失败
+	if got := m["failed_requests"].(int64); got != want {
+		t.Errorf("failed_requests = %d，期望 %d", got, want)
+	}
+	if got := m["in_flight"].(int64); got != 0 {
+		t.Errorf("in_flight = %d，期望 0", got)
+	}
+}
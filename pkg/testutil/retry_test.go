@@ -0,0 +1,113 @@
+package testutil_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"srpc/client"
+	"srpc/pkg/testutil"
+	pb "srpc/proto"
+	"srpc/server"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// failSayHello 返回让前 n 次 SayHello 以 code 失败的服务端拦截器，n 小于 0 时每次都失败；calls 记录收到的 SayHello 次数
+func failSayHello(n int64, code codes.Code, calls *atomic.Int64) server.Option {
+	return server.WithUnaryInterceptors(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if info.FullMethod != pb.Greeter_SayHello_FullMethodName {
+			return handler(ctx, req)
+		}
+		if call := calls.Add(1); n < 0 || call <= n {
+			return nil, status.Errorf(code, "第 %d 次调用注入失败", call)
+		}
+		return handler(ctx, req)
+	})
+}
+
+// fastRetryConfig 返回重试退避为毫秒级的客户端配置
+func fastRetryConfig(ts *testutil.TestServer, maxRetries int) client.Config {
+	cfg := ts.ClientConfig()
+	cfg.MaxRetries = maxRetries
+	cfg.RetryBaseDelay = time.Millisecond
+	cfg.RetryMaxDelay = 5 * time.Millisecond
+	return cfg
+}
+
+func TestSendNowRetriesUntilSuccess(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int64
+	ts := testutil.StartTestServer(t, failSayHello(2, codes.Unavailable, &calls))
+	c := testutil.NewClient(t, fastRetryConfig(ts, 3))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	reply, err := c.SendNow(ctx, "retry")
+	if err != nil {
+		t.Fatalf("SendNow 失败: %v", err)
+	}
+	if got, want := reply.GetMessage(), "Hello retry!"; got != want {
+		t.Errorf("响应 = %q，期望 %q", got, want)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("服务端收到 %d 次调用，期望 3 次", got)
+	}
+	snap := c.GetMetrics()
+	if snap.TotalRetries != 2 {
+		t.Errorf("TotalRetries = %d，期望 2", snap.TotalRetries)
+	}
+	if snap.ErrorsRetriedAway != 2 {
+		t.Errorf("ErrorsRetriedAway = %d，期望 2", snap.ErrorsRetriedAway)
+	}
+}
+
+func TestSendNowRetryExhausted(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int64
+	ts := testutil.StartTestServer(t, failSayHello(-1, codes.Unavailable, &calls))
+	c := testutil.NewClient(t, fastRetryConfig(ts, 2))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := c.SendNow(ctx, "exhausted")
+	if !errors.Is(err, client.ErrUnavailable) {
+		t.Fatalf("错误 = %v，期望 ErrUnavailable 类别", err)
+	}
+	var rpcErr *client.RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Attempts != 3 {
+		t.Errorf("错误 = %#v，期望 RPCError.Attempts = 3", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("服务端收到 %d 次调用，期望 3 次", got)
+	}
+	if got := c.GetMetrics().FinalErrorsByCode[codes.Unavailable.String()]; got != 1 {
+		t.Errorf("FinalErrorsByCode[Unavailable] = %d，期望 1", got)
+	}
+}
+
+func TestSendNowDoesNotRetryInvalidArgument(t *testing.T) {
+	t.Parallel()
+	ts := testutil.StartTestServer(t)
+	c := testutil.NewClient(t, fastRetryConfig(ts, 3))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// 服务端默认限制 name 最长 256 字节
+	_, err := c.SendNow(ctx, strings.Repeat("x", 257))
+	if !errors.Is(err, client.ErrInvalidArgument) {
+		t.Fatalf("错误 = %v，期望 ErrInvalidArgument 类别", err)
+	}
+	var rpcErr *client.RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Attempts != 1 {
+		t.Errorf("错误 = %#v，期望只尝试 1 次", err)
+	}
+	if got := c.GetMetrics().TotalRetries; got != 0 {
+		t.Errorf("TotalRetries = %d，参数错误不应重试", got)
+	}
+}
@@ -0,0 +1,120 @@
+package testutil_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"srpc/client"
+	"srpc/pkg/testutil"
+	pb "srpc/proto"
+)
+
+func TestCallGetStream(t *testing.T) {
+	t.Parallel()
+	ts := testutil.StartTestServer(t)
+	c := testutil.NewClient(t, ts.ClientConfig())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var got []string
+	err := c.CallGetStream(ctx, "get", func(resp *pb.StreamResData) error {
+		got = append(got, resp.GetData())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("CallGetStream 失败: %v", err)
+	}
+	if len(got) != 5 {
+		t.Fatalf("收到 %d 条消息，期望 5 条: %q", len(got), got)
+	}
+	for i, data := range got {
+		if want := fmt.Sprintf("服务端流数据 %d: get", i+1); data != want {
+			t.Errorf("第 %d 条消息 = %q，期望 %q", i+1, data, want)
+		}
+	}
+}
+
+func TestCallGetStreamHandlerError(t *testing.T) {
+	t.Parallel()
+	ts := testutil.StartTestServer(t)
+	c := testutil.NewClient(t, ts.ClientConfig())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	errStop := errors.New("停止接收")
+	received := 0
+	err := c.CallGetStream(ctx, "stop", func(*pb.StreamResData) error {
+		received++
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("错误 = %v，期望原样返回 handle 的错误", err)
+	}
+	if received != 1 {
+		t.Errorf("handle 被调用 %d 次，期望 1 次", received)
+	}
+}
+
+func TestCallPutStream(t *testing.T) {
+	t.Parallel()
+	ts := testutil.StartTestServer(t)
+	c := testutil.NewClient(t, ts.ClientConfig())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	reply, err := c.CallPutStream(ctx, []string{"a", "bb", "ccc"})
+	if err != nil {
+		t.Fatalf("CallPutStream 失败: %v", err)
+	}
+	if want := "成功接收 3 条消息（共 6 字节），最后一条: ccc"; reply.GetData() != want {
+		t.Errorf("响应 = %q，期望 %q", reply.GetData(), want)
+	}
+}
+
+func TestOpenAllStream(t *testing.T) {
+	t.Parallel()
+	ts := testutil.StartTestServer(t)
+	c := testutil.NewClient(t, ts.ClientConfig())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	s, err := c.OpenAllStream(ctx, 8)
+	if err != nil {
+		t.Fatalf("OpenAllStream 失败: %v", err)
+	}
+	if err := s.Send("ping"); err != nil {
+		t.Fatalf("Send 失败: %v", err)
+	}
+
+	// 服务端每秒发送一条初始消息，共 3 条，并立即回应客户端消息
+	initial, echoed := 0, false
+	for initial < 3 || !echoed {
+		select {
+		case msg, ok := <-s.Messages():
+			if !ok {
+				t.Fatalf("流提前结束: %v", <-s.Err())
+			}
+			switch {
+			case msg.GetData() == "回应: ping":
+				echoed = true
+			case strings.HasPrefix(msg.GetData(), "服务端初始消息"):
+				initial++
+			default:
+				t.Errorf("未预期的消息: %q", msg.GetData())
+			}
+		case <-ctx.Done():
+			t.Fatalf("等待消息超时，已收到 %d 条初始消息，回应: %v", initial, echoed)
+		}
+	}
+
+	s.Close()
+	for range s.Messages() {
+	}
+	if err := <-s.Err(); !errors.Is(err, client.ErrCanceled) {
+		t.Errorf("Close 后 Err = %v，期望 ErrCanceled 类别", err)
+	}
+}
@@ -0,0 +1,110 @@
+package testutil_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"srpc/pkg/testutil"
+	pb "srpc/proto"
+	"srpc/server"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// handlerExit 流处理器返回的时间和错误
+type handlerExit struct {
+	at  time.Time
+	err error
+}
+
+// recordHandlerExit 返回记录 method 处理器何时返回的服务端流拦截器
+func recordHandlerExit(method string, exits chan<- handlerExit) server.Option {
+	return server.WithStreamInterceptors(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+		if info.FullMethod == method {
+			exits <- handlerExit{at: time.Now(), err: err}
+		}
+		return err
+	})
+}
+
+// assertHandlerExitsPromptly 检查处理器在 cancelled 之后很快以取消错误返回，而不是等完模拟的处理延迟
+func assertHandlerExitsPromptly(t *testing.T, exits <-chan handlerExit, cancelled time.Time) {
+	t.Helper()
+	select {
+	case exit := <-exits:
+		if d := exit.at.Sub(cancelled); d > 100*time.Millisecond {
+			t.Errorf("处理器在取消 %v 后才返回", d)
+		}
+		if code := status.Code(exit.err); code != codes.Canceled {
+			t.Errorf("处理器返回 %v，期望 Canceled", exit.err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("客户端取消后处理器未返回")
+	}
+}
+
+func TestGetStreamHandlerExitsOnCancel(t *testing.T) {
+	t.Parallel()
+	exits := make(chan handlerExit, 1)
+	ts := testutil.StartTestServer(t, recordHandlerExit(pb.Greeter_GetStream_FullMethodName, exits))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := ts.Greeter(t).GetStream(ctx, &pb.StreamReqData{Data: "cancel"})
+	if err != nil {
+		t.Fatalf("打开 GetStream 失败: %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("接收第一条消息失败: %v", err)
+	}
+
+	// 处理器在两条消息之间等待 500ms，取消后应立即返回
+	cancel()
+	assertHandlerExitsPromptly(t, exits, time.Now())
+}
+
+func TestAllStreamHandlerExitsOnCancel(t *testing.T) {
+	t.Parallel()
+	exits := make(chan handlerExit, 1)
+	ts := testutil.StartTestServer(t, recordHandlerExit(pb.Greeter_AllStream_FullMethodName, exits))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := ts.Greeter(t).AllStream(ctx)
+	if err != nil {
+		t.Fatalf("打开 AllStream 失败: %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("接收第一条初始消息失败: %v", err)
+	}
+
+	// 初始消息之间等待 1s，取消后应立即返回
+	cancel()
+	assertHandlerExitsPromptly(t, exits, time.Now())
+}
+
+func TestStopAfterClientDisconnectIsPrompt(t *testing.T) {
+	t.Parallel()
+	ts := testutil.StartTestServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := ts.Greeter(t).GetStream(ctx, &pb.StreamReqData{Data: "stop"})
+	if err != nil {
+		t.Fatalf("打开 GetStream 失败: %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("接收第一条消息失败: %v", err)
+	}
+
+	// 剩余的 4 条消息需要约 2 秒；客户端已断开时，优雅停止不应等待处理器的模拟延迟
+	cancel()
+	start := time.Now()
+	ts.Stop(t)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("停止服务端耗时 %v，处理器仍在等待模拟延迟", elapsed)
+	}
+}
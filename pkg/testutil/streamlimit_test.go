@@ -0,0 +1,153 @@
+package testutil_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"srpc/pkg/testutil"
+	pb "srpc/proto"
+	"srpc/server"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// putStream 通过 PutStream 依次发送 data 并返回服务端的汇总响应
+// 服务端提前结束流时 Send 返回 io.EOF，真正的错误由 CloseAndRecv 返回
+func putStream(t *testing.T, ts *testutil.TestServer, data ...string) (*pb.StreamResData, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := ts.Greeter(t).PutStream(ctx)
+	if err != nil {
+		t.Fatalf("打开 PutStream 失败: %v", err)
+	}
+	for _, d := range data {
+		if err := stream.Send(&pb.StreamReqData{Data: d}); err != nil {
+			if !errors.Is(err, io.EOF) {
+				t.Fatalf("发送失败: %v", err)
+			}
+			break
+		}
+	}
+	return stream.CloseAndRecv()
+}
+
+// streamLimitInfo 检查 err 是携带 StreamLimitReason 的 ResourceExhausted，返回 ErrorInfo.Metadata
+func streamLimitInfo(t *testing.T, err error) map[string]string {
+	t.Helper()
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.ResourceExhausted {
+		t.Fatalf("错误 = %v，期望 ResourceExhausted", err)
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.GetReason() == server.StreamLimitReason {
+			return info.GetMetadata()
+		}
+	}
+	t.Fatalf("错误 %v 缺少 Reason 为 %s 的 ErrorInfo", err, server.StreamLimitReason)
+	return nil
+}
+
+func TestPutStreamMaxMessages(t *testing.T) {
+	t.Parallel()
+	cfg := server.DefaultConfig()
+	cfg.MaxStreamMessages = 3
+	ts := testutil.StartTestServerWithConfig(t, cfg)
+
+	// 恰好达到上限时正常结束，汇总响应包含总字节数；单条消息的大小不受该限制影响
+	big := strings.Repeat("x", 1000)
+	reply, err := putStream(t, ts, big, big, "end")
+	if err != nil {
+		t.Fatalf("3 条消息的 PutStream 失败: %v", err)
+	}
+	if !strings.Contains(reply.GetData(), "3 条消息") || !strings.Contains(reply.GetData(), "2003 字节") {
+		t.Errorf("汇总响应 = %q，期望包含消息数和总字节数", reply.GetData())
+	}
+
+	_, err = putStream(t, ts, "a", "b", "c", "d", "e")
+	md := streamLimitInfo(t, err)
+	if md["limit"] != "max_stream_messages" || md["messages_received"] != "4" || md["bytes_received"] != "4" {
+		t.Errorf("ErrorInfo.Metadata = %v，期望在第 4 条消息时触发 max_stream_messages", md)
+	}
+}
+
+func TestPutStreamMaxBytes(t *testing.T) {
+	t.Parallel()
+	cfg := server.DefaultConfig()
+	cfg.MaxStreamBytes = 150
+	ts := testutil.StartTestServerWithConfig(t, cfg)
+
+	// 消息数不受限制：150 条 1 字节的消息恰好达到上限，可以通过
+	many := make([]string, 150)
+	for i := range many {
+		many[i] = "z"
+	}
+	if _, err := putStream(t, ts, many...); err != nil {
+		t.Fatalf("累计 150 字节的 PutStream 失败: %v", err)
+	}
+
+	_, err := putStream(t, ts, strings.Repeat("a", 100), strings.Repeat("b", 50), "c", "d")
+	md := streamLimitInfo(t, err)
+	if md["limit"] != "max_stream_bytes" || md["messages_received"] != "3" || md["bytes_received"] != "151" {
+		t.Errorf("ErrorInfo.Metadata = %v，期望在累计 151 字节时触发 max_stream_bytes", md)
+	}
+}
+
+func TestPutStreamMaxDuration(t *testing.T) {
+	t.Parallel()
+	cfg := server.DefaultConfig()
+	cfg.MaxStreamDuration = 100 * time.Millisecond
+	ts := testutil.StartTestServerWithConfig(t, cfg)
+
+	// 在时限内结束的流不受影响
+	if _, err := putStream(t, ts, "quick"); err != nil {
+		t.Fatalf("时限内的 PutStream 失败: %v", err)
+	}
+
+	// 客户端发送一条消息后既不继续发送也不关闭，服务端应在时限到达时主动结束流
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := ts.Greeter(t).PutStream(ctx)
+	if err != nil {
+		t.Fatalf("打开 PutStream 失败: %v", err)
+	}
+	if err := stream.Send(&pb.StreamReqData{Data: "idle"}); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+	start := time.Now()
+	err = stream.RecvMsg(new(pb.StreamResData))
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("服务端在 %v 后才结束流，期望约 100ms", elapsed)
+	}
+	md := streamLimitInfo(t, err)
+	if md["limit"] != "max_stream_duration" || md["messages_received"] != "1" || md["bytes_received"] != "4" {
+		t.Errorf("ErrorInfo.Metadata = %v，期望触发 max_stream_duration 且已接收 1 条 4 字节", md)
+	}
+	if ms, err := strconv.Atoi(md["elapsed_ms"]); err != nil || ms < 100 {
+		t.Errorf("elapsed_ms = %q，期望不小于 100", md["elapsed_ms"])
+	}
+}
+
+func TestPutStreamUnlimitedByDefault(t *testing.T) {
+	t.Parallel()
+	ts := testutil.StartTestServer(t)
+
+	data := make([]string, 1000)
+	for i := range data {
+		data[i] = strings.Repeat("y", 100)
+	}
+	reply, err := putStream(t, ts, data...)
+	if err != nil {
+		t.Fatalf("默认配置下 PutStream 失败: %v", err)
+	}
+	if !strings.Contains(reply.GetData(), "1000 条消息") || !strings.Contains(reply.GetData(), "100000 字节") {
+		t.Errorf("汇总响应 = %q", reply.GetData())
+	}
+}
@@ -0,0 +1,171 @@
+// Package testutil 提供基于内存 bufconn 的测试服务端，测试之间不争用真实端口，可以并行运行
+//
+// 与客户端配合使用：
+//
+//	ts := testutil.StartTestServer(t)
+//	c := testutil.NewClient(t, ts.ClientConfig())
+//
+// testutil 同时依赖客户端和服务端模块，因此是独立的模块，客户端和服务端的生产代码不会引入对方的依赖
+package testutil
+
+import (
+	"context"
+	"errors"
+	"net"
+	"srpc/client"
+	pb "srpc/proto"
+	"srpc/server"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Target 测试服务端的拨号目标，必须配合 DialOption 使用
+const Target = "passthrough:///bufnet"
+
+// bufSize bufconn 的缓冲区大小
+const bufSize = 1 << 20
+
+// TestServer 运行在 bufconn 上的测试服务端，托管 Greeter、健康检查和反射服务
+// Stop 和 Restart 模拟服务端下线和重新上线，重启后 Server 指向新的实例，不能与读取 Server 的操作并发
+type TestServer struct {
+	Server *server.Server
+	cfg    server.Config
+	opts   []server.Option
+
+	mu        sync.Mutex
+	listener  *bufconn.Listener
+	serveDone chan error // 当前实例的 Serve 结果，已停止时为 nil
+}
+
+// StartTestServer 使用默认配置启动测试服务端，测试结束时通过 t.Cleanup 自动关闭
+func StartTestServer(t testing.TB, opts ...server.Option) *TestServer {
+	return StartTestServerWithConfig(t, server.DefaultConfig(), opts...)
+}
+
+// StartTestServerWithConfig 使用指定配置启动测试服务端，配置中的监听地址被忽略
+func StartTestServerWithConfig(t testing.TB, cfg server.Config, opts ...server.Option) *TestServer {
+	t.Helper()
+
+	ts := &TestServer{cfg: cfg, opts: opts}
+	ts.start(t)
+	t.Cleanup(func() { ts.stop(t) })
+	return ts
+}
+
+// Stop 停止测试服务端，之后的拨号立即失败，直到调用 Restart
+func (ts *TestServer) Stop(t testing.TB) {
+	t.Helper()
+	ts.stop(t)
+}
+
+// Restart 停止当前实例（如果仍在运行），使用相同的配置和选项在新的内存监听上启动新实例
+// 已建立的连接随旧实例断开，客户端重连时通过 DialOption 拨号到新实例
+func (ts *TestServer) Restart(t testing.TB) {
+	t.Helper()
+	ts.stop(t)
+	ts.start(t)
+}
+
+// start 创建服务端实例并在新的内存监听上开始服务
+func (ts *TestServer) start(t testing.TB) {
+	t.Helper()
+
+	s, err := server.New(ts.cfg, ts.opts...)
+	if err != nil {
+		t.Fatalf("创建测试服务端失败: %v", err)
+	}
+	reflection.Register(s)
+
+	lis := bufconn.Listen(bufSize)
+	serveDone := make(chan error, 1)
+	go func() {
+		serveDone <- s.Serve(lis)
+	}()
+
+	ts.mu.Lock()
+	ts.Server, ts.listener, ts.serveDone = s, lis, serveDone
+	ts.mu.Unlock()
+}
+
+// stop 停止当前实例并等待 Serve 返回，已停止时直接返回
+func (ts *TestServer) stop(t testing.TB) {
+	t.Helper()
+
+	ts.mu.Lock()
+	s, serveDone := ts.Server, ts.serveDone
+	ts.serveDone = nil
+	ts.mu.Unlock()
+	if serveDone == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.Stop(ctx)
+	// 测试自行调用 Stop 时，Serve 可能尚未开始就返回 ErrServerStopped
+	if err := <-serveDone; err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		t.Errorf("测试服务端异常退出: %v", err)
+	}
+}
+
+// DialOption 返回通过内存连接拨号到测试服务端的选项
+func (ts *TestServer) DialOption() grpc.DialOption {
+	return grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		// 每次拨号读取当前的监听，重启后拨号到新实例
+		ts.mu.Lock()
+		lis := ts.listener
+		ts.mu.Unlock()
+		return lis.DialContext(ctx)
+	})
+}
+
+// Conn 创建连接到测试服务端的原生 gRPC 连接，测试结束时自动关闭
+func (ts *TestServer) Conn(t testing.TB, opts ...grpc.DialOption) *grpc.ClientConn {
+	t.Helper()
+
+	opts = append([]grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		ts.DialOption(),
+	}, opts...)
+	conn, err := grpc.NewClient(Target, opts...)
+	if err != nil {
+		t.Fatalf("连接测试服务端失败: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// Greeter 创建连接到测试服务端的 Greeter 客户端存根，适合不经过 srpc 客户端直接验证服务端行为的测试
+func (ts *TestServer) Greeter(t testing.TB, opts ...grpc.DialOption) pb.GreeterClient {
+	t.Helper()
+	return pb.NewGreeterClient(ts.Conn(t, opts...))
+}
+
+// ClientConfig 返回通过内存连接拨号到测试服务端的客户端配置
+// 客户端不为健康检查和请求间隔填充默认值，这里设为适合测试的短间隔，其余字段为零值，由客户端填充默认值
+func (ts *TestServer) ClientConfig() client.Config {
+	return client.Config{
+		ServerAddr:        Target,
+		DialOptions:       []grpc.DialOption{ts.DialOption()},
+		KeepAliveInterval: time.Second,
+		RequestInterval:   100 * time.Millisecond,
+	}
+}
+
+// NewClient 使用 cfg 创建已连接的客户端，测试结束时自动关闭
+func NewClient(t testing.TB, cfg client.Config) *client.GRPCClient {
+	t.Helper()
+
+	c, err := client.NewGRPCClient(cfg)
+	if err != nil {
+		t.Fatalf("创建测试客户端失败: %v", err)
+	}
+	t.Cleanup(c.Shutdown)
+	return c
+}
@@ -0,0 +1,66 @@
+package testutil_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"srpc/client"
+	"srpc/pkg/testutil"
+	pb "srpc/proto"
+	"srpc/server"
+
+	"google.golang.org/grpc"
+)
+
+// slowSayHello 返回让前 n 次 SayHello 延迟 delay 再处理的服务端拦截器，n 小于 0 时每次都延迟；调用方取消时提前返回
+func slowSayHello(n int64, delay time.Duration, calls *atomic.Int64) server.Option {
+	return server.WithUnaryInterceptors(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if info.FullMethod != pb.Greeter_SayHello_FullMethodName {
+			return handler(ctx, req)
+		}
+		if call := calls.Add(1); n < 0 || call <= n {
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		return handler(ctx, req)
+	})
+}
+
+func TestOverallTimeoutSpansMultipleAttempts(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int64
+	ts := testutil.StartTestServer(t, slowSayHello(-1, time.Second, &calls))
+	cfg := fastRetryConfig(ts, 100)
+	cfg.PerAttemptTimeout = 100 * time.Millisecond
+	// 熔断器连续 5 次失败后开启，整体超时内的尝试次数需少于 5 次
+	cfg.OverallTimeout = 350 * time.Millisecond
+	c := testutil.NewClient(t, cfg)
+
+	start := time.Now()
+	_, err := c.SendNow(context.Background(), "overall")
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, client.ErrTimeout) {
+		t.Fatalf("错误 = %v，期望 ErrTimeout 类别", err)
+	}
+	var rpcErr *client.RPCError
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("错误 %T 不是 *client.RPCError", err)
+	}
+	// 单次超时后仍在整体预算内，应继续重试，直到整体超时截断最后一次尝试
+	if rpcErr.Attempts < 3 {
+		t.Errorf("Attempts = %d，期望整体超时内至少 3 次尝试", rpcErr.Attempts)
+	}
+	if got := calls.Load(); got < 3 {
+		t.Errorf("服务端收到 %d 次调用，期望至少 3 次", got)
+	}
+	if elapsed < cfg.OverallTimeout || elapsed > cfg.OverallTimeout+500*time.Millisecond {
+		t.Errorf("耗时 %v，期望略高于整体超时 %v", elapsed, cfg.OverallTimeout)
+	}
+}
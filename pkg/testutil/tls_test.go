@@ -0,0 +1,124 @@
+package testutil_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"srpc/pkg/testutil"
+	pb "srpc/proto"
+	"srpc/server"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// writeSelfSignedCert 在临时目录生成自签名证书和私钥，返回两个文件的路径
+func writeSelfSignedCert(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("生成私钥失败: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "srpc-test"},
+		DNSNames:     []string{"bufnet"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("生成证书失败: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("编码私钥失败: %v", err)
+	}
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "server.crt")
+	keyFile = filepath.Join(dir, "server.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("写入证书失败: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("写入私钥失败: %v", err)
+	}
+	return certFile, keyFile
+}
+
+// startTLSServer 启动启用 TLS 的测试服务端，minVersion 为 0 时使用服务端默认的最低版本
+func startTLSServer(t *testing.T, minVersion uint16) *testutil.TestServer {
+	t.Helper()
+	cfg := server.DefaultConfig()
+	cfg.TLSCertFile, cfg.TLSKeyFile = writeSelfSignedCert(t)
+	cfg.MinTLSVersion = minVersion
+	return testutil.StartTestServerWithConfig(t, cfg)
+}
+
+// sayHelloWithTLS 使用限定版本范围的 TLS 客户端调用一次 SayHello
+func sayHelloWithTLS(t *testing.T, ts *testutil.TestServer, minVersion, maxVersion uint16) error {
+	t.Helper()
+	creds := credentials.NewTLS(&tls.Config{
+		InsecureSkipVerify: true, // 自签名证书，只验证版本协商
+		MinVersion:         minVersion,
+		MaxVersion:         maxVersion,
+	})
+	conn, err := grpc.NewClient(testutil.Target, grpc.WithTransportCredentials(creds), ts.DialOption())
+	if err != nil {
+		t.Fatalf("创建连接失败: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	_, err = pb.NewGreeterClient(conn).SayHello(ctx, &pb.HelloRequest{Name: "tls"})
+	return err
+}
+
+func TestTLSRejectsTLS11Client(t *testing.T) {
+	t.Parallel()
+	ts := startTLSServer(t, 0)
+
+	err := sayHelloWithTLS(t, ts, tls.VersionTLS10, tls.VersionTLS11)
+	if err == nil {
+		t.Fatal("TLS 1.1 客户端的请求成功，期望握手被拒绝")
+	}
+	if !strings.Contains(err.Error(), "protocol version") {
+		t.Errorf("错误 = %v，期望握手因协议版本被拒绝", err)
+	}
+
+	// 同一服务端接受 TLS 1.2 及以上的客户端
+	if err := sayHelloWithTLS(t, ts, tls.VersionTLS12, tls.VersionTLS12); err != nil {
+		t.Errorf("TLS 1.2 客户端请求失败: %v", err)
+	}
+	if err := sayHelloWithTLS(t, ts, tls.VersionTLS13, tls.VersionTLS13); err != nil {
+		t.Errorf("TLS 1.3 客户端请求失败: %v", err)
+	}
+}
+
+func TestTLSMinVersion13RejectsTLS12Client(t *testing.T) {
+	t.Parallel()
+	ts := startTLSServer(t, tls.VersionTLS13)
+
+	if err := sayHelloWithTLS(t, ts, tls.VersionTLS12, tls.VersionTLS12); err == nil {
+		t.Error("最低版本为 TLS 1.3 时 TLS 1.2 客户端的请求成功")
+	}
+	if err := sayHelloWithTLS(t, ts, tls.VersionTLS13, tls.VersionTLS13); err != nil {
+		t.Errorf("TLS 1.3 客户端请求失败: %v", err)
+	}
+}
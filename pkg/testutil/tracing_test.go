@@ -0,0 +1,90 @@
+package testutil_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"srpc/pkg/testutil"
+	"srpc/server"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 全局 TracerProvider 只能有效设置一次，客户端包级 tracer 委托给首次设置的实现，
+// 因此整个测试进程共用一个导出器（-count 大于 1 时同样有效）
+var (
+	tracingOnce     sync.Once
+	tracingExporter = tracetest.NewInMemoryExporter()
+)
+
+// 导出器在测试之间共用，该测试不并行运行
+func TestTracingPropagatesClientSpanToServer(t *testing.T) {
+	tracingOnce.Do(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(tracingExporter)))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+	})
+	exporter := tracingExporter
+	exporter.Reset()
+
+	cfg := server.DefaultConfig()
+	cfg.EnableTracing = true
+	ts := testutil.StartTestServerWithConfig(t, cfg)
+
+	clientCfg := ts.ClientConfig()
+	clientCfg.EnableTracing = true
+	c := testutil.NewClient(t, clientCfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := c.SendNow(ctx, "tracing"); err != nil {
+		t.Fatalf("SendNow 失败: %v", err)
+	}
+
+	// 服务端 span 在响应写出后才结束，稍等导出
+	var logical, rpcClient, rpcServer tracetest.SpanStub
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		logical, rpcClient, rpcServer = tracetest.SpanStub{}, tracetest.SpanStub{}, tracetest.SpanStub{}
+		for _, s := range exporter.GetSpans() {
+			switch {
+			case s.Name == "SayHello":
+				logical = s
+			case s.Name == "Greeter/SayHello" && s.SpanKind == trace.SpanKindClient:
+				rpcClient = s
+			case s.Name == "Greeter/SayHello" && s.SpanKind == trace.SpanKindServer:
+				rpcServer = s
+			}
+		}
+		if rpcServer.SpanContext.IsValid() || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for name, s := range map[string]tracetest.SpanStub{"逻辑请求": logical, "客户端 RPC": rpcClient, "服务端": rpcServer} {
+		if !s.SpanContext.IsValid() {
+			t.Fatalf("未导出%s span，已导出: %v", name, exporter.GetSpans())
+		}
+	}
+
+	traceID := logical.SpanContext.TraceID()
+	if rpcClient.SpanContext.TraceID() != traceID || rpcServer.SpanContext.TraceID() != traceID {
+		t.Errorf("span 不在同一条链路上: 逻辑请求 %s，客户端 RPC %s，服务端 %s",
+			traceID, rpcClient.SpanContext.TraceID(), rpcServer.SpanContext.TraceID())
+	}
+	if got, want := rpcClient.Parent.SpanID(), logical.SpanContext.SpanID(); got != want {
+		t.Errorf("客户端 RPC span 的父 span = %s，期望逻辑请求 span %s", got, want)
+	}
+	if got, want := rpcServer.Parent.SpanID(), rpcClient.SpanContext.SpanID(); got != want {
+		t.Errorf("服务端 span 的父 span = %s，期望客户端 RPC span %s", got, want)
+	}
+	if !rpcServer.Parent.IsRemote() {
+		t.Error("服务端 span 的父 span 应来自远端传播")
+	}
+}
@@ -0,0 +1,151 @@
+package testutil_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"srpc/client"
+	"srpc/pkg/testutil"
+	"srpc/server"
+
+	"google.golang.org/grpc"
+)
+
+// startUnixServer 在 path 上启动监听 Unix domain socket 的服务端，等待 socket 文件出现后返回
+func startUnixServer(t *testing.T, path string) *server.Server {
+	t.Helper()
+	cfg := server.DefaultConfig()
+	cfg.ListenAddr = "unix://" + path
+	s, err := server.New(cfg)
+	if err != nil {
+		t.Fatalf("创建服务端失败: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- s.Start() }()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		s.Stop(ctx)
+		if err := <-done; err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			t.Errorf("服务端异常退出: %v", err)
+		}
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return s
+		}
+		select {
+		case err := <-done:
+			t.Fatalf("服务端启动失败: %v", err)
+		default:
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("等待 socket %s 就绪超时", path)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// unixClientConfig 返回通过 Unix domain socket 连接 path 的客户端配置
+func unixClientConfig(path string) client.Config {
+	return client.Config{
+		ServerAddr:        "unix://" + path,
+		DialTimeout:       2 * time.Second,
+		KeepAliveInterval: time.Second,
+		RequestInterval:   100 * time.Millisecond,
+	}
+}
+
+func TestUnixSocketRoundTrip(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "srpc.sock")
+	startUnixServer(t, path)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("socket 文件不存在: %v", err)
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		t.Errorf("%s 不是 socket 文件: %v", path, info.Mode())
+	}
+	if got := info.Mode().Perm(); got != server.DefaultConfig().SocketFileMode {
+		t.Errorf("socket 文件权限 = %v，期望 %v", got, server.DefaultConfig().SocketFileMode)
+	}
+
+	c := testutil.NewClient(t, unixClientConfig(path))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	reply, err := c.SendNow(ctx, "unix")
+	if err != nil {
+		t.Fatalf("SendNow 失败: %v", err)
+	}
+	if got, want := reply.GetMessage(), "Hello unix!"; got != want {
+		t.Errorf("响应 = %q，期望 %q", got, want)
+	}
+}
+
+func TestUnixSocketReplacesStaleFile(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "srpc.sock")
+
+	// 模拟异常退出：监听器关闭但不删除 socket 文件
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	lis.(*net.UnixListener).SetUnlinkOnClose(false)
+	lis.Close()
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("遗留的 socket 文件不存在: %v", err)
+	}
+
+	startUnixServer(t, path)
+	c := testutil.NewClient(t, unixClientConfig(path))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := c.SendNow(ctx, "stale"); err != nil {
+		t.Fatalf("SendNow 失败: %v", err)
+	}
+}
+
+func TestUnixSocketRefusesNonSocketFile(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "srpc.sock")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatalf("创建文件失败: %v", err)
+	}
+
+	cfg := server.DefaultConfig()
+	cfg.ListenAddr = "unix://" + path
+	s, err := server.New(cfg)
+	if err != nil {
+		t.Fatalf("创建服务端失败: %v", err)
+	}
+	if err := s.Start(); err == nil || !strings.Contains(err.Error(), "不是 socket 文件") {
+		t.Errorf("Start 错误 = %v，期望拒绝覆盖普通文件", err)
+	}
+}
+
+func TestUnixSocketMissingFile(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "missing.sock")
+
+	c, err := client.NewGRPCClient(unixClientConfig(path))
+	if err == nil {
+		c.Shutdown()
+		t.Fatal("socket 文件不存在时应返回错误")
+	}
+	if !strings.Contains(err.Error(), "unix socket 文件不存在") {
+		t.Errorf("错误 = %v，期望说明 socket 文件不存在", err)
+	}
+}
@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff 指数退避计算器，非并发安全，每个重试流程应使用独立实例
+// 第 n 次调用 Next 的上限为 min(MaxDelay, BaseDelay * Multiplier^n)，实际返回 [上限 * (1 - Jitter), 上限] 内的随机值
+// NewBackoff 创建的实例 Jitter 为 1（Full Jitter），即在 [0, 上限] 内随机
+type Backoff struct {
+	BaseDelay      time.Duration // 基础延迟
+	MaxDelay       time.Duration // 最大延迟
+	Multiplier     float64       // 增长倍数，小于等于 1 时按 2 处理
+	Jitter         float64       // 抖动比例（0-1），0 表示不抖动，超出范围时截断
+	MaxElapsedTime time.Duration // 从第一次 Next（或 Reset 后的第一次 Next）起允许退避的总时长，0 表示不限制，见 Expired
+	Clock          Clock         // 计算已用时长的时间源，为 nil 时使用真实时钟
+	attempt        int
+	start          time.Time
+}
+
+// NewBackoff 创建新的指数退避计算器，使用 Full Jitter
+func NewBackoff(baseDelay, maxDelay time.Duration, multiplier float64) *Backoff {
+	return &Backoff{
+		BaseDelay:  baseDelay,
+		MaxDelay:   maxDelay,
+		Multiplier: multiplier,
+		Jitter:     1,
+	}
+}
+
+// Next 返回下一次等待时间，并推进尝试次数
+func (b *Backoff) Next() time.Duration {
+	if b.start.IsZero() {
+		b.start = b.now()
+	}
+	ceiling := b.ceiling(b.attempt)
+	b.attempt++
+	if ceiling <= 0 {
+		return 0
+	}
+
+	jitter := min(max(b.Jitter, 0), 1)
+	spread := int64(float64(ceiling) * jitter)
+	if spread <= 0 {
+		return ceiling
+	}
+	return ceiling - time.Duration(rand.Int64N(spread+1))
+}
+
+// Attempt 返回已调用 Next 的次数
+func (b *Backoff) Attempt() int {
+	return b.attempt
+}
+
+// Expired 判断是否已超过 MaxElapsedTime，调用方应在重试前检查并放弃后续尝试
+// 未设置 MaxElapsedTime 或尚未调用过 Next 时返回 false
+func (b *Backoff) Expired() bool {
+	if b.MaxElapsedTime <= 0 || b.start.IsZero() {
+		return false
+	}
+	return b.now().Sub(b.start) >= b.MaxElapsedTime
+}
+
+// Reset 重置尝试次数和已用时长，下一次 Next 从基础延迟重新开始
+func (b *Backoff) Reset() {
+	b.attempt = 0
+	b.start = time.Time{}
+}
+
+// now 返回当前时间
+func (b *Backoff) now() time.Time {
+	if b.Clock == nil {
+		return time.Now()
+	}
+	return b.Clock.Now()
+}
+
+// ceiling 计算第 attempt 次等待时间的上限
+func (b *Backoff) ceiling(attempt int) time.Duration {
+	multiplier := b.Multiplier
+	if multiplier <= 1 {
+		multiplier = 2
+	}
+
+	delay := float64(b.BaseDelay) * math.Pow(multiplier, float64(attempt))
+	if b.MaxDelay > 0 && (delay > float64(b.MaxDelay) || math.IsInf(delay, 0)) {
+		return b.MaxDelay
+	}
+	if delay > math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(delay)
+}
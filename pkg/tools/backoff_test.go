@@ -0,0 +1,144 @@
+package tools
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffJitterBounds(t *testing.T) {
+	ceilings := []time.Duration{10, 20, 40, 80, 100, 100}
+	for _, jitter := range []float64{0.25, 0.5, 1} {
+		// 多次运行同一序列，确保每一步都落在 [上限 * (1 - Jitter), 上限] 内且确实发生抖动
+		varied := false
+		for range 200 {
+			b := &Backoff{BaseDelay: 10 * time.Millisecond, MaxDelay: 100 * time.Millisecond, Multiplier: 2, Jitter: jitter}
+			for i, c := range ceilings {
+				ceiling := c * time.Millisecond
+				lower := time.Duration(float64(ceiling) * (1 - jitter))
+				d := b.Next()
+				if d < lower || d > ceiling {
+					t.Fatalf("Jitter=%v 第 %d 次等待 %v，期望在 [%v, %v] 内", jitter, i+1, d, lower, ceiling)
+				}
+				if d != ceiling {
+					varied = true
+				}
+			}
+		}
+		if !varied {
+			t.Errorf("Jitter=%v 时所有等待都等于上限，没有抖动", jitter)
+		}
+	}
+}
+
+func TestBackoffWithoutJitter(t *testing.T) {
+	b := &Backoff{BaseDelay: 10 * time.Millisecond, MaxDelay: 100 * time.Millisecond, Multiplier: 3}
+	want := []time.Duration{10, 30, 90, 100, 100}
+	for i, w := range want {
+		if d := b.Next(); d != w*time.Millisecond {
+			t.Errorf("第 %d 次等待 %v，期望 %v", i+1, d, w*time.Millisecond)
+		}
+	}
+	if got := b.Attempt(); got != len(want) {
+		t.Errorf("Attempt = %d，期望 %d", got, len(want))
+	}
+}
+
+func TestBackoffCeilingGrowth(t *testing.T) {
+	b := &Backoff{BaseDelay: time.Millisecond, MaxDelay: time.Second, Multiplier: 1.5}
+	prev := time.Duration(0)
+	for attempt := range 100 {
+		c := b.ceiling(attempt)
+		if c < prev {
+			t.Fatalf("第 %d 次的上限 %v 小于前一次 %v", attempt, c, prev)
+		}
+		if c > b.MaxDelay {
+			t.Fatalf("第 %d 次的上限 %v 超过 MaxDelay", attempt, c)
+		}
+		prev = c
+	}
+	if prev != b.MaxDelay {
+		t.Errorf("多次增长后的上限 = %v，期望封顶在 %v", prev, b.MaxDelay)
+	}
+
+	// 次数极大导致浮点溢出时仍封顶在 MaxDelay
+	if c := b.ceiling(1 << 20); c != b.MaxDelay {
+		t.Errorf("溢出时的上限 = %v，期望 %v", c, b.MaxDelay)
+	}
+}
+
+func TestBackoffMultiplierDefaultsToTwo(t *testing.T) {
+	for _, m := range []float64{0, 1, 0.5, -3} {
+		b := &Backoff{BaseDelay: 10 * time.Millisecond, MaxDelay: time.Second, Multiplier: m}
+		for i, w := range []time.Duration{10, 20, 40, 80} {
+			if d := b.Next(); d != w*time.Millisecond {
+				t.Errorf("Multiplier=%v 第 %d 次等待 %v，期望 %v", m, i+1, d, w*time.Millisecond)
+			}
+		}
+	}
+}
+
+func TestBackoffExpired(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	b := &Backoff{BaseDelay: time.Millisecond, MaxDelay: time.Second, MaxElapsedTime: 5 * time.Second, Clock: clock}
+
+	// 计时从第一次 Next 开始
+	clock.Advance(time.Hour)
+	if b.Expired() {
+		t.Fatal("尚未调用 Next 时不应过期")
+	}
+	b.Next()
+	clock.Advance(5*time.Second - time.Nanosecond)
+	if b.Expired() {
+		t.Fatal("未到 MaxElapsedTime 时不应过期")
+	}
+	clock.Advance(time.Nanosecond)
+	if !b.Expired() {
+		t.Fatal("达到 MaxElapsedTime 后应过期")
+	}
+
+	// Reset 后重新计时
+	b.Reset()
+	if b.Expired() {
+		t.Fatal("Reset 后不应过期")
+	}
+	b.Next()
+	clock.Advance(time.Second)
+	if b.Expired() {
+		t.Error("Reset 后重新计时，1 秒后不应过期")
+	}
+
+	// 未设置 MaxElapsedTime 时从不过期
+	unlimited := &Backoff{BaseDelay: time.Millisecond, Clock: clock}
+	unlimited.Next()
+	clock.Advance(24 * time.Hour)
+	if unlimited.Expired() {
+		t.Error("未设置 MaxElapsedTime 时不应过期")
+	}
+}
+
+func TestBackoffReset(t *testing.T) {
+	b := &Backoff{BaseDelay: 10 * time.Millisecond, MaxDelay: time.Second, Multiplier: 2}
+	for range 5 {
+		b.Next()
+	}
+	b.Reset()
+	if got := b.Attempt(); got != 0 {
+		t.Errorf("Reset 后 Attempt = %d，期望 0", got)
+	}
+	if d := b.Next(); d != 10*time.Millisecond {
+		t.Errorf("Reset 后第一次等待 %v，期望从基础延迟 10ms 重新开始", d)
+	}
+}
+
+func TestNewBackoffFullJitter(t *testing.T) {
+	b := NewBackoff(100*time.Millisecond, time.Second, 2)
+	if b.Jitter != 1 {
+		t.Fatalf("NewBackoff 的 Jitter = %v，期望 1", b.Jitter)
+	}
+	for range 100 {
+		b.Reset()
+		if d := b.Next(); d < 0 || d > 100*time.Millisecond {
+			t.Fatalf("Full Jitter 的第一次等待 %v，期望在 [0, 100ms] 内", d)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock 时间源抽象，使熔断器、重试退避和重连循环等依赖时间的逻辑可以在测试中用 FakeClock 驱动
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	Sleep(d time.Duration)
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker 对应 time.Ticker，以方法形式暴露通道以便 FakeClock 实现
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// RealClock 使用 time 包的真实时钟
+type RealClock struct{}
+
+// Now 返回当前时间
+func (RealClock) Now() time.Time { return time.Now() }
+
+// After 等价于 time.After
+func (RealClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Sleep 等价于 time.Sleep
+func (RealClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// NewTicker 等价于 time.NewTicker
+func (RealClock) NewTicker(d time.Duration) Ticker { return realTicker{time.NewTicker(d)} }
+
+// realTicker 包装 time.Ticker
+type realTicker struct {
+	t *time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time { return t.t.C }
+func (t realTicker) Stop()               { t.t.Stop() }
+
+// FakeClock 手动推进的时钟，只有调用 Advance 时时间才会前进并触发到期的 After、Sleep 和 Ticker
+// 并发安全；与 time.Ticker 一样，接收方来不及读取时 Ticker 会丢弃多余的触发
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+// fakeWaiter 等待时钟到达 deadline 的 After、Sleep 或 Ticker，period 大于 0 时为 Ticker
+type fakeWaiter struct {
+	deadline time.Time
+	period   time.Duration
+	ch       chan time.Time
+}
+
+// NewFakeClock 创建以 now 为当前时间的假时钟
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now 返回假时钟的当前时间
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After 返回在时钟推进 d 后收到当前时间的通道
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w := &fakeWaiter{deadline: c.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		w.ch <- c.now
+		return w.ch
+	}
+	c.waiters = append(c.waiters, w)
+	return w.ch
+}
+
+// Sleep 阻塞直到时钟被推进 d
+func (c *FakeClock) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+// NewTicker 返回每推进 d 触发一次的 Ticker，d 必须大于 0
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("tools: FakeClock.NewTicker 的间隔必须大于 0")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w := &fakeWaiter{deadline: c.now.Add(d), period: d, ch: make(chan time.Time, 1)}
+	c.waiters = append(c.waiters, w)
+	return &fakeTicker{clock: c, w: w}
+}
+
+// Advance 将时钟推进 d，并触发所有到期的等待者
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	remaining := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.deadline.After(c.now) {
+			remaining = append(remaining, w)
+			continue
+		}
+		select {
+		case w.ch <- w.deadline:
+		default:
+		}
+		if w.period > 0 {
+			// 与 time.Ticker 一样跳过错过的周期，下一次在当前时间之后触发
+			for !w.deadline.After(c.now) {
+				w.deadline = w.deadline.Add(w.period)
+			}
+			remaining = append(remaining, w)
+		}
+	}
+	c.waiters = remaining
+}
+
+// Waiters 返回尚未触发的 After、Sleep 和 Ticker 数量，测试可据此等待被测 goroutine 进入等待后再 Advance
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// removeWaiter 移除已停止的 Ticker
+func (c *FakeClock) removeWaiter(target *fakeWaiter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, w := range c.waiters {
+		if w == target {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+// fakeTicker FakeClock 创建的 Ticker
+type fakeTicker struct {
+	clock *FakeClock
+	w     *fakeWaiter
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.w.ch }
+func (t *fakeTicker) Stop()               { t.clock.removeWaiter(t.w) }
@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// NodeIDEnv 指定默认 ID 生成器节点 ID 的环境变量
+const NodeIDEnv = "NODE_ID"
+
+// maxNodeID SimpleIDGenerator 支持的最大节点 ID
+const maxNodeID = simpleIDNodeMask
+
+// 默认节点 ID 的来源，见 DefaultNodeID
+const (
+	NodeIDSourceConfig   = "config"   // 通过 SetDefaultNodeID 指定
+	NodeIDSourceEnv      = "env"      // 来自 NODE_ID 环境变量
+	NodeIDSourceHostname = "hostname" // 由主机名哈希得出
+	NodeIDSourceRandom   = "random"   // 无法获取主机名时随机选择
+)
+
+// ErrDefaultIDGeneratorInitialized 默认 ID 生成器已创建，无法再修改节点 ID
+var ErrDefaultIDGeneratorInitialized = errors.New("默认 ID 生成器已初始化")
+
+var (
+	defaultNodeMu       sync.Mutex
+	defaultNodeInit     bool   // 默认生成器是否已创建
+	defaultNodeID       uint16 // 默认生成器使用的节点 ID
+	defaultNodeIDSource string // 默认节点 ID 的来源
+)
+
+// SetDefaultNodeID 指定默认 ID 生成器的节点 ID（0-1023），优先于 NODE_ID 环境变量
+// 必须在第一次调用 GetDefaultIDGenerator 或 GenerateID 之前调用，之后调用返回 ErrDefaultIDGeneratorInitialized
+func SetDefaultNodeID(nodeID uint16) error {
+	if nodeID > maxNodeID {
+		return fmt.Errorf("节点 ID %d 超出范围 0-%d", nodeID, maxNodeID)
+	}
+
+	defaultNodeMu.Lock()
+	defer defaultNodeMu.Unlock()
+
+	if defaultNodeInit {
+		return ErrDefaultIDGeneratorInitialized
+	}
+	defaultNodeID = nodeID
+	defaultNodeIDSource = NodeIDSourceConfig
+	return nil
+}
+
+// DefaultNodeID 返回默认 ID 生成器使用的节点 ID 及其来源（config、env、hostname 或 random）
+// 尚未创建默认生成器时会先创建，之后 SetDefaultNodeID 不再生效
+func DefaultNodeID() (uint16, string) {
+	GetDefaultIDGenerator()
+
+	defaultNodeMu.Lock()
+	defer defaultNodeMu.Unlock()
+	return defaultNodeID, defaultNodeIDSource
+}
+
+// resolveDefaultNodeID 确定默认生成器的节点 ID 并标记为已初始化
+// 优先级：SetDefaultNodeID > NODE_ID 环境变量 > 主机名哈希 > 随机值
+func resolveDefaultNodeID() uint16 {
+	defaultNodeMu.Lock()
+	defer defaultNodeMu.Unlock()
+
+	defaultNodeInit = true
+	if defaultNodeIDSource == NodeIDSourceConfig {
+		return defaultNodeID
+	}
+
+	if value := os.Getenv(NodeIDEnv); value != "" {
+		nodeID, err := strconv.ParseUint(value, 10, 16)
+		if err == nil && nodeID <= maxNodeID {
+			defaultNodeID, defaultNodeIDSource = uint16(nodeID), NodeIDSourceEnv
+			return defaultNodeID
+		}
+		slogger.Warn("环境变量 NODE_ID 无效，改用主机名生成节点 ID", map[string]interface{}{
+			"value": value,
+			"range": fmt.Sprintf("0-%d", maxNodeID),
+		})
+	}
+
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		defaultNodeID, defaultNodeIDSource = hostnameNodeID(hostname), NodeIDSourceHostname
+		return defaultNodeID
+	}
+
+	var b [2]byte
+	rand.Read(b[:])
+	defaultNodeID, defaultNodeIDSource = (uint16(b[0])|uint16(b[1])<<8)&maxNodeID, NodeIDSourceRandom
+	return defaultNodeID
+}
+
+// hostnameNodeID 将主机名哈希为节点 ID，同一主机（例如同一个 Pod）重启后保持不变
+func hostnameNodeID(hostname string) uint16 {
+	h := fnv.New32a()
+	h.Write([]byte(hostname))
+	return uint16(h.Sum32() % (maxNodeID + 1))
+}
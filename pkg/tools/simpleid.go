@@ -0,0 +1,131 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SimpleIDGenerator 的位布局
+const (
+	simpleIDNodeBits     = 10
+	simpleIDSequenceBits = 13
+	simpleIDNodeMask     = 1<<simpleIDNodeBits - 1
+	simpleIDSequenceMask = 1<<simpleIDSequenceBits - 1
+)
+
+// simpleIDMinTime 合法 ID 的最早时间戳，早于该时间的 ID 视为无效输入
+var simpleIDMinTime = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// simpleIDMaxFutureSkew ID 时间戳允许领先本机时钟的最大幅度，容忍节点之间的时钟偏差，超出时视为无效输入
+const simpleIDMaxFutureSkew = time.Minute
+
+// base62Alphabet base62 字符表
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// maxBase62Len 64 位无符号整数 base62 编码的最大长度，十进制形式的合法 ID 至少 19 位，二者不会混淆
+const maxBase62Len = 11
+
+// ErrInvalidSimpleID ID 格式错误或时间戳超出范围
+var ErrInvalidSimpleID = errors.New("无效的 ID")
+
+// SimpleIDInfo SimpleIDGenerator 生成的 ID 中包含的信息
+type SimpleIDInfo struct {
+	Timestamp time.Time // 生成时间（毫秒精度）
+	NodeID    uint16    // 节点 ID（0-1023）
+	Sequence  uint16    // 同一毫秒内的序列号（0-8191）
+}
+
+// String 以便于日志阅读的形式输出 ID 信息，例如 time=2024-05-01T08:00:00.123Z node=42 seq=7
+func (i SimpleIDInfo) String() string {
+	return fmt.Sprintf("time=%s node=%d seq=%d", i.Timestamp.UTC().Format("2006-01-02T15:04:05.000Z07:00"), i.NodeID, i.Sequence)
+}
+
+// composeSimpleID 组合ID：时间戳(41位) | 节点ID(10位) | 序列号(13位)
+func composeSimpleID(unixMilli int64, nodeID, sequence uint16) uint64 {
+	return uint64(unixMilli)<<(simpleIDNodeBits+simpleIDSequenceBits) |
+		uint64(nodeID&simpleIDNodeMask)<<simpleIDSequenceBits |
+		uint64(sequence&simpleIDSequenceMask)
+}
+
+// ParseSimpleID 解析 SimpleIDGenerator 生成的 ID，接受十进制和 base62 两种编码
+// 旧版本将 ID 按有符号整数输出（可能为负数），同样可以解析
+// 时间戳早于 2020 年或领先本机时钟超过 1 分钟的 ID 返回 ErrInvalidSimpleID，例如把其他格式的数字误当作 ID
+func ParseSimpleID(id string) (SimpleIDInfo, error) {
+	raw, err := parseSimpleIDValue(id)
+	if err != nil {
+		return SimpleIDInfo{}, err
+	}
+
+	ts := time.UnixMilli(int64(raw >> (simpleIDNodeBits + simpleIDSequenceBits)))
+	if ts.Before(simpleIDMinTime) || ts.After(time.Now().Add(simpleIDMaxFutureSkew)) {
+		return SimpleIDInfo{}, fmt.Errorf("%w: 时间戳 %s 超出范围", ErrInvalidSimpleID, ts.UTC().Format(time.RFC3339))
+	}
+	return SimpleIDInfo{
+		Timestamp: ts,
+		NodeID:    uint16(raw >> simpleIDSequenceBits & simpleIDNodeMask),
+		Sequence:  uint16(raw & simpleIDSequenceMask),
+	}, nil
+}
+
+// parseSimpleIDValue 将 ID 字符串还原为 64 位整数
+func parseSimpleIDValue(id string) (uint64, error) {
+	switch {
+	case id == "":
+		return 0, fmt.Errorf("%w: 空字符串", ErrInvalidSimpleID)
+	case strings.HasPrefix(id, "-"):
+		v, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidSimpleID, id)
+		}
+		return uint64(v), nil
+	case len(id) <= maxBase62Len:
+		v, err := ParseBase62(id)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrInvalidSimpleID, err)
+		}
+		return v, nil
+	default:
+		v, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidSimpleID, id)
+		}
+		return v, nil
+	}
+}
+
+// FormatBase62 将 64 位无符号整数编码为 base62 字符串
+func FormatBase62(v uint64) string {
+	if v == 0 {
+		return "0"
+	}
+	var buf [maxBase62Len]byte
+	i := len(buf)
+	for v > 0 {
+		i--
+		buf[i] = base62Alphabet[v%62]
+		v /= 62
+	}
+	return string(buf[i:])
+}
+
+// ParseBase62 解码 FormatBase62 生成的字符串，字符非法或溢出时返回错误
+func ParseBase62(s string) (uint64, error) {
+	if s == "" {
+		return 0, errors.New("base62: 空字符串")
+	}
+	var v uint64
+	for i := 0; i < len(s); i++ {
+		d := strings.IndexByte(base62Alphabet, s[i])
+		if d < 0 {
+			return 0, fmt.Errorf("base62: 非法字符 %q", s[i])
+		}
+		if v > (^uint64(0)-uint64(d))/62 {
+			return 0, fmt.Errorf("base62: %q 超出 64 位范围", s)
+		}
+		v = v*62 + uint64(d)
+	}
+	return v, nil
+}
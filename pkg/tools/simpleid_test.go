@@ -0,0 +1,169 @@
+package tools
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParseSimpleIDRoundTripBoundaries(t *testing.T) {
+	ts := time.Date(2025, 6, 1, 12, 0, 0, 123e6, time.UTC)
+	tests := []struct {
+		name     string
+		nodeID   uint16
+		sequence uint16
+	}{
+		{"zero", 0, 0},
+		{"max_sequence", 1, simpleIDSequenceMask},
+		{"max_node", maxNodeID, 0},
+		{"max_both", maxNodeID, simpleIDSequenceMask},
+	}
+	for _, tt := range tests {
+		raw := composeSimpleID(ts.UnixMilli(), tt.nodeID, tt.sequence)
+		for _, id := range []string{strconv.FormatUint(raw, 10), FormatBase62(raw)} {
+			info, err := ParseSimpleID(id)
+			if err != nil {
+				t.Fatalf("%s: ParseSimpleID(%q) 失败: %v", tt.name, id, err)
+			}
+			if !info.Timestamp.Equal(ts) || info.NodeID != tt.nodeID || info.Sequence != tt.sequence {
+				t.Errorf("%s: ParseSimpleID(%q) = %v，期望 time=%v node=%d seq=%d", tt.name, id, info, ts, tt.nodeID, tt.sequence)
+			}
+		}
+	}
+}
+
+func TestParseSimpleIDGeneratedBatch(t *testing.T) {
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	g := NewSimpleIDGenerator(maxNodeID, WithIDClock(NewFakeClock(start)), EncodeBase62())
+
+	// 同一毫秒内恰好用完 8192 个序列号，最后一个 ID 的序列号为 8191
+	ids := g.GenerateBatch(simpleIDSequenceMask + 1)
+	for i, id := range []string{ids[0], ids[len(ids)-1]} {
+		info, err := ParseSimpleID(id)
+		if err != nil {
+			t.Fatalf("ParseSimpleID(%q) 失败: %v", id, err)
+		}
+		wantSeq := uint16(0)
+		if i == 1 {
+			wantSeq = simpleIDSequenceMask
+		}
+		if !info.Timestamp.Equal(start) || info.NodeID != maxNodeID || info.Sequence != wantSeq {
+			t.Errorf("ParseSimpleID(%q) = %v，期望 node=%d seq=%d", id, info, maxNodeID, wantSeq)
+		}
+	}
+}
+
+func TestParseSimpleIDLegacySigned(t *testing.T) {
+	raw := composeSimpleID(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC).UnixMilli(), 7, 3)
+	if _, err := ParseSimpleID(strconv.FormatInt(int64(raw), 10)); err != nil {
+		t.Errorf("有符号十进制形式解析失败: %v", err)
+	}
+}
+
+func TestParseSimpleIDRejectsInvalid(t *testing.T) {
+	future := composeSimpleID(time.Now().Add(time.Hour).UnixMilli(), 1, 1)
+	nearFuture := composeSimpleID(time.Now().Add(10*time.Second).UnixMilli(), 1, 1)
+	tests := []struct {
+		name string
+		id   string
+	}{
+		{"empty", ""},
+		{"not_a_number", "12345678901234567890abc"},
+		{"bad_base62", "abc-"},
+		{"before_2020", strconv.FormatUint(composeSimpleID(time.Date(2019, 12, 31, 0, 0, 0, 0, time.UTC).UnixMilli(), 1, 1), 10)},
+		{"far_future", strconv.FormatUint(future, 10)},
+		{"far_future_base62", FormatBase62(future)},
+	}
+	for _, tt := range tests {
+		if _, err := ParseSimpleID(tt.id); !errors.Is(err, ErrInvalidSimpleID) {
+			t.Errorf("%s: ParseSimpleID(%q) 错误 = %v，期望 ErrInvalidSimpleID", tt.name, tt.id, err)
+		}
+	}
+
+	// 节点之间的少量时钟偏差仍然可以解析
+	if _, err := ParseSimpleID(strconv.FormatUint(nearFuture, 10)); err != nil {
+		t.Errorf("领先 10 秒的 ID 应可解析: %v", err)
+	}
+}
+
+func TestGenerateBatchAcrossMillisecondRollover(t *testing.T) {
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	g := NewSimpleIDGenerator(7, WithIDClock(clock))
+	last := generateIncreasing(t, g, 10, 0)
+
+	// 批量超过一毫秒的 8192 个序列号，生成器需等待时钟前进到下一毫秒
+	const n = 3*(simpleIDSequenceMask+1) + 100
+	done := make(chan []string, 1)
+	go func() { done <- g.GenerateBatch(n) }()
+
+	var ids []string
+	for advanced := 0; ids == nil; {
+		select {
+		case ids = <-done:
+			continue
+		default:
+		}
+		if clock.Waiters() > 0 {
+			clock.Advance(time.Millisecond)
+			advanced++
+		} else {
+			time.Sleep(time.Millisecond)
+		}
+		if advanced > 10 {
+			t.Fatal("时钟推进后 GenerateBatch 仍在等待")
+		}
+	}
+
+	if len(ids) != n {
+		t.Fatalf("生成 %d 个 ID，期望 %d", len(ids), n)
+	}
+	seen := make(map[string]bool, n)
+	for i, id := range ids {
+		if seen[id] {
+			t.Fatalf("第 %d 个 ID %s 重复", i, id)
+		}
+		seen[id] = true
+		v := simpleIDValue(t, id)
+		if v <= last {
+			t.Fatalf("第 %d 个 ID %d 不大于前一个 %d", i, v, last)
+		}
+		last = v
+	}
+
+	// 批量之后单个生成的 ID 继续递增，最后一个 ID 位于第 4 个毫秒
+	generateIncreasing(t, g, 10, last)
+	info, err := ParseSimpleID(ids[n-1])
+	if err != nil {
+		t.Fatalf("ParseSimpleID 失败: %v", err)
+	}
+	if want := start.Add(3 * time.Millisecond); !info.Timestamp.Equal(want) {
+		t.Errorf("最后一个 ID 的时间戳 = %v，期望 %v", info.Timestamp, want)
+	}
+}
+
+func BenchmarkSimpleIDGenerate(b *testing.B) {
+	g := NewSimpleIDGenerator(1)
+	for b.Loop() {
+		for range 100 {
+			g.Generate()
+		}
+	}
+}
+
+func BenchmarkSimpleIDGenerateBatch(b *testing.B) {
+	g := NewSimpleIDGenerator(1)
+	for b.Loop() {
+		g.GenerateBatch(100)
+	}
+}
+
+func BenchmarkSimpleIDGenerateParallel(b *testing.B) {
+	g := NewSimpleIDGenerator(1)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			g.GenerateBatch(100)
+		}
+	})
+}
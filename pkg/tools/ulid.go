@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"crypto/rand"
+	"sync"
+	"time"
+)
+
+// crockfordAlphabet ULID 使用的 Crockford Base32 字母表（不含 I、L、O、U）
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULIDGenerator 生成 ULID：48 位 Unix 毫秒时间戳 + 80 位随机数，编码为 26 位 Crockford Base32
+// 同一毫秒内随机部分在上一次的基础上加一（单调熵），随机部分溢出或时钟回拨时沿用上一次的时间戳继续递增，
+// 保证同一生成器生成的 ID 按字典序严格递增
+type ULIDGenerator struct {
+	mu       sync.Mutex
+	lastTime int64    // 上一次使用的毫秒时间戳
+	entropy  [10]byte // 上一次使用的 80 位随机部分
+}
+
+// NewULIDGenerator 创建新的 ULID 生成器
+func NewULIDGenerator() *ULIDGenerator {
+	return &ULIDGenerator{}
+}
+
+// Generate 生成一个 ULID，实现 IDGenerator
+func (g *ULIDGenerator) Generate() string {
+	id, err := g.generate()
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// generate 生成一个 ULID
+func (g *ULIDGenerator) generate() (string, error) {
+	var fresh [10]byte
+	if _, err := rand.Read(fresh[:]); err != nil {
+		return "", err
+	}
+
+	var id [16]byte
+	g.mu.Lock()
+	now := time.Now().UnixMilli()
+	if now > g.lastTime {
+		// 新的毫秒使用新的随机数，最高位清零，为同一毫秒内的递增保留至少 2^79 个值
+		g.lastTime = now
+		g.entropy = fresh
+		g.entropy[0] &= 0x7f
+	} else if !incrementEntropy(&g.entropy) {
+		// 同一毫秒内随机部分用尽：借用下一毫秒
+		g.lastTime++
+		g.entropy = fresh
+		g.entropy[0] &= 0x7f
+	}
+	ts := g.lastTime
+	copy(id[6:], g.entropy[:])
+	g.mu.Unlock()
+
+	// 时间戳（48 位，大端）
+	id[0] = byte(ts >> 40)
+	id[1] = byte(ts >> 32)
+	id[2] = byte(ts >> 24)
+	id[3] = byte(ts >> 16)
+	id[4] = byte(ts >> 8)
+	id[5] = byte(ts)
+
+	return encodeULID(id), nil
+}
+
+// incrementEntropy 将 80 位随机部分按大端整数加一，溢出时返回 false
+func incrementEntropy(b *[10]byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeULID 将 128 位 ULID 编码为 26 位 Crockford Base32 字符串
+// 首字符只承载最高 3 位，其余每个字符承载 5 位
+func encodeULID(id [16]byte) string {
+	var out [26]byte
+	// 以两个 64 位整数表示 128 位值，从低位开始每次取 5 位
+	hi := uint64(id[0])<<56 | uint64(id[1])<<48 | uint64(id[2])<<40 | uint64(id[3])<<32 |
+		uint64(id[4])<<24 | uint64(id[5])<<16 | uint64(id[6])<<8 | uint64(id[7])
+	lo := uint64(id[8])<<56 | uint64(id[9])<<48 | uint64(id[10])<<40 | uint64(id[11])<<32 |
+		uint64(id[12])<<24 | uint64(id[13])<<16 | uint64(id[14])<<8 | uint64(id[15])
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = crockfordAlphabet[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// defaultULIDGenerator GenerateULID 使用的进程级生成器
+var defaultULIDGenerator = NewULIDGenerator()
+
+// GenerateULID 生成一个可按字典序排序的 ULID，同一进程内生成的值严格递增
+func GenerateULID() (string, error) {
+	return defaultULIDGenerator.generate()
+}
+
+// GetULIDGenerator 获取 GenerateULID 使用的进程级 ULID 生成器
+func GetULIDGenerator() *ULIDGenerator {
+	return defaultULIDGenerator
+}
@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestULIDFormat(t *testing.T) {
+	g := NewULIDGenerator()
+	prev := g.Generate()
+	for i := range 10000 {
+		id := g.Generate()
+		if len(id) != 26 {
+			t.Fatalf("ULID %q 长度为 %d，期望 26", id, len(id))
+		}
+		for _, ch := range id {
+			if !strings.ContainsRune(crockfordAlphabet, ch) {
+				t.Fatalf("ULID %q 含有 Crockford Base32 以外的字符 %q", id, ch)
+			}
+		}
+		// 48 位时间戳决定首字符最大为 7
+		if id[0] > '7' {
+			t.Fatalf("ULID %q 的首字符超出 128 位范围", id)
+		}
+		if id <= prev {
+			t.Fatalf("第 %d 个 ULID %q 不大于前一个 %q", i+1, id, prev)
+		}
+		prev = id
+	}
+}
+
+func TestULIDConcurrentUnique(t *testing.T) {
+	g := NewULIDGenerator()
+	const goroutines, perGoroutine = 16, 100000 / 16
+
+	results := make([][]string, goroutines)
+	var wg sync.WaitGroup
+	for i := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids := make([]string, perGoroutine)
+			for j := range ids {
+				ids[j] = g.Generate()
+			}
+			results[i] = ids
+		}()
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool, goroutines*perGoroutine)
+	for i, ids := range results {
+		for j, id := range ids {
+			if seen[id] {
+				t.Fatalf("重复的 ULID %q", id)
+			}
+			seen[id] = true
+			// 同一 goroutine 内按生成顺序严格递增
+			if j > 0 && id <= ids[j-1] {
+				t.Fatalf("goroutine %d 的第 %d 个 ULID %q 不大于前一个 %q", i, j, id, ids[j-1])
+			}
+		}
+	}
+}
+
+func TestIncrementEntropyOverflow(t *testing.T) {
+	b := [10]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0xff}
+	if !incrementEntropy(&b) || b != [10]byte{0, 0, 0, 0, 0, 0, 0, 0, 1, 0} {
+		t.Errorf("进位结果 = %x", b)
+	}
+	b = [10]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	if incrementEntropy(&b) {
+		t.Error("80 位全为 1 时加一应报告溢出")
+	}
+}
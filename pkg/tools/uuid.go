@@ -4,62 +4,239 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	srpclog "srpc/pkg/log"
+	"strconv"
 	"sync"
 	"time"
 )
 
+// slogger ID 生成器使用的日志记录器
+var slogger = srpclog.NewLogger()
+
 // IDGenerator 是一个简单的ID生成器接口
 type IDGenerator interface {
 	Generate() string
 }
 
+// BatchGenerator 支持批量生成ID的 IDGenerator，批量生成只需一次加锁
+type BatchGenerator interface {
+	IDGenerator
+	GenerateBatch(n int) []string
+}
+
+// GenerateBatch 使用 g 生成 n 个ID，g 实现 BatchGenerator 时使用批量接口，否则逐个调用 Generate
+func GenerateBatch(g IDGenerator, n int) []string {
+	if bg, ok := g.(BatchGenerator); ok {
+		return bg.GenerateBatch(n)
+	}
+	if n <= 0 {
+		return nil
+	}
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = g.Generate()
+	}
+	return ids
+}
+
 // SimpleIDGenerator 基于时间戳和序列号的简单ID生成器
 type SimpleIDGenerator struct {
 	mu       sync.Mutex
 	lastTime int64
 	sequence uint16
 	nodeID   uint16
+	base62   bool  // 是否以 base62 输出
+	clock    Clock // 时间源，默认为真实时钟
+
+	waitOnRegression bool          // 时钟回拨时是否等待时钟追上，而不是沿用上一次的时间戳
+	behind           bool          // 当前时钟是否落后于 lastTime
+	regressions      int64         // 检测到的时钟回拨次数
+	maxSkew          time.Duration // 观察到的最大回拨幅度
+}
+
+// SimpleIDStats SimpleIDGenerator 的运行统计
+type SimpleIDStats struct {
+	ClockRegressions int64         // 检测到的时钟回拨次数，每次时钟从正常变为落后计一次
+	MaxClockSkew     time.Duration // 观察到的最大回拨幅度
+}
+
+// SimpleIDOption SimpleIDGenerator 的可选配置
+type SimpleIDOption func(*SimpleIDGenerator)
+
+// EncodeBase62 以 base62（0-9A-Za-z）而不是十进制输出 ID，长度不超过 11 个字符
+func EncodeBase62() SimpleIDOption {
+	return func(g *SimpleIDGenerator) {
+		g.base62 = true
+	}
+}
+
+// WithIDClock 指定生成 ID 使用的时钟，测试中可传入 FakeClock 模拟时钟回拨
+func WithIDClock(clock Clock) SimpleIDOption {
+	return func(g *SimpleIDGenerator) {
+		g.clock = clock
+	}
+}
+
+// WaitOnClockRegression 时钟回拨时阻塞等待时钟追上上一次的时间戳
+// 默认不等待，而是沿用上一次的时间戳继续递增序列号，序列号用尽时借用下一毫秒
+func WaitOnClockRegression() SimpleIDOption {
+	return func(g *SimpleIDGenerator) {
+		g.waitOnRegression = true
+	}
 }
 
 // NewSimpleIDGenerator 创建新的简单ID生成器
 // nodeID 用于区分不同节点（0-1023）
-func NewSimpleIDGenerator(nodeID uint16) *SimpleIDGenerator {
+func NewSimpleIDGenerator(nodeID uint16, opts ...SimpleIDOption) *SimpleIDGenerator {
 	if nodeID > 1023 {
 		nodeID = nodeID % 1024
 	}
-	return &SimpleIDGenerator{
+	g := &SimpleIDGenerator{
 		nodeID: nodeID,
+		clock:  RealClock{},
+	}
+	for _, opt := range opts {
+		opt(g)
 	}
+	return g
 }
 
 // Generate 生成一个基于时间戳的唯一ID
 // 格式：时间戳(41位) + 节点ID(10位) + 序列号(13位)
-// 总共64位，适合int64存储
+// 总共64位，以无符号十进制（或 base62）输出，可用 ParseSimpleID 解析
+// 时钟回拨时生成的 ID 仍然严格递增，见 WaitOnClockRegression
 func (g *SimpleIDGenerator) Generate() string {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
-	now := time.Now().UnixMilli()
+	return g.format(g.nextLocked())
+}
+
+// GenerateBase62 生成一个ID并以 base62 输出，不受 EncodeBase62 配置影响
+// 与 Generate 共享时间戳和序列号，同一生成器混用两种格式也不会产生重复的ID
+func (g *SimpleIDGenerator) GenerateBase62() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return FormatBase62(g.nextLocked())
+}
+
+// Base62IDGenerator 以 base62 输出 Generator 生成的ID，实现 IDGenerator
+// 适合复用默认生成器的序列（例如 GetDefaultIDGenerator），只改变输出格式
+type Base62IDGenerator struct {
+	Generator *SimpleIDGenerator
+}
+
+// Generate 生成一个 base62 编码的ID，可用 ParseSimpleID 解析
+func (b Base62IDGenerator) Generate() string {
+	return b.Generator.GenerateBase62()
+}
+
+// GenerateBatch 一次加锁生成 n 个ID，实现 BatchGenerator
+// 在当前毫秒内连续分配序列号，序列号用尽时与 Generate 一样顺延到后续毫秒，生成的 ID 严格递增
+func (g *SimpleIDGenerator) GenerateBatch(n int) []string {
+	if n <= 0 {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ids := make([]string, n)
+	ids[0] = g.format(g.nextLocked())
+	for i := 1; i < n; i++ {
+		g.incrementLocked()
+		ids[i] = g.format(composeSimpleID(g.lastTime, g.nodeID, g.sequence))
+	}
+	return ids
+}
+
+// nextLocked 读取时钟并分配下一个ID，调用方需持有 g.mu
+func (g *SimpleIDGenerator) nextLocked() uint64 {
+	now := g.clock.Now().UnixMilli()
+
+	if now < g.lastTime {
+		now = g.handleRegressionLocked(now)
+	} else {
+		g.behind = false
+	}
 
 	if now == g.lastTime {
-		g.sequence++
-		if g.sequence >= 8192 { // 13位序列号最大8191
-			// 等待下一毫秒
-			for now <= g.lastTime {
-				time.Sleep(time.Microsecond)
-				now = time.Now().UnixMilli()
-			}
-			g.sequence = 0
-		}
+		g.incrementLocked()
 	} else {
+		g.lastTime = now
 		g.sequence = 0
 	}
 
+	return composeSimpleID(g.lastTime, g.nodeID, g.sequence)
+}
+
+// incrementLocked 在 lastTime 所在毫秒内递增序列号，用尽时顺延到下一毫秒，调用方需持有 g.mu
+func (g *SimpleIDGenerator) incrementLocked() {
+	g.sequence++
+	if g.sequence < 8192 { // 13位序列号最大8191
+		return
+	}
+
+	now := g.lastTime
+	if g.behind {
+		// 时钟仍落后，借用下一毫秒，避免等待回拨的时间
+		now = g.lastTime + 1
+	}
+	// 等待下一毫秒
+	for now <= g.lastTime {
+		g.clock.Sleep(time.Microsecond)
+		now = g.clock.Now().UnixMilli()
+	}
 	g.lastTime = now
+	g.sequence = 0
+}
 
-	// 组合ID：时间戳(41位) | 节点ID(10位) | 序列号(13位)
-	id := (int64(now) << 23) | (int64(g.nodeID) << 13) | int64(g.sequence)
-	return fmt.Sprintf("%d", id)
+// format 按生成器配置将ID格式化为字符串
+func (g *SimpleIDGenerator) format(id uint64) string {
+	if g.base62 {
+		return FormatBase62(id)
+	}
+	return strconv.FormatUint(id, 10)
+}
+
+// handleRegressionLocked 处理时钟回拨，返回本次使用的时间戳，调用方需持有 g.mu
+func (g *SimpleIDGenerator) handleRegressionLocked(now int64) int64 {
+	skew := time.Duration(g.lastTime-now) * time.Millisecond
+	if !g.behind {
+		g.behind = true
+		g.regressions++
+		slogger.Warn("检测到时钟回拨", map[string]interface{}{
+			"skew":           skew.String(),
+			"wait_for_clock": g.waitOnRegression,
+		})
+	}
+	g.maxSkew = max(g.maxSkew, skew)
+
+	if !g.waitOnRegression {
+		return g.lastTime
+	}
+	for now < g.lastTime {
+		g.clock.Sleep(time.Millisecond)
+		now = g.clock.Now().UnixMilli()
+	}
+	g.behind = false
+	return now
+}
+
+// NodeID 返回生成器的节点 ID
+func (g *SimpleIDGenerator) NodeID() uint16 {
+	return g.nodeID
+}
+
+// Stats 返回时钟回拨等运行统计
+func (g *SimpleIDGenerator) Stats() SimpleIDStats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return SimpleIDStats{
+		ClockRegressions: g.regressions,
+		MaxClockSkew:     g.maxSkew,
+	}
 }
 
 // GenerateUUID 生成一个随机的UUID（版本4）
@@ -92,6 +269,14 @@ func MustGenerateUUID() string {
 	return uuid
 }
 
+// UUIDGenerator 生成随机 UUID（版本4）的 IDGenerator
+type UUIDGenerator struct{}
+
+// Generate 生成一个随机 UUID，实现 IDGenerator
+func (UUIDGenerator) Generate() string {
+	return MustGenerateUUID()
+}
+
 // ShortID 生成一个简短的随机ID（16字符）
 func ShortID() (string, error) {
 	var bytes [8]byte
@@ -102,6 +287,19 @@ func ShortID() (string, error) {
 	return hex.EncodeToString(bytes[:]), nil
 }
 
+// ShortIDGenerator 生成 16 位十六进制随机 ID 的 IDGenerator（64 位随机数）
+// 适合量大、对碰撞不敏感的追踪场景：约生成 6000 万个 ID 后任意两个相同的概率达到 1e-4，约 50 亿个时达到 50%
+type ShortIDGenerator struct{}
+
+// Generate 生成一个随机短 ID，实现 IDGenerator；随机源读取失败时 panic，与 MustGenerateUUID 一致
+func (ShortIDGenerator) Generate() string {
+	id, err := ShortID()
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
 // DefaultIDGenerator 默认的ID生成器实例
 var (
 	defaultGenerator *SimpleIDGenerator
@@ -109,16 +307,10 @@ var (
 )
 
 // GetDefaultIDGenerator 获取默认的ID生成器（单例）
+// 节点 ID 依次取自 SetDefaultNodeID、NODE_ID 环境变量和主机名哈希，见 DefaultNodeID
 func GetDefaultIDGenerator() *SimpleIDGenerator {
 	once.Do(func() {
-		// 使用一个随机的节点ID
-		var nodeID uint16
-		// 读取2个随机字节并转换为uint16
-		var b [2]byte
-		rand.Read(b[:])
-		// 使用小端字节序转换
-		nodeID = uint16(b[0]) | uint16(b[1])<<8
-		defaultGenerator = NewSimpleIDGenerator(nodeID % 1024)
+		defaultGenerator = NewSimpleIDGenerator(resolveDefaultNodeID())
 	})
 	return defaultGenerator
 }
@@ -0,0 +1,130 @@
+package tools
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+// simpleIDValue 将十进制 ID 还原为整数，便于比较先后
+func simpleIDValue(t *testing.T, id string) uint64 {
+	t.Helper()
+	v, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		t.Fatalf("ID %q 不是十进制整数: %v", id, err)
+	}
+	return v
+}
+
+// generateIncreasing 生成 n 个 ID 并检查大于 prev 且严格递增，返回最后一个 ID 的值
+func generateIncreasing(t *testing.T, g *SimpleIDGenerator, n int, prev uint64) uint64 {
+	t.Helper()
+	for i := range n {
+		v := simpleIDValue(t, g.Generate())
+		if v <= prev {
+			t.Fatalf("第 %d 个 ID %d 不大于前一个 %d", i+1, v, prev)
+		}
+		prev = v
+	}
+	return prev
+}
+
+func TestSimpleIDClockRegressionMidBurst(t *testing.T) {
+	clock := NewFakeClock(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	g := NewSimpleIDGenerator(7, WithIDClock(clock))
+
+	last := generateIncreasing(t, g, 100, 0)
+	clock.Advance(2 * time.Millisecond)
+	last = generateIncreasing(t, g, 100, last)
+
+	// 回拨 5ms 后继续生成：沿用上一次的时间戳递增序列号
+	clock.Advance(-5 * time.Millisecond)
+	last = generateIncreasing(t, g, 100, last)
+	// 仍落后时的再次回拨不重复计数，但更新最大幅度
+	clock.Advance(-2 * time.Millisecond)
+	last = generateIncreasing(t, g, 100, last)
+	if s := g.Stats(); s.ClockRegressions != 1 || s.MaxClockSkew != 7*time.Millisecond {
+		t.Errorf("Stats = %+v，期望 1 次回拨、最大幅度 7ms", s)
+	}
+
+	// 时钟追上后恢复正常，之后的回拨重新计数
+	clock.Advance(10 * time.Millisecond)
+	last = generateIncreasing(t, g, 100, last)
+	clock.Advance(-time.Millisecond)
+	generateIncreasing(t, g, 100, last)
+	if s := g.Stats(); s.ClockRegressions != 2 || s.MaxClockSkew != 7*time.Millisecond {
+		t.Errorf("Stats = %+v，期望 2 次回拨、最大幅度 7ms", s)
+	}
+}
+
+func TestSimpleIDRegressionBorrowsNextMillisecond(t *testing.T) {
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	g := NewSimpleIDGenerator(7, WithIDClock(clock))
+	last := generateIncreasing(t, g, 1, 0)
+
+	// 时钟落后期间序列号用尽，不能等待假时钟前进，应直接借用下一毫秒
+	clock.Advance(-time.Second)
+	last = generateIncreasing(t, g, simpleIDSequenceMask+10, last)
+
+	info, err := ParseSimpleID(strconv.FormatUint(last, 10))
+	if err != nil {
+		t.Fatalf("ParseSimpleID 失败: %v", err)
+	}
+	if want := start.Add(time.Millisecond); !info.Timestamp.Equal(want) {
+		t.Errorf("借用后的时间戳 = %v，期望 %v", info.Timestamp, want)
+	}
+}
+
+func TestSimpleIDWaitOnClockRegression(t *testing.T) {
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	g := NewSimpleIDGenerator(7, WithIDClock(clock), WaitOnClockRegression())
+	last := generateIncreasing(t, g, 1, 0)
+
+	clock.Advance(-3 * time.Millisecond)
+	done := make(chan string, 1)
+	go func() { done <- g.Generate() }()
+
+	// 等待期间每推进 1ms 检查一次时钟，追上回拨前的时间戳后才返回
+	advanced := 0
+	for {
+		if id, ok := waitForWaiterOrDone(t, clock, done); ok {
+			if advanced < 3 {
+				t.Fatalf("时钟推进 %dms 后即返回，期望等待追上回拨的 3ms", advanced)
+			}
+			if v := simpleIDValue(t, id); v <= last {
+				t.Fatalf("等待后生成的 ID %d 不大于回拨前的 %d", v, last)
+			}
+			break
+		}
+		if advanced > 10 {
+			t.Fatal("时钟追上后 Generate 仍在等待")
+		}
+		clock.Advance(time.Millisecond)
+		advanced++
+	}
+	if s := g.Stats(); s.ClockRegressions != 1 || s.MaxClockSkew != 3*time.Millisecond {
+		t.Errorf("Stats = %+v，期望 1 次回拨、最大幅度 3ms", s)
+	}
+}
+
+// waitForWaiterOrDone 等待被测 goroutine 在 clock 上进入等待或返回结果，返回结果时 ok 为 true
+func waitForWaiterOrDone(t *testing.T, clock *FakeClock, done <-chan string) (string, bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		select {
+		case id := <-done:
+			return id, true
+		default:
+		}
+		if clock.Waiters() > 0 {
+			return "", false
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("等待 goroutine 进入时钟等待超时")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
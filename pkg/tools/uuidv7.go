@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// UUIDv7Generator 按 RFC 9562 生成 UUIDv7：48 位 Unix 毫秒时间戳 + 版本位 + 12 位计数器 + 变体位 + 62 位随机数
+// 同一毫秒内计数器递增，计数器用尽或时钟回拨时沿用上一次的时间戳继续递增，保证同一生成器生成的 ID 严格递增
+type UUIDv7Generator struct {
+	mu       sync.Mutex
+	lastTime int64  // 上一次使用的毫秒时间戳
+	counter  uint16 // 12 位计数器（rand_a）
+}
+
+// NewUUIDv7Generator 创建新的 UUIDv7 生成器
+func NewUUIDv7Generator() *UUIDv7Generator {
+	return &UUIDv7Generator{}
+}
+
+// Generate 生成一个 UUIDv7，实现 IDGenerator
+func (g *UUIDv7Generator) Generate() string {
+	uuid, err := g.generate()
+	if err != nil {
+		panic(err)
+	}
+	return uuid
+}
+
+// generate 生成一个 UUIDv7
+func (g *UUIDv7Generator) generate() (string, error) {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[6:]); err != nil {
+		return "", err
+	}
+
+	g.mu.Lock()
+	now := time.Now().UnixMilli()
+	if now > g.lastTime {
+		// 新的毫秒以随机值开始计数，最高位清零，为同一毫秒内的递增保留至少 2048 个值
+		g.lastTime = now
+		g.counter = (uint16(uuid[6])<<8 | uint16(uuid[7])) & 0x07ff
+	} else {
+		// 同一毫秒或时钟回拨：计数器递增，用尽时借用下一毫秒
+		g.counter++
+		if g.counter > 0x0fff {
+			g.lastTime++
+			g.counter = 0
+		}
+	}
+	ts, counter := g.lastTime, g.counter
+	g.mu.Unlock()
+
+	// unix_ts_ms（48 位，大端）
+	uuid[0] = byte(ts >> 40)
+	uuid[1] = byte(ts >> 32)
+	uuid[2] = byte(ts >> 24)
+	uuid[3] = byte(ts >> 16)
+	uuid[4] = byte(ts >> 8)
+	uuid[5] = byte(ts)
+	// 版本 7 + rand_a（此处为计数器）
+	uuid[6] = 0x70 | byte(counter>>8)
+	uuid[7] = byte(counter)
+	// 变体为 RFC 9562（10xx），其余为随机数 rand_b
+	uuid[8] = (uuid[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%08x-%04x-%04x-%04x-%012x",
+		uuid[0:4],
+		uuid[4:6],
+		uuid[6:8],
+		uuid[8:10],
+		uuid[10:16]), nil
+}
+
+// defaultUUIDv7Generator GenerateUUIDv7 使用的进程级生成器
+var defaultUUIDv7Generator = NewUUIDv7Generator()
+
+// GenerateUUIDv7 生成一个按时间排序的 UUID（版本7），同一进程内生成的值严格递增
+func GenerateUUIDv7() (string, error) {
+	return defaultUUIDv7Generator.generate()
+}
+
+// GetUUIDv7Generator 获取 GenerateUUIDv7 使用的进程级 UUIDv7 生成器
+func GetUUIDv7Generator() *UUIDv7Generator {
+	return defaultUUIDv7Generator
+}
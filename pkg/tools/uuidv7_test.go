@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestUUIDv7VersionAndVariant(t *testing.T) {
+	g := NewUUIDv7Generator()
+	before := time.Now().Truncate(time.Millisecond)
+	for range 1000 {
+		id := g.Generate()
+		if len(id) != 36 || id[14] != '7' {
+			t.Fatalf("UUID %q 的版本字符不是 7", id)
+		}
+		if v := id[19]; v != '8' && v != '9' && v != 'a' && v != 'b' {
+			t.Fatalf("UUID %q 的变体不是 RFC 9562（10xx）", id)
+		}
+
+		// 与第三方实现交叉验证
+		u, err := uuid.Parse(id)
+		if err != nil {
+			t.Fatalf("google/uuid 无法解析 %q: %v", id, err)
+		}
+		if u.Version() != 7 || u.Variant() != uuid.RFC4122 {
+			t.Fatalf("google/uuid 解析 %q 得到版本 %d、变体 %v", id, u.Version(), u.Variant())
+		}
+		sec, nsec := u.Time().UnixTime()
+		if ts := time.Unix(sec, nsec); ts.Before(before) || ts.After(time.Now().Add(time.Second)) {
+			t.Fatalf("UUID %q 的时间戳 %v 不在生成时间附近", id, ts)
+		}
+	}
+}
+
+func TestUUIDv7MonotonicBurst(t *testing.T) {
+	g := NewUUIDv7Generator()
+	// 同一毫秒内生成的数量远超随机起点留出的 2048 个计数值，验证借用下一毫秒后仍然递增
+	prev := g.Generate()
+	for i := range 20000 {
+		id := g.Generate()
+		if id <= prev {
+			t.Fatalf("第 %d 个 UUID %q 不大于前一个 %q", i+1, id, prev)
+		}
+		prev = id
+	}
+}
+
+func TestUUIDv7ConcurrentUnique(t *testing.T) {
+	g := NewUUIDv7Generator()
+	const goroutines, perGoroutine = 8, 5000
+
+	var mu sync.Mutex
+	seen := make(map[string]bool, goroutines*perGoroutine)
+	var wg sync.WaitGroup
+	for range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids := make([]string, perGoroutine)
+			for i := range ids {
+				ids[i] = g.Generate()
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, id := range ids {
+				if seen[id] {
+					t.Errorf("重复的 UUID %q", id)
+				}
+				seen[id] = true
+			}
+		}()
+	}
+	wg.Wait()
+}
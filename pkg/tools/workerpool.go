@@ -0,0 +1,268 @@
+package tools
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// OverflowPolicy 任务队列已满时的处理策略
+type OverflowPolicy int
+
+const (
+	OverflowDropNewest OverflowPolicy = iota // 丢弃新提交的任务，Submit 返回 ErrQueueFull
+	OverflowDropOldest                       // 丢弃队列中最早的任务，为新任务腾出位置
+	OverflowBlock                            // 阻塞 Submit 直到队列有空位或 ctx 结束
+)
+
+// String 返回策略名称
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowDropNewest:
+		return "drop-newest"
+	case OverflowDropOldest:
+		return "drop-oldest"
+	case OverflowBlock:
+		return "block"
+	default:
+		return fmt.Sprintf("OverflowPolicy(%d)", int(p))
+	}
+}
+
+// ParseOverflowPolicy 解析 drop-newest、drop-oldest、block，空字符串返回 OverflowDropNewest
+func ParseOverflowPolicy(s string) (OverflowPolicy, error) {
+	switch s {
+	case "", "drop-newest":
+		return OverflowDropNewest, nil
+	case "drop-oldest":
+		return OverflowDropOldest, nil
+	case "block":
+		return OverflowBlock, nil
+	default:
+		return 0, fmt.Errorf("不支持的队列溢出策略: %q，可选 drop-newest、drop-oldest、block", s)
+	}
+}
+
+var (
+	// ErrQueueFull 队列已满，任务按 OverflowDropNewest 被丢弃
+	ErrQueueFull = errors.New("任务队列已满")
+	// ErrWorkerPoolClosed 工作池已关闭，不再接受任务
+	ErrWorkerPoolClosed = errors.New("工作池已关闭")
+)
+
+// WorkerPoolStats 工作池运行统计
+type WorkerPoolStats struct {
+	Workers    int   // 工作 goroutine 数
+	QueueDepth int   // 排队中的任务数
+	Running    int   // 正在执行的任务数
+	Completed  int64 // 已执行完成的任务数
+	Dropped    int64 // 因队列溢出或关闭而丢弃的任务数
+}
+
+// WorkerPoolOption WorkerPool 的可选配置
+type WorkerPoolOption func(*WorkerPool)
+
+// WithOverflowPolicy 设置队列已满时的处理策略，默认为 OverflowDropNewest
+func WithOverflowPolicy(policy OverflowPolicy) WorkerPoolOption {
+	return func(p *WorkerPool) {
+		p.policy = policy
+	}
+}
+
+// WithDropHandler 设置任务被丢弃时的回调，例如记录指标；在锁外调用
+func WithDropHandler(fn func()) WorkerPoolOption {
+	return func(p *WorkerPool) {
+		p.onDrop = fn
+	}
+}
+
+// WorkerPool 固定数量的工作 goroutine 加有界任务队列，队列按 FIFO 顺序执行
+// 创建后立即启动工作 goroutine，Close 或 Shutdown 后不再接受新任务
+type WorkerPool struct {
+	mu        sync.Mutex
+	notEmpty  *sync.Cond // 队列非空或已关闭
+	notFull   *sync.Cond // 队列有空位或已关闭
+	queue     list.List  // 排队中的任务（func()）
+	queueSize int
+	workers   int
+	policy    OverflowPolicy
+	onDrop    func()
+	closed    bool
+	running   int
+	completed int64
+	dropped   int64
+	done      chan struct{} // 所有工作 goroutine 退出后关闭
+	exited    int
+}
+
+// NewWorkerPool 创建并启动工作池，workers 和 queueSize 小于 1 时按 1 处理
+func NewWorkerPool(workers, queueSize int, opts ...WorkerPoolOption) *WorkerPool {
+	p := &WorkerPool{
+		workers:   max(workers, 1),
+		queueSize: max(queueSize, 1),
+		done:      make(chan struct{}),
+	}
+	p.notEmpty = sync.NewCond(&p.mu)
+	p.notFull = sync.NewCond(&p.mu)
+	for _, opt := range opts {
+		opt(p)
+	}
+	for i := 0; i < p.workers; i++ {
+		go p.worker()
+	}
+	return p
+}
+
+// Submit 提交任务，按溢出策略处理队列已满的情况
+// OverflowDropNewest 下队列已满时返回 ErrQueueFull；OverflowBlock 下 ctx 结束时返回 ctx.Err()
+func (p *WorkerPool) Submit(ctx context.Context, job func()) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return ErrWorkerPoolClosed
+	}
+
+	if p.queue.Len() >= p.queueSize {
+		switch p.policy {
+		case OverflowDropOldest:
+			p.queue.Remove(p.queue.Front())
+			p.dropped++
+			p.queue.PushBack(job)
+			p.notEmpty.Signal()
+			p.mu.Unlock()
+			p.notifyDrop(1)
+			return nil
+		case OverflowBlock:
+			if err := p.waitNotFullLocked(ctx); err != nil {
+				p.mu.Unlock()
+				return err
+			}
+		default:
+			p.dropped++
+			p.mu.Unlock()
+			p.notifyDrop(1)
+			return ErrQueueFull
+		}
+	}
+
+	p.queue.PushBack(job)
+	p.notEmpty.Signal()
+	p.mu.Unlock()
+	return nil
+}
+
+// waitNotFullLocked 等待队列出现空位，调用方需持有 p.mu；ctx 结束或工作池关闭时返回错误
+func (p *WorkerPool) waitNotFullLocked(ctx context.Context) error {
+	// sync.Cond 无法直接等待 ctx，ctx 结束时广播唤醒等待者
+	stop := context.AfterFunc(ctx, func() {
+		p.mu.Lock()
+		p.notFull.Broadcast()
+		p.mu.Unlock()
+	})
+	defer stop()
+
+	for p.queue.Len() >= p.queueSize && !p.closed {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		p.notFull.Wait()
+	}
+	if p.closed {
+		return ErrWorkerPoolClosed
+	}
+	return ctx.Err()
+}
+
+// worker 循环取出并执行任务，工作池关闭且队列为空时退出
+func (p *WorkerPool) worker() {
+	p.mu.Lock()
+	for {
+		for p.queue.Len() == 0 && !p.closed {
+			p.notEmpty.Wait()
+		}
+		if p.queue.Len() == 0 {
+			break
+		}
+		job := p.queue.Remove(p.queue.Front()).(func())
+		p.running++
+		p.notFull.Signal()
+		p.mu.Unlock()
+
+		job()
+
+		p.mu.Lock()
+		p.running--
+		p.completed++
+	}
+	p.exited++
+	if p.exited == p.workers {
+		close(p.done)
+	}
+	p.mu.Unlock()
+}
+
+// Close 停止接受新任务并丢弃排队中的任务，正在执行的任务继续运行直到完成，不阻塞
+func (p *WorkerPool) Close() {
+	p.mu.Lock()
+	if p.closed && p.queue.Len() == 0 {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	discarded := p.queue.Len()
+	p.queue.Init()
+	p.dropped += int64(discarded)
+	p.notEmpty.Broadcast()
+	p.notFull.Broadcast()
+	p.mu.Unlock()
+
+	p.notifyDrop(discarded)
+}
+
+// Shutdown 停止接受新任务，等待排队中和正在执行的任务完成
+// ctx 结束时丢弃仍在排队的任务并返回 ctx.Err()，正在执行的任务不会被中断
+func (p *WorkerPool) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	p.closed = true
+	p.notEmpty.Broadcast()
+	p.notFull.Broadcast()
+	p.mu.Unlock()
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		p.Close()
+		return ctx.Err()
+	}
+}
+
+// Wait 等待所有工作 goroutine 退出，需先调用 Close 或 Shutdown
+func (p *WorkerPool) Wait() {
+	<-p.done
+}
+
+// Stats 返回当前运行统计
+func (p *WorkerPool) Stats() WorkerPoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return WorkerPoolStats{
+		Workers:    p.workers,
+		QueueDepth: p.queue.Len(),
+		Running:    p.running,
+		Completed:  p.completed,
+		Dropped:    p.dropped,
+	}
+}
+
+// notifyDrop 为每个被丢弃的任务调用一次丢弃回调
+func (p *WorkerPool) notifyDrop(n int) {
+	if p.onDrop == nil {
+		return
+	}
+	for i := 0; i < n; i++ {
+		p.onDrop()
+	}
+}
@@ -0,0 +1,225 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockedPool 创建只有一个工作 goroutine、队列容量为 2 的工作池，并让唯一的工作 goroutine 阻塞在任务上
+// 返回记录后续任务执行顺序的函数和放行阻塞任务的函数
+func blockedPool(t *testing.T, opts ...WorkerPoolOption) (p *WorkerPool, record func(int) func(), executed func() []int, release func()) {
+	t.Helper()
+	p = NewWorkerPool(1, 2, opts...)
+	gate := make(chan struct{})
+	var once sync.Once
+	release = func() { once.Do(func() { close(gate) }) }
+	t.Cleanup(func() {
+		release()
+		p.Close()
+		p.Wait()
+	})
+
+	if err := p.Submit(context.Background(), func() { <-gate }); err != nil {
+		t.Fatalf("提交阻塞任务失败: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for p.Stats().Running != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("等待工作 goroutine 开始执行超时")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	var mu sync.Mutex
+	var order []int
+	record = func(i int) func() {
+		return func() {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, i)
+		}
+	}
+	executed = func() []int {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(order)
+	}
+	return p, record, executed, release
+}
+
+// shutdownPool 等待工作池执行完排队的任务
+func shutdownPool(t *testing.T, p *WorkerPool) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := p.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown 失败: %v", err)
+	}
+}
+
+func TestWorkerPoolDropNewest(t *testing.T) {
+	var drops atomic.Int64
+	p, record, executed, release := blockedPool(t, WithDropHandler(func() { drops.Add(1) }))
+	ctx := context.Background()
+
+	for i := 1; i <= 2; i++ {
+		if err := p.Submit(ctx, record(i)); err != nil {
+			t.Fatalf("提交任务 %d 失败: %v", i, err)
+		}
+	}
+	if err := p.Submit(ctx, record(3)); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("队列已满时 Submit 错误 = %v，期望 ErrQueueFull", err)
+	}
+	if s := p.Stats(); s.QueueDepth != 2 || s.Dropped != 1 || drops.Load() != 1 {
+		t.Errorf("Stats = %+v，丢弃回调 %d 次，期望排队 2、丢弃 1", s, drops.Load())
+	}
+
+	release()
+	shutdownPool(t, p)
+	if got := executed(); !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("执行顺序 = %v，期望 [1 2]", got)
+	}
+	if s := p.Stats(); s.Completed != 3 {
+		t.Errorf("Completed = %d，期望 3（含阻塞任务）", s.Completed)
+	}
+}
+
+func TestWorkerPoolDropOldest(t *testing.T) {
+	var drops atomic.Int64
+	p, record, executed, release := blockedPool(t, WithOverflowPolicy(OverflowDropOldest), WithDropHandler(func() { drops.Add(1) }))
+	ctx := context.Background()
+
+	for i := 1; i <= 4; i++ {
+		if err := p.Submit(ctx, record(i)); err != nil {
+			t.Fatalf("提交任务 %d 失败: %v", i, err)
+		}
+	}
+	if s := p.Stats(); s.QueueDepth != 2 || s.Dropped != 2 || drops.Load() != 2 {
+		t.Errorf("Stats = %+v，丢弃回调 %d 次，期望排队 2、丢弃 2", s, drops.Load())
+	}
+
+	release()
+	shutdownPool(t, p)
+	// 最早的任务 1、2 被挤出，剩下的任务仍按提交顺序执行
+	if got := executed(); !slices.Equal(got, []int{3, 4}) {
+		t.Errorf("执行顺序 = %v，期望 [3 4]", got)
+	}
+}
+
+func TestWorkerPoolBlock(t *testing.T) {
+	p, record, executed, release := blockedPool(t, WithOverflowPolicy(OverflowBlock))
+	ctx := context.Background()
+
+	for i := 1; i <= 2; i++ {
+		if err := p.Submit(ctx, record(i)); err != nil {
+			t.Fatalf("提交任务 %d 失败: %v", i, err)
+		}
+	}
+
+	// 队列已满时 Submit 阻塞，ctx 结束后返回 ctx.Err()，任务不入队
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := p.Submit(timeoutCtx, record(-1)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Submit 错误 = %v，期望 DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Submit 在 %v 后返回，期望阻塞到 ctx 超时", elapsed)
+	}
+
+	// 阻塞的 Submit 在队列出现空位后成功
+	submitted := make(chan error, 1)
+	go func() { submitted <- p.Submit(ctx, record(3)) }()
+	select {
+	case err := <-submitted:
+		t.Fatalf("队列已满时 Submit 提前返回: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	release()
+	select {
+	case err := <-submitted:
+		if err != nil {
+			t.Fatalf("队列出现空位后 Submit 失败: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("队列出现空位后 Submit 仍在阻塞")
+	}
+
+	shutdownPool(t, p)
+	if got := executed(); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("执行顺序 = %v，期望 [1 2 3]", got)
+	}
+	if s := p.Stats(); s.Dropped != 0 {
+		t.Errorf("Dropped = %d，阻塞策略不应丢弃任务", s.Dropped)
+	}
+}
+
+func TestWorkerPoolBlockedSubmitUnblocksOnClose(t *testing.T) {
+	p, record, _, _ := blockedPool(t, WithOverflowPolicy(OverflowBlock))
+	ctx := context.Background()
+	for i := 1; i <= 2; i++ {
+		if err := p.Submit(ctx, record(i)); err != nil {
+			t.Fatalf("提交任务 %d 失败: %v", i, err)
+		}
+	}
+
+	submitted := make(chan error, 1)
+	go func() { submitted <- p.Submit(ctx, record(3)) }()
+	time.Sleep(20 * time.Millisecond)
+	p.Close()
+	select {
+	case err := <-submitted:
+		if !errors.Is(err, ErrWorkerPoolClosed) {
+			t.Errorf("关闭后阻塞的 Submit 错误 = %v，期望 ErrWorkerPoolClosed", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("关闭后 Submit 仍在阻塞")
+	}
+	if s := p.Stats(); s.QueueDepth != 0 || s.Dropped != 2 {
+		t.Errorf("Stats = %+v，Close 应丢弃排队中的 2 个任务", s)
+	}
+}
+
+func TestWorkerPoolShutdownTimeoutDropsQueued(t *testing.T) {
+	p, record, executed, _ := blockedPool(t)
+	for i := 1; i <= 2; i++ {
+		if err := p.Submit(context.Background(), record(i)); err != nil {
+			t.Fatalf("提交任务 %d 失败: %v", i, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := p.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown 错误 = %v，期望 DeadlineExceeded", err)
+	}
+	if err := p.Submit(context.Background(), record(3)); !errors.Is(err, ErrWorkerPoolClosed) {
+		t.Errorf("关闭后 Submit 错误 = %v，期望 ErrWorkerPoolClosed", err)
+	}
+	if s := p.Stats(); s.QueueDepth != 0 || s.Dropped != 2 || s.Running != 1 {
+		t.Errorf("Stats = %+v，期望丢弃排队的 2 个任务、阻塞任务仍在执行", s)
+	}
+	if got := executed(); len(got) != 0 {
+		t.Errorf("超时后执行了被丢弃的任务 %v", got)
+	}
+}
+
+func TestParseOverflowPolicy(t *testing.T) {
+	for _, policy := range []OverflowPolicy{OverflowDropNewest, OverflowDropOldest, OverflowBlock} {
+		got, err := ParseOverflowPolicy(policy.String())
+		if err != nil || got != policy {
+			t.Errorf("ParseOverflowPolicy(%q) = %v, %v，期望 %v", policy.String(), got, err, policy)
+		}
+	}
+	if got, err := ParseOverflowPolicy(""); err != nil || got != OverflowDropNewest {
+		t.Errorf("空字符串解析为 %v, %v，期望 drop-newest", got, err)
+	}
+	if _, err := ParseOverflowPolicy("drop-all"); err == nil {
+		t.Error("不支持的策略应返回错误")
+	}
+}
@@ -9,6 +9,7 @@ package proto
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -21,6 +22,53 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// 流响应消息类型
+type StreamMessageKind int32
+
+const (
+	StreamMessageKind_STREAM_MESSAGE_KIND_DATA      StreamMessageKind = 0 // 业务数据，旧版本服务端不设置 kind 时即为该值
+	StreamMessageKind_STREAM_MESSAGE_KIND_HEARTBEAT StreamMessageKind = 1 // 双向流的服务端心跳，data 为空，客户端不交给应用
+)
+
+// Enum value maps for StreamMessageKind.
+var (
+	StreamMessageKind_name = map[int32]string{
+		0: "STREAM_MESSAGE_KIND_DATA",
+		1: "STREAM_MESSAGE_KIND_HEARTBEAT",
+	}
+	StreamMessageKind_value = map[string]int32{
+		"STREAM_MESSAGE_KIND_DATA":      0,
+		"STREAM_MESSAGE_KIND_HEARTBEAT": 1,
+	}
+)
+
+func (x StreamMessageKind) Enum() *StreamMessageKind {
+	p := new(StreamMessageKind)
+	*p = x
+	return p
+}
+
+func (x StreamMessageKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (StreamMessageKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_helloworld_proto_enumTypes[0].Descriptor()
+}
+
+func (StreamMessageKind) Type() protoreflect.EnumType {
+	return &file_helloworld_proto_enumTypes[0]
+}
+
+func (x StreamMessageKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use StreamMessageKind.Descriptor instead.
+func (StreamMessageKind) EnumDescriptor() ([]byte, []int) {
+	return file_helloworld_proto_rawDescGZIP(), []int{0}
+}
+
 type HelloRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // 1表示字段的序号不是值
@@ -156,6 +204,7 @@ func (x *StreamReqData) GetData() string {
 type StreamResData struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Data          string                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Kind          StreamMessageKind      `protobuf:"varint,2,opt,name=kind,proto3,enum=StreamMessageKind" json:"kind,omitempty"` // 消息类型
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -197,25 +246,298 @@ func (x *StreamResData) GetData() string {
 	return ""
 }
 
+func (x *StreamResData) GetKind() StreamMessageKind {
+	if x != nil {
+		return x.Kind
+	}
+	return StreamMessageKind_STREAM_MESSAGE_KIND_DATA
+}
+
+type PingRequest struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	ClientSendTimeUnixNano int64                  `protobuf:"varint,1,opt,name=client_send_time_unix_nano,json=clientSendTimeUnixNano,proto3" json:"client_send_time_unix_nano,omitempty"` // 客户端发送时间
+	Sequence               uint64                 `protobuf:"varint,2,opt,name=sequence,proto3" json:"sequence,omitempty"`                                                                 // 探测序列号
+	Token                  string                 `protobuf:"bytes,3,opt,name=token,proto3" json:"token,omitempty"`                                                                        // 可选的回显令牌，服务端原样返回
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *PingRequest) Reset() {
+	*x = PingRequest{}
+	mi := &file_helloworld_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PingRequest) ProtoMessage() {}
+
+func (x *PingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_helloworld_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PingRequest.ProtoReflect.Descriptor instead.
+func (*PingRequest) Descriptor() ([]byte, []int) {
+	return file_helloworld_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PingRequest) GetClientSendTimeUnixNano() int64 {
+	if x != nil {
+		return x.ClientSendTimeUnixNano
+	}
+	return 0
+}
+
+func (x *PingRequest) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *PingRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type PingReply struct {
+	state                     protoimpl.MessageState `protogen:"open.v1"`
+	ClientSendTimeUnixNano    int64                  `protobuf:"varint,1,opt,name=client_send_time_unix_nano,json=clientSendTimeUnixNano,proto3" json:"client_send_time_unix_nano,omitempty"`          // 原样返回的客户端发送时间
+	Sequence                  uint64                 `protobuf:"varint,2,opt,name=sequence,proto3" json:"sequence,omitempty"`                                                                          // 原样返回的探测序列号
+	ServerReceiveTimeUnixNano int64                  `protobuf:"varint,3,opt,name=server_receive_time_unix_nano,json=serverReceiveTimeUnixNano,proto3" json:"server_receive_time_unix_nano,omitempty"` // 服务端收到请求的时间
+	Token                     string                 `protobuf:"bytes,4,opt,name=token,proto3" json:"token,omitempty"`                                                                                 // 原样返回的回显令牌
+	UptimeSeconds             int64                  `protobuf:"varint,5,opt,name=uptime_seconds,json=uptimeSeconds,proto3" json:"uptime_seconds,omitempty"`                                           // 服务端已运行的秒数
+	Version                   string                 `protobuf:"bytes,6,opt,name=version,proto3" json:"version,omitempty"`                                                                             // 服务端版本号
+	unknownFields             protoimpl.UnknownFields
+	sizeCache                 protoimpl.SizeCache
+}
+
+func (x *PingReply) Reset() {
+	*x = PingReply{}
+	mi := &file_helloworld_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PingReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PingReply) ProtoMessage() {}
+
+func (x *PingReply) ProtoReflect() protoreflect.Message {
+	mi := &file_helloworld_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PingReply.ProtoReflect.Descriptor instead.
+func (*PingReply) Descriptor() ([]byte, []int) {
+	return file_helloworld_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PingReply) GetClientSendTimeUnixNano() int64 {
+	if x != nil {
+		return x.ClientSendTimeUnixNano
+	}
+	return 0
+}
+
+func (x *PingReply) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *PingReply) GetServerReceiveTimeUnixNano() int64 {
+	if x != nil {
+		return x.ServerReceiveTimeUnixNano
+	}
+	return 0
+}
+
+func (x *PingReply) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *PingReply) GetUptimeSeconds() int64 {
+	if x != nil {
+		return x.UptimeSeconds
+	}
+	return 0
+}
+
+func (x *PingReply) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+type ServerInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`                                     // 版本号，构建时通过 -ldflags 注入
+	GitCommit     string                 `protobuf:"bytes,2,opt,name=git_commit,json=gitCommit,proto3" json:"git_commit,omitempty"`                // 构建时的 Git 提交
+	GoVersion     string                 `protobuf:"bytes,3,opt,name=go_version,json=goVersion,proto3" json:"go_version,omitempty"`                // 编译使用的 Go 版本
+	StartTimeUnix int64                  `protobuf:"varint,4,opt,name=start_time_unix,json=startTimeUnix,proto3" json:"start_time_unix,omitempty"` // 服务端启动时间
+	UptimeSeconds int64                  `protobuf:"varint,5,opt,name=uptime_seconds,json=uptimeSeconds,proto3" json:"uptime_seconds,omitempty"`   // 已运行秒数
+	Compressors   []string               `protobuf:"bytes,6,rep,name=compressors,proto3" json:"compressors,omitempty"`                             // 已注册的压缩器
+	BuildTime     string                 `protobuf:"bytes,7,opt,name=build_time,json=buildTime,proto3" json:"build_time,omitempty"`                // 构建时间，构建时通过 -ldflags 注入
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerInfo) Reset() {
+	*x = ServerInfo{}
+	mi := &file_helloworld_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerInfo) ProtoMessage() {}
+
+func (x *ServerInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_helloworld_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerInfo.ProtoReflect.Descriptor instead.
+func (*ServerInfo) Descriptor() ([]byte, []int) {
+	return file_helloworld_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ServerInfo) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ServerInfo) GetGitCommit() string {
+	if x != nil {
+		return x.GitCommit
+	}
+	return ""
+}
+
+func (x *ServerInfo) GetGoVersion() string {
+	if x != nil {
+		return x.GoVersion
+	}
+	return ""
+}
+
+func (x *ServerInfo) GetStartTimeUnix() int64 {
+	if x != nil {
+		return x.StartTimeUnix
+	}
+	return 0
+}
+
+func (x *ServerInfo) GetUptimeSeconds() int64 {
+	if x != nil {
+		return x.UptimeSeconds
+	}
+	return 0
+}
+
+func (x *ServerInfo) GetCompressors() []string {
+	if x != nil {
+		return x.Compressors
+	}
+	return nil
+}
+
+func (x *ServerInfo) GetBuildTime() string {
+	if x != nil {
+		return x.BuildTime
+	}
+	return ""
+}
+
 var File_helloworld_proto protoreflect.FileDescriptor
 
 const file_helloworld_proto_rawDesc = "" +
 	"\n" +
-	"\x10helloworld.proto\"\"\n" +
+	"\x10helloworld.proto\x1a\x1bgoogle/protobuf/empty.proto\"\"\n" +
 	"\fHelloRequest\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\"&\n" +
 	"\n" +
 	"HelloReply\x12\x18\n" +
 	"\amessage\x18\x01 \x01(\tR\amessage\"#\n" +
 	"\rStreamReqData\x12\x12\n" +
-	"\x04data\x18\x01 \x01(\tR\x04data\"#\n" +
+	"\x04data\x18\x01 \x01(\tR\x04data\"K\n" +
 	"\rStreamResData\x12\x12\n" +
-	"\x04data\x18\x01 \x01(\tR\x04data2\xc0\x01\n" +
+	"\x04data\x18\x01 \x01(\tR\x04data\x12&\n" +
+	"\x04kind\x18\x02 \x01(\x0e2\x12.StreamMessageKindR\x04kind\"{\n" +
+	"\vPingRequest\x12:\n" +
+	"\x1aclient_send_time_unix_nano\x18\x01 \x01(\x03R\x16clientSendTimeUnixNano\x12\x1a\n" +
+	"\bsequence\x18\x02 \x01(\x04R\bsequence\x12\x14\n" +
+	"\x05token\x18\x03 \x01(\tR\x05token\"\xfc\x01\n" +
+	"\tPingReply\x12:\n" +
+	"\x1aclient_send_time_unix_nano\x18\x01 \x01(\x03R\x16clientSendTimeUnixNano\x12\x1a\n" +
+	"\bsequence\x18\x02 \x01(\x04R\bsequence\x12@\n" +
+	"\x1dserver_receive_time_unix_nano\x18\x03 \x01(\x03R\x19serverReceiveTimeUnixNano\x12\x14\n" +
+	"\x05token\x18\x04 \x01(\tR\x05token\x12%\n" +
+	"\x0euptime_seconds\x18\x05 \x01(\x03R\ruptimeSeconds\x12\x18\n" +
+	"\aversion\x18\x06 \x01(\tR\aversion\"\xf4\x01\n" +
+	"\n" +
+	"ServerInfo\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x1d\n" +
+	"\n" +
+	"git_commit\x18\x02 \x01(\tR\tgitCommit\x12\x1d\n" +
+	"\n" +
+	"go_version\x18\x03 \x01(\tR\tgoVersion\x12&\n" +
+	"\x0fstart_time_unix\x18\x04 \x01(\x03R\rstartTimeUnix\x12%\n" +
+	"\x0euptime_seconds\x18\x05 \x01(\x03R\ruptimeSeconds\x12 \n" +
+	"\vcompressors\x18\x06 \x03(\tR\vcompressors\x12\x1d\n" +
+	"\n" +
+	"build_time\x18\a \x01(\tR\tbuildTime*T\n" +
+	"\x11StreamMessageKind\x12\x1c\n" +
+	"\x18STREAM_MESSAGE_KIND_DATA\x10\x00\x12!\n" +
+	"\x1dSTREAM_MESSAGE_KIND_HEARTBEAT\x10\x012\x98\x02\n" +
 	"\aGreeter\x12&\n" +
 	"\bSayHello\x12\r.HelloRequest\x1a\v.HelloReply\x12-\n" +
 	"\tGetStream\x12\x0e.StreamReqData\x1a\x0e.StreamResData0\x01\x12-\n" +
 	"\tPutStream\x12\x0e.StreamReqData\x1a\x0e.StreamResData(\x01\x12/\n" +
-	"\tAllStream\x12\x0e.StreamReqData\x1a\x0e.StreamResData(\x010\x01B\tZ\a.;protob\x06proto3"
+	"\tAllStream\x12\x0e.StreamReqData\x1a\x0e.StreamResData(\x010\x01\x12 \n" +
+	"\x04Ping\x12\f.PingRequest\x1a\n" +
+	".PingReply\x124\n" +
+	"\rGetServerInfo\x12\x16.google.protobuf.Empty\x1a\v.ServerInfoB\tZ\a.;protob\x06proto3"
 
 var (
 	file_helloworld_proto_rawDescOnce sync.Once
@@ -229,27 +551,38 @@ func file_helloworld_proto_rawDescGZIP() []byte {
 	return file_helloworld_proto_rawDescData
 }
 
-var file_helloworld_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_helloworld_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_helloworld_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
 var file_helloworld_proto_goTypes = []any{
-	(*HelloRequest)(nil),  // 0: HelloRequest
-	(*HelloReply)(nil),    // 1: HelloReply
-	(*StreamReqData)(nil), // 2: StreamReqData
-	(*StreamResData)(nil), // 3: StreamResData
+	(StreamMessageKind)(0), // 0: StreamMessageKind
+	(*HelloRequest)(nil),   // 1: HelloRequest
+	(*HelloReply)(nil),     // 2: HelloReply
+	(*StreamReqData)(nil),  // 3: StreamReqData
+	(*StreamResData)(nil),  // 4: StreamResData
+	(*PingRequest)(nil),    // 5: PingRequest
+	(*PingReply)(nil),      // 6: PingReply
+	(*ServerInfo)(nil),     // 7: ServerInfo
+	(*emptypb.Empty)(nil),  // 8: google.protobuf.Empty
 }
 var file_helloworld_proto_depIdxs = []int32{
-	0, // 0: Greeter.SayHello:input_type -> HelloRequest
-	2, // 1: Greeter.GetStream:input_type -> StreamReqData
-	2, // 2: Greeter.PutStream:input_type -> StreamReqData
-	2, // 3: Greeter.AllStream:input_type -> StreamReqData
-	1, // 4: Greeter.SayHello:output_type -> HelloReply
-	3, // 5: Greeter.GetStream:output_type -> StreamResData
-	3, // 6: Greeter.PutStream:output_type -> StreamResData
-	3, // 7: Greeter.AllStream:output_type -> StreamResData
-	4, // [4:8] is the sub-list for method output_type
-	0, // [0:4] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0, // 0: StreamResData.kind:type_name -> StreamMessageKind
+	1, // 1: Greeter.SayHello:input_type -> HelloRequest
+	3, // 2: Greeter.GetStream:input_type -> StreamReqData
+	3, // 3: Greeter.PutStream:input_type -> StreamReqData
+	3, // 4: Greeter.AllStream:input_type -> StreamReqData
+	5, // 5: Greeter.Ping:input_type -> PingRequest
+	8, // 6: Greeter.GetServerInfo:input_type -> google.protobuf.Empty
+	2, // 7: Greeter.SayHello:output_type -> HelloReply
+	4, // 8: Greeter.GetStream:output_type -> StreamResData
+	4, // 9: Greeter.PutStream:output_type -> StreamResData
+	4, // 10: Greeter.AllStream:output_type -> StreamResData
+	6, // 11: Greeter.Ping:output_type -> PingReply
+	7, // 12: Greeter.GetServerInfo:output_type -> ServerInfo
+	7, // [7:13] is the sub-list for method output_type
+	1, // [1:7] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
 }
 
 func init() { file_helloworld_proto_init() }
@@ -262,13 +595,14 @@ func file_helloworld_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_helloworld_proto_rawDesc), len(file_helloworld_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   4,
+			NumEnums:      1,
+			NumMessages:   7,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_helloworld_proto_goTypes,
 		DependencyIndexes: file_helloworld_proto_depIdxs,
+		EnumInfos:         file_helloworld_proto_enumTypes,
 		MessageInfos:      file_helloworld_proto_msgTypes,
 	}.Build()
 	File_helloworld_proto = out.File
@@ -11,6 +11,7 @@ import (
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
 )
 
 // This is a compile-time assertion to ensure that this generated file
@@ -19,10 +20,12 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Greeter_SayHello_FullMethodName  = "/Greeter/SayHello"
-	Greeter_GetStream_FullMethodName = "/Greeter/GetStream"
-	Greeter_PutStream_FullMethodName = "/Greeter/PutStream"
-	Greeter_AllStream_FullMethodName = "/Greeter/AllStream"
+	Greeter_SayHello_FullMethodName      = "/Greeter/SayHello"
+	Greeter_GetStream_FullMethodName     = "/Greeter/GetStream"
+	Greeter_PutStream_FullMethodName     = "/Greeter/PutStream"
+	Greeter_AllStream_FullMethodName     = "/Greeter/AllStream"
+	Greeter_Ping_FullMethodName          = "/Greeter/Ping"
+	Greeter_GetServerInfo_FullMethodName = "/Greeter/GetServerInfo"
 )
 
 // GreeterClient is the client API for Greeter service.
@@ -36,6 +39,10 @@ type GreeterClient interface {
 	PutStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[StreamReqData, StreamResData], error)
 	// 双向流模式
 	AllStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[StreamReqData, StreamResData], error)
+	// 轻量级探活，用于健康检查和 RTT 测量
+	Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingReply, error)
+	// 查询服务端版本、运行时长等构建与运行信息
+	GetServerInfo(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ServerInfo, error)
 }
 
 type greeterClient struct {
@@ -101,6 +108,26 @@ func (c *greeterClient) AllStream(ctx context.Context, opts ...grpc.CallOption)
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type Greeter_AllStreamClient = grpc.BidiStreamingClient[StreamReqData, StreamResData]
 
+func (c *greeterClient) Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PingReply)
+	err := c.cc.Invoke(ctx, Greeter_Ping_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *greeterClient) GetServerInfo(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ServerInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ServerInfo)
+	err := c.cc.Invoke(ctx, Greeter_GetServerInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // GreeterServer is the server API for Greeter service.
 // All implementations should embed UnimplementedGreeterServer
 // for forward compatibility.
@@ -112,6 +139,10 @@ type GreeterServer interface {
 	PutStream(grpc.ClientStreamingServer[StreamReqData, StreamResData]) error
 	// 双向流模式
 	AllStream(grpc.BidiStreamingServer[StreamReqData, StreamResData]) error
+	// 轻量级探活，用于健康检查和 RTT 测量
+	Ping(context.Context, *PingRequest) (*PingReply, error)
+	// 查询服务端版本、运行时长等构建与运行信息
+	GetServerInfo(context.Context, *emptypb.Empty) (*ServerInfo, error)
 }
 
 // UnimplementedGreeterServer should be embedded to have
@@ -133,6 +164,12 @@ func (UnimplementedGreeterServer) PutStream(grpc.ClientStreamingServer[StreamReq
 func (UnimplementedGreeterServer) AllStream(grpc.BidiStreamingServer[StreamReqData, StreamResData]) error {
 	return status.Error(codes.Unimplemented, "method AllStream not implemented")
 }
+func (UnimplementedGreeterServer) Ping(context.Context, *PingRequest) (*PingReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method Ping not implemented")
+}
+func (UnimplementedGreeterServer) GetServerInfo(context.Context, *emptypb.Empty) (*ServerInfo, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetServerInfo not implemented")
+}
 func (UnimplementedGreeterServer) testEmbeddedByValue() {}
 
 // UnsafeGreeterServer may be embedded to opt out of forward compatibility for this service.
@@ -196,6 +233,42 @@ func _Greeter_AllStream_Handler(srv interface{}, stream grpc.ServerStream) error
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type Greeter_AllStreamServer = grpc.BidiStreamingServer[StreamReqData, StreamResData]
 
+func _Greeter_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreeterServer).Ping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Greeter_Ping_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreeterServer).Ping(ctx, req.(*PingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Greeter_GetServerInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreeterServer).GetServerInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Greeter_GetServerInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreeterServer).GetServerInfo(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Greeter_ServiceDesc is the grpc.ServiceDesc for Greeter service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -207,6 +280,14 @@ var Greeter_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SayHello",
 			Handler:    _Greeter_SayHello_Handler,
 		},
+		{
+			MethodName: "Ping",
+			Handler:    _Greeter_Ping_Handler,
+		},
+		{
+			MethodName: "GetServerInfo",
+			Handler:    _Greeter_GetServerInfo_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
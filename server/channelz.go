@@ -0,0 +1,14 @@
+package server
+
+import (
+	"strings"
+
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// operationalMethod 判断是否为标准健康检查或 channelz 等运维服务的方法，这些方法不受排空、并发限制和故障注入影响
+func operationalMethod(method string) bool {
+	return strings.HasPrefix(method, "/"+healthpb.Health_ServiceDesc.ServiceName+"/") ||
+		strings.HasPrefix(method, "/"+channelzpb.Channelz_ServiceDesc.ServiceName+"/")
+}
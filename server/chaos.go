@@ -0,0 +1,205 @@
+package server
+
+import (
+	"context"
+	"srpc/pkg/chaos"
+	"srpc/pkg/log"
+	pb "srpc/proto"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// 按请求覆盖故障注入的 metadata 键，仅在 Config.AllowInjectHeaders 为 true 时生效
+const (
+	InjectErrorHeader = "x-inject-error"    // 必定注入的状态码，例如 UNAVAILABLE 或 14
+	InjectDelayHeader = "x-inject-delay-ms" // 注入的延迟毫秒数，覆盖配置的延迟
+	// 同一请求 ID 的前 N 次尝试返回错误、之后成功，用于驱动客户端的重试和恢复；
+	// 错误的状态码由 x-inject-error 指定，未指定时为 UNAVAILABLE
+	// 计数依赖请求 ID（x-request-id），未携带请求 ID 的请求无法区分彼此，记录警告后不注入
+	InjectFailCountHeader = "x-inject-fail-count"
+)
+
+// failCountTTL 按请求 ID 记录的尝试次数的保留时间，超过后同一请求 ID 重新计数
+const failCountTTL = 5 * time.Minute
+
+// injector 在处理器执行前注入延迟和错误
+// 位于指标拦截器之外，注入的错误和延迟单独计入 injected_* 指标，不计入 failed_requests 和请求耗时
+type injector struct {
+	cfg          chaos.Config
+	allowHeaders bool
+	metrics      *Metrics
+	failCounts   *failCounter // 携带 x-inject-fail-count 的请求的尝试次数
+}
+
+// failCount 一个请求 ID 的尝试次数
+type failCount struct {
+	attempts int
+	lastSeen time.Time
+}
+
+// failCounter 按方法和请求 ID 统计携带 x-inject-fail-count 的尝试次数
+type failCounter struct {
+	mu      sync.Mutex
+	entries map[string]failCount
+	pruned  time.Time // 上次清理过期记录的时间
+}
+
+// next 返回 key 本次是第几次尝试，每隔 failCountTTL 清理一次长时间未出现的记录
+func (f *failCounter) next(key string, now time.Time) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if now.Sub(f.pruned) > failCountTTL {
+		for k, e := range f.entries {
+			if now.Sub(e.lastSeen) > failCountTTL {
+				delete(f.entries, k)
+			}
+		}
+		f.pruned = now
+	}
+	e := f.entries[key]
+	e.attempts++
+	e.lastSeen = now
+	f.entries[key] = e
+	return e.attempts
+}
+
+// newInjector 合并 WithChaos 选项和 Config 中的注入字段，Config 字段优先；未配置任何注入时返回 nil
+func newInjector(cfg Config, base chaos.Config, m *Metrics) *injector {
+	if cfg.FailureRate > 0 {
+		base.ErrorRate = cfg.FailureRate
+		if cfg.FailureCode != codes.OK {
+			base.Codes = []codes.Code{cfg.FailureCode}
+		}
+	}
+	if cfg.ResponseDelay > 0 {
+		base.Latency = cfg.ResponseDelay
+	}
+	if !base.Enabled() && !cfg.AllowInjectHeaders {
+		return nil
+	}
+	return &injector{
+		cfg:          base,
+		allowHeaders: cfg.AllowInjectHeaders,
+		metrics:      m,
+		failCounts:   &failCounter{entries: make(map[string]failCount), pruned: time.Now()},
+	}
+}
+
+// unaryInterceptor 一元 RPC 的注入拦截器
+func (i *injector) unaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := i.inject(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// streamInterceptor 流 RPC 的注入拦截器，只在建立流时注入一次
+func (i *injector) streamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := i.inject(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// inject 执行一次注入，返回注入的错误或 nil
+func (i *injector) inject(ctx context.Context, method string) error {
+	if chaosExempt(method) {
+		return nil
+	}
+
+	cfg := i.cfg
+	var forced error
+	if i.allowHeaders {
+		cfg, forced = i.applyHeaders(ctx, method, cfg)
+	}
+
+	if delay := cfg.Delay(); delay > 0 {
+		i.metrics.RecordInjectedDelay(delay)
+		slogger.InfoContext(ctx, "故障注入：延迟响应", map[string]interface{}{
+			"method": method,
+			"delay":  delay.String(),
+		})
+		if err := chaos.Sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+
+	err := forced
+	if err == nil {
+		err = cfg.Error()
+	}
+	if err != nil {
+		i.metrics.RecordInjectedFailure()
+		slogger.WarnContext(ctx, "故障注入：返回错误", map[string]interface{}{
+			"method": method,
+			"code":   status.Code(err).String(),
+		})
+	}
+	return err
+}
+
+// applyHeaders 读取请求 metadata 中的注入覆盖，格式错误的值被忽略
+func (i *injector) applyHeaders(ctx context.Context, method string, cfg chaos.Config) (chaos.Config, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return cfg, nil
+	}
+
+	if values := md.Get(InjectDelayHeader); len(values) > 0 {
+		if ms, err := strconv.Atoi(values[0]); err == nil && ms >= 0 {
+			cfg.Latency = time.Duration(ms) * time.Millisecond
+			cfg.LatencyJitter = 0
+		} else {
+			slogger.WarnContext(ctx, "忽略无效的延迟注入请求头", map[string]interface{}{"value": values[0]})
+		}
+	}
+
+	code, forceCode := codes.Unavailable, false
+	if values := md.Get(InjectErrorHeader); len(values) > 0 {
+		if parsed, err := chaos.ParseCode(values[0]); err == nil {
+			code, forceCode = parsed, true
+		} else {
+			slogger.WarnContext(ctx, "忽略无效的错误注入请求头", map[string]interface{}{"value": values[0]})
+		}
+	}
+
+	// 按请求 ID 计数，客户端对同一逻辑请求的重试共用一个计数
+	if values := md.Get(InjectFailCountHeader); len(values) > 0 {
+		if n, err := strconv.Atoi(values[0]); err == nil && n >= 0 {
+			requestID := log.RequestIDFromContext(ctx)
+			if requestID == "" {
+				// 没有请求 ID 时所有请求只能共用一个计数，结果取决于其他请求，不如明确地不注入
+				slogger.WarnContext(ctx, "失败次数注入需要请求 ID，忽略本次注入", map[string]interface{}{"method": method})
+				return cfg, nil
+			}
+			attempt := i.failCounts.next(method+"\x00"+requestID, time.Now())
+			if attempt <= n {
+				return cfg, chaos.InjectedError(code)
+			}
+			return cfg, nil
+		}
+		slogger.WarnContext(ctx, "忽略无效的失败次数注入请求头", map[string]interface{}{"value": values[0]})
+	}
+
+	if forceCode {
+		return cfg, chaos.InjectedError(code)
+	}
+	return cfg, nil
+}
+
+// chaosExempt 与客户端一致，探活、服务端信息查询、标准健康检查和 channelz 不注入故障
+func chaosExempt(method string) bool {
+	return method == pb.Greeter_Ping_FullMethodName || method == pb.Greeter_GetServerInfo_FullMethodName ||
+		operationalMethod(method)
+}
@@ -1,14 +1,201 @@
 package main
 
 import (
+	"compress/flate"
 	"log"
-	_ "srpc/pkg/compress" // 确保压缩器被注册
+	"os"
+	"srpc/pkg/chaos"
+	"srpc/pkg/compress" // 同时确保压缩器被注册
+	"srpc/pkg/tools"
 	"srpc/server"
+	"strconv"
+	"time"
 )
 
 func main() {
 	log.Println("启动gRPC服务端...")
-	if err := server.RunServer(); err != nil {
+	nodeID, source := tools.DefaultNodeID()
+	log.Printf("ID 生成器节点 ID: %d（来源: %s）", nodeID, source)
+	if err := server.RunServerWithConfig(loadConfig(), loadOptions()...); err != nil {
 		log.Fatalf("服务器运行失败: %v", err)
 	}
 }
+
+// loadConfig 从环境变量加载配置
+func loadConfig() server.Config {
+	cfg := server.DefaultConfig()
+
+	// 获取监听地址，支持 unix:///path/to.sock 形式的 Unix domain socket
+	if value := os.Getenv("LISTEN_ADDR"); value != "" {
+		cfg.ListenAddr = value
+	}
+
+	// 获取调试 HTTP 服务监听地址，默认不启动
+	cfg.DebugAddr = os.Getenv("DEBUG_ADDR")
+
+	// 获取实例 ID，默认使用主机名
+	cfg.InstanceID = os.Getenv("INSTANCE_ID")
+
+	// 获取 TLS 配置，证书和私钥都为空时使用明文连接
+	cfg.TLSCertFile = os.Getenv("TLS_CERT_FILE")
+	cfg.TLSKeyFile = os.Getenv("TLS_KEY_FILE")
+	if value := os.Getenv("TLS_MIN_VERSION"); value != "" {
+		if version, err := server.ParseTLSVersion(value); err == nil {
+			cfg.MinTLSVersion = version
+		} else {
+			log.Printf("环境变量 TLS_MIN_VERSION 无效，使用默认值 1.2: %v", err)
+		}
+	}
+	if value := os.Getenv("TLS_CIPHER_SUITES"); value != "" {
+		if suites, err := server.ParseCipherSuites(value); err == nil {
+			cfg.CipherSuites = suites
+		} else {
+			log.Fatalf("环境变量 TLS_CIPHER_SUITES 无效: %v", err)
+		}
+	}
+
+	// 获取请求签名配置，密钥为空时不校验签名
+	cfg.HMACSecret = os.Getenv("HMAC_SECRET")
+	cfg.HMACSignatureHeader = os.Getenv("HMAC_SIGNATURE_HEADER")
+	cfg.HMACTimestampHeader = os.Getenv("HMAC_TIMESTAMP_HEADER")
+	cfg.HMACMaxClockSkew = getEnvAsSeconds("HMAC_MAX_CLOCK_SKEW_SEC", cfg.HMACMaxClockSkew)
+
+	// 获取 Unix domain socket 文件权限（八进制），默认为 0660
+	if value := os.Getenv("SOCKET_FILE_MODE"); value != "" {
+		if mode, err := strconv.ParseUint(value, 8, 32); err == nil {
+			cfg.SocketFileMode = os.FileMode(mode)
+		} else {
+			log.Printf("环境变量 SOCKET_FILE_MODE 不是有效的八进制权限，使用默认值: %v", value)
+		}
+	}
+
+	// 获取是否启用链路追踪，默认为 false
+	if value := os.Getenv("ENABLE_TRACING"); value != "" {
+		if enabled, err := strconv.ParseBool(value); err == nil {
+			cfg.EnableTracing = enabled
+		} else {
+			log.Printf("环境变量 ENABLE_TRACING 不是有效的布尔值，使用默认值: %v", value)
+		}
+	}
+
+	// 获取是否注册 channelz 服务，默认为 false
+	if value := os.Getenv("ENABLE_CHANNELZ"); value != "" {
+		if enabled, err := strconv.ParseBool(value); err == nil {
+			cfg.EnableChannelz = enabled
+		} else {
+			log.Printf("环境变量 ENABLE_CHANNELZ 不是有效的布尔值，使用默认值: %v", value)
+		}
+	}
+
+	// 获取 deflate 压缩级别，用于压缩发往 deflate 客户端的响应
+	if err := compress.SetDeflateLevel(getEnvAsInt("DEFLATE_LEVEL", flate.DefaultCompression)); err != nil {
+		log.Printf("DEFLATE_LEVEL 无效，使用默认级别: %v", err)
+	}
+
+	// 获取请求字段长度限制
+	cfg.MaxNameLength = getEnvAsInt("MAX_NAME_LENGTH", cfg.MaxNameLength)
+	cfg.MaxStreamDataSize = getEnvAsInt("MAX_STREAM_DATA_SIZE", cfg.MaxStreamDataSize)
+
+	// 获取客户端流限制，默认不限制
+	cfg.MaxStreamMessages = getEnvAsInt("MAX_STREAM_MESSAGES", cfg.MaxStreamMessages)
+	cfg.MaxStreamBytes = getEnvAsInt("MAX_STREAM_BYTES", cfg.MaxStreamBytes)
+	cfg.MaxStreamDuration = getEnvAsSeconds("MAX_STREAM_DURATION_SEC", cfg.MaxStreamDuration)
+
+	// 获取双向流心跳间隔，默认不发送
+	cfg.StreamHeartbeatInterval = getEnvAsSeconds("STREAM_HEARTBEAT_INTERVAL_SEC", cfg.StreamHeartbeatInterval)
+
+	// 获取消息大小限制，默认使用 gRPC 默认值
+	cfg.MaxRecvMsgSize = getEnvAsInt("MAX_RECV_MSG_SIZE", cfg.MaxRecvMsgSize)
+	cfg.MaxSendMsgSize = getEnvAsInt("MAX_SEND_MSG_SIZE", cfg.MaxSendMsgSize)
+
+	// 获取服务器同时处理的 RPC 上限，默认为 0（不限制）
+	cfg.MaxConcurrentStreams = getEnvAsInt("MAX_CONCURRENT_STREAMS", cfg.MaxConcurrentStreams)
+
+	// 获取幂等去重配置，默认为 0（不去重）
+	cfg.IdempotencyTTL = getEnvAsSeconds("IDEMPOTENCY_TTL_SEC", cfg.IdempotencyTTL)
+	cfg.IdempotencyCacheSize = getEnvAsInt("IDEMPOTENCY_CACHE_SIZE", cfg.IdempotencyCacheSize)
+
+	// 获取传输层 keepalive 配置（秒），默认使用 gRPC 默认值
+	cfg.KeepAliveTime = getEnvAsSeconds("GRPC_KEEPALIVE_TIME_SEC", cfg.KeepAliveTime)
+	cfg.KeepAliveTimeout = getEnvAsSeconds("GRPC_KEEPALIVE_TIMEOUT_SEC", cfg.KeepAliveTimeout)
+	cfg.MaxConnectionAge = getEnvAsSeconds("MAX_CONNECTION_AGE_SEC", cfg.MaxConnectionAge)
+	cfg.MaxConnectionAgeGrace = getEnvAsSeconds("MAX_CONNECTION_AGE_GRACE_SEC", cfg.MaxConnectionAgeGrace)
+	cfg.KeepAliveMinTime = getEnvAsSeconds("GRPC_KEEPALIVE_MIN_TIME_SEC", cfg.KeepAliveMinTime)
+
+	// 获取故障注入配置，默认不注入
+	cfg.FailureRate = getEnvAsFloat("FAILURE_RATE", cfg.FailureRate)
+	if value := os.Getenv("FAILURE_CODE"); value != "" {
+		if code, err := chaos.ParseCode(value); err == nil {
+			cfg.FailureCode = code
+		} else {
+			log.Printf("环境变量 FAILURE_CODE 无效，使用默认状态码: %v", err)
+		}
+	}
+	cfg.ResponseDelay = time.Duration(getEnvAsInt("RESPONSE_DELAY_MS", 0)) * time.Millisecond
+	if value := os.Getenv("ALLOW_INJECT_HEADERS"); value != "" {
+		if allowed, err := strconv.ParseBool(value); err == nil {
+			cfg.AllowInjectHeaders = allowed
+		} else {
+			log.Printf("环境变量 ALLOW_INJECT_HEADERS 不是有效的布尔值，使用默认值: %v", value)
+		}
+	}
+
+	return cfg
+}
+
+// loadOptions 从环境变量加载 Config 之外的构造选项
+func loadOptions() []server.Option {
+	var opts []server.Option
+
+	// 获取故障注入配置，默认不注入；用于测试客户端的重试和熔断
+	chaosCfg := chaos.Config{
+		ErrorRate:     getEnvAsFloat("CHAOS_ERROR_RATE", 0),
+		Latency:       time.Duration(getEnvAsInt("CHAOS_LATENCY_MS", 0)) * time.Millisecond,
+		LatencyJitter: time.Duration(getEnvAsInt("CHAOS_LATENCY_JITTER_MS", 0)) * time.Millisecond,
+	}
+	if value := os.Getenv("CHAOS_CODES"); value != "" {
+		if codes, err := chaos.ParseCodes(value); err == nil {
+			chaosCfg.Codes = codes
+		} else {
+			log.Printf("环境变量 CHAOS_CODES 无效，使用默认状态码: %v", err)
+		}
+	}
+	if chaosCfg.Enabled() {
+		opts = append(opts, server.WithChaos(chaosCfg))
+	}
+
+	return opts
+}
+
+// getEnvAsInt 获取整数类型的环境变量，解析失败时返回默认值
+func getEnvAsInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	intValue, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("环境变量 %s 不是有效的整数，使用默认值: %v", key, value)
+		return defaultValue
+	}
+	return intValue
+}
+
+// getEnvAsSeconds 获取以秒为单位的时长环境变量，解析失败时返回默认值
+func getEnvAsSeconds(key string, defaultValue time.Duration) time.Duration {
+	return time.Duration(getEnvAsInt(key, int(defaultValue/time.Second))) * time.Second
+}
+
+// getEnvAsFloat 获取浮点数类型的环境变量，解析失败时返回默认值
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	floatValue, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Printf("环境变量 %s 不是有效的浮点数，使用默认值: %v", key, value)
+		return defaultValue
+	}
+	return floatValue
+}
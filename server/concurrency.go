@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// concurrencyRetryDelay 并发超限时建议客户端等待的时间，通过 RetryInfo 返回
+const concurrencyRetryDelay = 100 * time.Millisecond
+
+// semaphore 基于带缓冲 channel 的计数信号量，与客户端的 Semaphore 实现相同，服务端只需要非阻塞获取
+type semaphore struct {
+	slots chan struct{}
+}
+
+// newSemaphore 创建容量为 n 的信号量，n 小于等于 0 时返回 nil 表示不限制
+func newSemaphore(n int) *semaphore {
+	if n <= 0 {
+		return nil
+	}
+	return &semaphore{slots: make(chan struct{}, n)}
+}
+
+// tryAcquire 尝试立即获取一个许可，不阻塞
+func (s *semaphore) tryAcquire() bool {
+	select {
+	case s.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// release 归还一个许可，必须与成功的 tryAcquire 成对调用
+func (s *semaphore) release() {
+	<-s.slots
+}
+
+// concurrencyLimitUnaryInterceptor 限制同时处理的 RPC 总数，超限时立即返回 ResourceExhausted
+func concurrencyLimitUnaryInterceptor(sem *semaphore, m *Metrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if concurrencyExempt(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		if !sem.tryAcquire() {
+			return nil, rejectOverLimit(ctx, info.FullMethod, sem, m)
+		}
+		defer sem.release()
+		return handler(ctx, req)
+	}
+}
+
+// concurrencyLimitStreamInterceptor 与一元拦截器共享同一个信号量，流在整个生命周期内占用一个许可
+func concurrencyLimitStreamInterceptor(sem *semaphore, m *Metrics) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if concurrencyExempt(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		if !sem.tryAcquire() {
+			return rejectOverLimit(ss.Context(), info.FullMethod, sem, m)
+		}
+		defer sem.release()
+		return handler(srv, ss)
+	}
+}
+
+// rejectOverLimit 记录并返回并发超限错误，错误携带 RetryInfo，客户端按建议的延迟重试
+func rejectOverLimit(ctx context.Context, method string, sem *semaphore, m *Metrics) error {
+	m.RecordConcurrencyRejected()
+	slogger.WarnContext(ctx, "并发请求数已达上限，拒绝请求", map[string]interface{}{
+		"method": method,
+		"limit":  cap(sem.slots),
+	})
+	return OverloadedError("服务端并发请求数已达上限", concurrencyRetryDelay)
+}
+
+// concurrencyExempt 标准健康检查和 channelz 不受并发限制，避免过载时被负载均衡器误判为宕机或无法排查
+func concurrencyExempt(method string) bool {
+	return operationalMethod(method)
+}
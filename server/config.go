@@ -0,0 +1,76 @@
+package server
+
+import (
+	"os"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Config 服务端配置
+type Config struct {
+	ListenAddr        string      // 监听地址，例如 ":50051"，或 "unix:///path/to.sock" 使用 Unix domain socket
+	SocketFileMode    os.FileMode // Unix domain socket 文件权限，默认 0660
+	EnableTracing     bool        // 是否启用 OpenTelemetry 链路追踪，延续客户端传播的追踪上下文
+	EnableChannelz    bool        // 是否注册 channelz 服务，供 grpcdebug 等工具查看连接、子通道和 socket 状态
+	MaxNameLength     int         // SayHello 请求 name 字段的最大长度（字节），默认 256
+	MaxStreamDataSize int         // 流消息 data 字段的最大大小（字节），默认 4096
+	MaxStreamMessages int         // 单个 PutStream 最多接收的消息数，超出时返回 ResourceExhausted；0 表示不限制
+	MaxStreamBytes    int         // 单个 PutStream 累计接收的 data 字节数上限，超出时返回 ResourceExhausted；0 表示不限制
+	MaxRecvMsgSize    int         // 单条接收消息解压后的最大字节数，0 表示使用 gRPC 默认值（4MB），不能为负数
+	MaxSendMsgSize    int         // 单条发送消息压缩后的最大字节数，0 表示使用 gRPC 默认值（不限制），不能为负数
+	// 整个服务器同时处理的 RPC 上限（一元调用和流合计，健康检查除外），超限时返回 ResourceExhausted；0 表示不限制
+	// 与 HTTP/2 的单连接并发流限制 grpc.MaxConcurrentStreams 不同，此限制跨所有连接生效
+	MaxConcurrentStreams int
+	// AllStream 双向流上服务端发送心跳的间隔，心跳消息的 kind 为 STREAM_MESSAGE_KIND_HEARTBEAT，客户端据此更快发现失联的对端；0 表示不发送
+	StreamHeartbeatInterval time.Duration
+	// 单个 PutStream 的最长持续时间，到期时即使客户端仍在发送或空闲等待也返回 ResourceExhausted；0 表示不限制
+	MaxStreamDuration time.Duration
+	// 调试 HTTP 服务监听地址（例如 localhost:6060），提供 /debug/pprof/、/debug/vars 和 /debug/grpc；为空时不启动
+	// 该服务不做鉴权，应只监听本机或内网地址
+	DebugAddr string
+	// 实例 ID，通过响应 header x-server-instance 返回给客户端，便于确认负载均衡后由哪个副本处理；为空时使用主机名
+	InstanceID string
+	// 按客户端携带的幂等键（x-idempotency-key）对一元 RPC 去重的时间窗口，窗口内的重复请求直接返回首次成功的响应；0 表示不去重
+	IdempotencyTTL       time.Duration
+	IdempotencyCacheSize int // 幂等表最多保留的键数，超出时淘汰最早的键（默认 10000）
+
+	// TLS，证书和私钥文件都为空时使用明文连接
+	TLSCertFile   string   // PEM 格式的证书文件（可包含中间证书链）
+	TLSKeyFile    string   // PEM 格式的私钥文件
+	MinTLSVersion uint16   // 允许的最低 TLS 版本（tls.VersionTLS*），0 表示 TLS 1.2，更低版本的握手会被拒绝
+	CipherSuites  []uint16 // TLS 1.2 及以下允许的密码套件白名单，只能包含 tls.CipherSuites 中的安全套件；为空时使用 Go 默认列表
+
+	// 请求签名校验，HMACSecret 为空时不校验；健康检查和 channelz 不要求签名
+	HMACSecret          string        // 与客户端共享的签名密钥
+	HMACSignatureHeader string        // 携带签名的 metadata 键，为空时使用 x-srpc-signature
+	HMACTimestampHeader string        // 携带毫秒时间戳的 metadata 键，为空时使用 x-srpc-timestamp
+	HMACMaxClockSkew    time.Duration // 请求时间戳与服务端时间的最大偏差，超出视为过期或重放，默认 5 分钟
+
+	// 传输层 keepalive，时长为 0 时使用 gRPC 默认值
+	KeepAliveTime                time.Duration // 连接空闲多久后服务端主动发送 PING（gRPC 默认 2 小时）
+	KeepAliveTimeout             time.Duration // 等待 PING 响应的超时时间（gRPC 默认 20 秒）
+	MaxConnectionAge             time.Duration // 连接最长存活时间，到期后发送 GOAWAY 促使客户端重建连接，便于负载重新均衡；0 表示不限制
+	MaxConnectionAgeGrace        time.Duration // 连接到期后等待进行中 RPC 完成的宽限时间；0 表示无限等待
+	KeepAliveMinTime             time.Duration // 允许客户端发送 PING 的最小间隔，过于频繁的客户端会被断开（默认 10 秒）
+	KeepAlivePermitWithoutStream bool          // 是否允许客户端在没有活跃 RPC 时发送 PING
+
+	// 故障注入，用于测试客户端的重试和熔断；零值表示不注入，行为与未配置时完全一致
+	FailureRate        float64       // 注入错误的概率（0-1）
+	FailureCode        codes.Code    // 注入错误使用的状态码，为 OK 时使用 Unavailable
+	ResponseDelay      time.Duration // 每次请求在处理前增加的延迟
+	AllowInjectHeaders bool          // 是否允许客户端通过 x-inject-error / x-inject-delay-ms 请求头按请求注入，仅用于测试环境
+}
+
+// DefaultConfig 返回默认服务端配置
+func DefaultConfig() Config {
+	return Config{
+		ListenAddr:                   ":50051",
+		SocketFileMode:               0o660,
+		MaxNameLength:                256,
+		MaxStreamDataSize:            4096,
+		IdempotencyCacheSize:         10000,
+		KeepAliveMinTime:             10 * time.Second,
+		KeepAlivePermitWithoutStream: true,
+	}
+}
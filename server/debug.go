@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"sort"
+	"time"
+)
+
+// debugShutdownTimeout 关闭调试 HTTP 服务时等待进行中请求的最长时间
+const debugShutdownTimeout = time.Second
+
+// startDebugServer 在 DebugAddr 上启动调试 HTTP 服务，未配置时不启动
+func (s *Server) startDebugServer() error {
+	if s.config.DebugAddr == "" {
+		return nil
+	}
+
+	lis, err := net.Listen("tcp", s.config.DebugAddr)
+	if err != nil {
+		return fmt.Errorf("调试服务监听失败: %v", err)
+	}
+
+	srv := &http.Server{Handler: s.debugHandler(), ReadHeaderTimeout: 5 * time.Second}
+	s.mu.Lock()
+	s.debugServer = srv
+	s.debugAddr = lis.Addr()
+	s.mu.Unlock()
+
+	slogger.Info("调试服务已启动", map[string]interface{}{"addr": lis.Addr().String()})
+	go func() {
+		if err := srv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slogger.Error("调试服务异常退出", map[string]interface{}{"error": err})
+		}
+	}()
+	return nil
+}
+
+// stopDebugServer 关闭调试 HTTP 服务
+func (s *Server) stopDebugServer() {
+	s.mu.Lock()
+	srv := s.debugServer
+	s.debugServer = nil
+	s.mu.Unlock()
+	if srv == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), debugShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		srv.Close()
+	}
+	slogger.Info("调试服务已关闭")
+}
+
+// DebugAddr 返回调试 HTTP 服务实际监听的地址，未启动时返回 nil；DebugAddr 配置为 ":0" 时可用于获取随机端口
+func (s *Server) DebugAddr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.debugAddr
+}
+
+// debugHandler 返回调试服务的路由：/debug/pprof/、/debug/vars 和 /debug/grpc
+func (s *Server) debugHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/vars", s.serveVars)
+	mux.HandleFunc("/debug/grpc", s.serveGRPC)
+	return mux
+}
+
+// serveVars 以 expvar 格式输出全局变量（cmdline、memstats 等），并附加 srpc_server 服务端指标
+// 不通过 expvar.Publish 注册，避免同一进程中创建多个 Server 时重复注册而 panic
+func (s *Server) serveVars(w http.ResponseWriter, r *http.Request) {
+	vars := make(map[string]json.RawMessage)
+	expvar.Do(func(kv expvar.KeyValue) {
+		vars[kv.Key] = json.RawMessage(kv.Value.String())
+	})
+	metrics, err := json.Marshal(s.metrics.GetMetrics())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	vars["srpc_server"] = metrics
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(vars)
+}
+
+// serveGRPC 以纯文本列出已注册的服务以及各方法正在处理的 RPC 数（包括未结束的流）
+func (s *Server) serveGRPC(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	fmt.Fprintf(w, "draining: %v\n", s.Draining())
+	fmt.Fprintf(w, "in_flight: %d\n\n", s.metrics.InFlight())
+
+	services := s.GetServiceInfo()
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	active := s.metrics.ActiveByMethod()
+	for _, name := range names {
+		fmt.Fprintf(w, "%s\n", name)
+		for _, method := range services[name].Methods {
+			fullMethod := "/" + name + "/" + method.Name
+			kind := "unary"
+			if method.IsClientStream || method.IsServerStream {
+				kind = "stream"
+			}
+			fmt.Fprintf(w, "  %-40s %-6s active=%d\n", method.Name, kind, active[fullMethod])
+		}
+	}
+}
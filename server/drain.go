@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// drainer 维护排空状态：排空后健康检查返回 NOT_SERVING，新 RPC 被拒绝，已建立的流不受影响
+type drainer struct {
+	draining atomic.Bool
+	health   *health.Server
+}
+
+// newDrainer 创建排空控制器，服务整体和 Greeter 服务初始均为 SERVING
+func newDrainer(services ...string) *drainer {
+	d := &drainer{health: health.NewServer()}
+	d.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	for _, service := range services {
+		d.health.SetServingStatus(service, healthpb.HealthCheckResponse_SERVING)
+	}
+	return d
+}
+
+// drain 进入排空状态，返回是否为首次调用
+func (d *drainer) drain() bool {
+	if !d.draining.CompareAndSwap(false, true) {
+		return false
+	}
+	// Shutdown 将所有服务置为 NOT_SERVING，并忽略之后的状态更新
+	d.health.Shutdown()
+	return true
+}
+
+// reject 排空期间拒绝新 RPC，健康检查和 channelz 除外，以便负载均衡器读取 NOT_SERVING 状态并继续排查连接
+func (d *drainer) reject(method string) error {
+	if !d.draining.Load() || operationalMethod(method) {
+		return nil
+	}
+	return status.Error(codes.Unavailable, "服务器正在排空，不再接受新请求")
+}
+
+// unaryInterceptor 排空期间拒绝新的一元 RPC
+func (d *drainer) unaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := d.reject(info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// streamInterceptor 排空期间拒绝新建的流，已建立的流继续运行直到结束
+func (d *drainer) streamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := d.reject(info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
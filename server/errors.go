@@ -0,0 +1,37 @@
+package server
+
+import (
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// InvalidArgumentError 返回携带 errdetails.BadRequest 的 InvalidArgument 错误，标明出错的字段
+func InvalidArgumentError(field, description string) error {
+	st := status.New(codes.InvalidArgument, description)
+	detailed, err := st.WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: field, Description: description},
+		},
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// OverloadedError 返回携带 errdetails.RetryInfo 的 ResourceExhausted 错误
+// 客户端重试时会优先使用 retryDelay 作为等待时间，而不是自身的退避策略
+func OverloadedError(message string, retryDelay time.Duration) error {
+	st := status.New(codes.ResourceExhausted, message)
+	detailed, err := st.WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(retryDelay),
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"srpc/pkg/auth"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// hmacConfig 根据服务端配置构造请求签名配置
+func hmacConfig(cfg Config) auth.HMACConfig {
+	return auth.HMACConfig{
+		Secret:          []byte(cfg.HMACSecret),
+		SignatureHeader: cfg.HMACSignatureHeader,
+		TimestampHeader: cfg.HMACTimestampHeader,
+		MaxClockSkew:    cfg.HMACMaxClockSkew,
+	}.WithDefaults()
+}
+
+// hmacUnaryInterceptor 校验一元 RPC 的请求签名，签名不匹配或时间戳过期时返回 Unauthenticated
+func hmacUnaryInterceptor(cfg auth.HMACConfig, m *Metrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if operationalMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		body, err := auth.MessageBody(req)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "序列化请求失败: %v", err)
+		}
+		if err := verifySignature(ctx, cfg, info.FullMethod, body, m); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// hmacStreamInterceptor 在流建立时校验签名，签名只覆盖方法名和时间戳
+func hmacStreamInterceptor(cfg auth.HMACConfig, m *Metrics) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if operationalMethod(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		if err := verifySignature(ss.Context(), cfg, info.FullMethod, nil, m); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// verifySignature 从 incoming metadata 读取时间戳和签名并校验
+func verifySignature(ctx context.Context, cfg auth.HMACConfig, method string, body []byte, m *Metrics) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	err := cfg.Verify(method, firstValue(md, cfg.TimestampHeader), firstValue(md, cfg.SignatureHeader), body, time.Now())
+	if err == nil {
+		return nil
+	}
+
+	m.RecordAuthRejected()
+	slogger.WarnContext(ctx, "请求签名校验失败", map[string]interface{}{
+		"method": method,
+		"error":  err.Error(),
+	})
+	return status.Error(codes.Unauthenticated, err.Error())
+}
+
+// firstValue 返回 metadata 中 key 的第一个值，不存在时返回空字符串
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
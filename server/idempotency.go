@@ -0,0 +1,128 @@
+package server
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"srpc/pkg/log"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// idempotencyEntry 一个幂等键对应的处理结果，done 关闭后 reply 和 err 才可读取
+type idempotencyEntry struct {
+	key       string
+	done      chan struct{}
+	reply     any
+	err       error
+	expiresAt time.Time
+}
+
+// idempotencyCache 按方法和幂等键记录一元 RPC 结果的有界 TTL 表
+// 条目按首次出现的顺序排列，过期或超出容量时从最早的条目开始淘汰
+type idempotencyCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	size    int
+	order   *list.List // 最早出现的条目在前
+	entries map[string]*list.Element
+}
+
+// newIdempotencyCache 创建幂等表，ttl 不大于 0 时返回 nil 表示不去重
+func newIdempotencyCache(ttl time.Duration, size int) *idempotencyCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &idempotencyCache{
+		ttl:     ttl,
+		size:    max(size, 1),
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// begin 查找 key 对应的条目；不存在或已过期时创建新条目并返回 owner 为 true，由调用方执行请求后调用 finish
+func (c *idempotencyCache) begin(key string, now time.Time) (entry *idempotencyEntry, owner bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for front := c.order.Front(); front != nil; front = c.order.Front() {
+		if e := front.Value.(*idempotencyEntry); now.Before(e.expiresAt) && c.order.Len() < c.size {
+			break
+		}
+		c.removeLocked(front)
+	}
+	if elem, ok := c.entries[key]; ok {
+		return elem.Value.(*idempotencyEntry), false
+	}
+
+	entry = &idempotencyEntry{key: key, done: make(chan struct{}), expiresAt: now.Add(c.ttl)}
+	c.entries[key] = c.order.PushBack(entry)
+	return entry, true
+}
+
+// finish 记录请求结果并唤醒等待的重复请求；失败的结果不保留，之后的重试会重新执行
+func (c *idempotencyCache) finish(entry *idempotencyEntry, reply any, err error) {
+	entry.reply, entry.err = reply, err
+	close(entry.done)
+	if err == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[entry.key]; ok && elem.Value == entry {
+		c.removeLocked(elem)
+	}
+}
+
+// removeLocked 删除条目，调用方需持有 c.mu
+func (c *idempotencyCache) removeLocked(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*idempotencyEntry).key)
+}
+
+// idempotencyKey 读取客户端携带的幂等键，未携带时返回空字符串
+func idempotencyKey(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if keys := md.Get(log.IdempotencyKeyHeader); len(keys) > 0 {
+		return keys[0]
+	}
+	return ""
+}
+
+// idempotencyUnaryInterceptor 按幂等键对一元 RPC 去重：TTL 内重复到达的请求直接返回首次成功的响应，
+// 首次请求仍在处理时重复请求等待其结果；流和未携带幂等键的请求不受影响
+func idempotencyUnaryInterceptor(cache *idempotencyCache, m *Metrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		key := idempotencyKey(ctx)
+		if key == "" || operationalMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		entry, owner := cache.begin(info.FullMethod+"\x00"+key, time.Now())
+		if owner {
+			reply, err := handler(ctx, req)
+			cache.finish(entry, reply, err)
+			return reply, err
+		}
+
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+		if entry.err == nil {
+			m.RecordIdempotentReplay()
+			slogger.InfoContext(ctx, "重复请求，返回首次处理的响应", map[string]interface{}{"idempotency_key": key})
+		}
+		return entry.reply, entry.err
+	}
+}
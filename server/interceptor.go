@@ -0,0 +1,212 @@
+package server
+
+import (
+	"context"
+	"runtime/debug"
+	srpclog "srpc/pkg/log"
+	srpcstats "srpc/pkg/stats"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// recoveryUnaryInterceptor 捕获一元处理器中的 panic，转换为 Internal 错误
+func recoveryUnaryInterceptor(m *Metrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				m.RecordPanic()
+				slogger.ErrorContext(ctx, "处理器发生 panic", map[string]interface{}{
+					"panic": r,
+					"stack": string(debug.Stack()),
+				})
+				err = status.Errorf(codes.Internal, "服务端内部错误")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// recoveryStreamInterceptor 捕获流处理器中的 panic，转换为 Internal 错误
+func recoveryStreamInterceptor(m *Metrics) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				m.RecordPanic()
+				slogger.ErrorContext(ss.Context(), "流处理器发生 panic", map[string]interface{}{
+					"panic": r,
+					"stack": string(debug.Stack()),
+				})
+				err = status.Errorf(codes.Internal, "服务端内部错误")
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// accessLogUnaryInterceptor 记录每个一元 RPC 的耗时和状态码
+func accessLogUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logAccess(ctx, start, err)
+		return resp, err
+	}
+}
+
+// accessLogStreamInterceptor 记录每个流 RPC 的耗时和状态码
+func accessLogStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logAccess(ss.Context(), start, err)
+		return err
+	}
+}
+
+// logAccess 输出访问日志
+func logAccess(ctx context.Context, start time.Time, err error) {
+	fields := map[string]interface{}{
+		"duration": time.Since(start).String(),
+		"code":     status.Code(err).String(),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		slogger.WarnContext(ctx, "RPC 处理完成", fields)
+		return
+	}
+	slogger.InfoContext(ctx, "RPC 处理完成", fields)
+}
+
+// metricsUnaryInterceptor 统计一元 RPC 的调用次数、失败次数和耗时
+func metricsUnaryInterceptor(m *Metrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		m.beginRequest(info.FullMethod)
+		defer m.endRequest(info.FullMethod)
+		// 在 defer 中记录，处理器 panic 时同样计为失败，再交给外层的恢复拦截器
+		start := time.Now()
+		success := false
+		defer func() { m.RecordRequest(info.FullMethod, success, time.Since(start)) }()
+		resp, err := handler(ctx, req)
+		success = err == nil
+		return resp, err
+	}
+}
+
+// metricsStreamInterceptor 统计流 RPC 的调用次数、失败次数和耗时
+func metricsStreamInterceptor(m *Metrics) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		m.beginRequest(info.FullMethod)
+		defer m.endRequest(info.FullMethod)
+		start := time.Now()
+		success := false
+		defer func() { m.RecordRequest(info.FullMethod, success, time.Since(start)) }()
+		err := handler(srv, ss)
+		success = err == nil
+		return err
+	}
+}
+
+// serverDurationUnaryInterceptor 在 trailer 中返回服务端处理耗时，客户端据此从端到端耗时中分离出网络开销
+func serverDurationUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		// 失败时同样返回，客户端可以区分服务端处理慢和网络慢
+		if err := grpc.SetTrailer(ctx, srpcstats.DurationTrailer(time.Since(start))); err != nil {
+			slogger.WarnContext(ctx, "设置耗时 trailer 失败", map[string]interface{}{"error": err})
+		}
+		return resp, err
+	}
+}
+
+// serverDurationStreamInterceptor 在流结束时通过 trailer 返回整个流的耗时和收发消息数
+func serverDurationStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		counted := &countingServerStream{ServerStream: ss}
+		err := handler(srv, counted)
+		ss.SetTrailer(metadata.Join(srpcstats.DurationTrailer(time.Since(start)), metadata.Pairs(
+			srpcstats.ServerMessagesSentTrailer, strconv.FormatInt(counted.sent.Load(), 10),
+			srpcstats.ServerMessagesReceivedTrailer, strconv.FormatInt(counted.received.Load(), 10),
+		)))
+		return err
+	}
+}
+
+// instanceHeaderUnaryInterceptor 在响应 header 中返回实例 ID
+func instanceHeaderUnaryInterceptor(instance string) grpc.UnaryServerInterceptor {
+	header := metadata.Pairs(srpcstats.ServerInstanceHeader, instance)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := grpc.SetHeader(ctx, header); err != nil {
+			slogger.WarnContext(ctx, "设置实例 header 失败", map[string]interface{}{"error": err})
+		}
+		return handler(ctx, req)
+	}
+}
+
+// instanceHeaderStreamInterceptor 在流的响应 header 中返回实例 ID，header 随第一条消息或流结束时发送
+func instanceHeaderStreamInterceptor(instance string) grpc.StreamServerInterceptor {
+	header := metadata.Pairs(srpcstats.ServerInstanceHeader, instance)
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := ss.SetHeader(header); err != nil {
+			slogger.WarnContext(ss.Context(), "设置实例 header 失败", map[string]interface{}{"error": err})
+		}
+		return handler(srv, ss)
+	}
+}
+
+// countingServerStream 统计流中成功收发的消息数
+type countingServerStream struct {
+	grpc.ServerStream
+	sent     atomic.Int64
+	received atomic.Int64
+}
+
+// SendMsg 发送消息并计数
+func (s *countingServerStream) SendMsg(m any) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent.Add(1)
+	}
+	return err
+}
+
+// RecvMsg 接收消息并计数
+func (s *countingServerStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received.Add(1)
+	}
+	return err
+}
+
+// requestIDSpanUnaryInterceptor 将客户端传入的请求 ID 记录到 otelgrpc 创建的服务端 span 上
+func requestIDSpanUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		annotateSpanWithRequestID(ctx)
+		return handler(ctx, req)
+	}
+}
+
+// requestIDSpanStreamInterceptor 将客户端传入的请求 ID 记录到 otelgrpc 创建的服务端 span 上
+func requestIDSpanStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		annotateSpanWithRequestID(ss.Context())
+		return handler(srv, ss)
+	}
+}
+
+// annotateSpanWithRequestID 为当前 span 添加 request_id 属性，与客户端 span 保持一致
+func annotateSpanWithRequestID(ctx context.Context) {
+	if requestID := srpclog.RequestIDFromContext(ctx); requestID != "" {
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("request_id", requestID))
+	}
+}
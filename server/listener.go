@@ -0,0 +1,64 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// unixSocketPath 解析 unix:///path/to.sock 或 unix:path 形式的地址，非 unix 地址返回 false
+func unixSocketPath(addr string) (string, bool) {
+	switch {
+	case strings.HasPrefix(addr, "unix://"):
+		return strings.TrimPrefix(addr, "unix://"), true
+	case strings.HasPrefix(addr, "unix:"):
+		return strings.TrimPrefix(addr, "unix:"), true
+	default:
+		return "", false
+	}
+}
+
+// listen 根据监听地址创建 TCP 或 Unix domain socket 监听器
+func listen(cfg Config) (net.Listener, error) {
+	path, ok := unixSocketPath(cfg.ListenAddr)
+	if !ok {
+		return net.Listen("tcp", cfg.ListenAddr)
+	}
+
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, cfg.SocketFileMode); err != nil {
+		lis.Close()
+		return nil, fmt.Errorf("设置 socket 文件权限失败: %v", err)
+	}
+	return lis, nil
+}
+
+// removeStaleSocket 删除上次异常退出遗留的 socket 文件
+// 只删除无人监听的 socket 文件，避免误删普通文件或抢占正在运行的实例
+func removeStaleSocket(path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s 已存在且不是 socket 文件", path)
+	}
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return fmt.Errorf("%s 正在被其他进程监听", path)
+	}
+
+	slogger.Info("删除遗留的 socket 文件", map[string]interface{}{"path": path})
+	return os.Remove(path)
+}
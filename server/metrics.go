@@ -0,0 +1,201 @@
+package server
+
+import (
+	"srpc/pkg/stats"
+	"sync"
+	"time"
+)
+
+// methodStats 单个 RPC 方法的统计
+type methodStats struct {
+	requests int64
+	failures int64
+	duration time.Duration
+	transfer stats.Totals // 传输层字节统计
+}
+
+// Metrics 服务端指标收集器
+type Metrics struct {
+	mu             sync.RWMutex
+	totalRequests  int64
+	failedRequests int64
+	panicCount     int64
+	inFlight       int64            // 正在处理的 RPC 数
+	active         map[string]int64 // 各方法正在处理的 RPC 数（包括未结束的流）
+	rejected       int64            // 因并发上限被拒绝的 RPC 数
+	authRejected   int64            // 因请求签名校验失败被拒绝的 RPC 数
+	replays        int64            // 按幂等键去重、直接返回首次响应的重复请求数
+	injectedErrors int64            // 故障注入返回的错误数，不计入 failedRequests
+	injectedDelays int64            // 故障注入延迟的请求数
+	injectedDelay  time.Duration    // 故障注入的延迟累计
+	totalDuration  time.Duration
+	methods        map[string]*methodStats
+	transfer       stats.Totals // 传输层字节统计（全部方法）
+	startTime      time.Time
+}
+
+// NewMetrics 创建新的服务端指标收集器
+func NewMetrics() *Metrics {
+	return &Metrics{
+		methods:   make(map[string]*methodStats),
+		active:    make(map[string]int64),
+		startTime: time.Now(),
+	}
+}
+
+// RecordRequest 记录一次 RPC 调用
+func (m *Metrics) RecordRequest(method string, success bool, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ms := m.methodStatsLocked(method)
+
+	m.totalRequests++
+	ms.requests++
+	if !success {
+		m.failedRequests++
+		ms.failures++
+	}
+	m.totalDuration += duration
+	ms.duration += duration
+}
+
+// RecordPayload 记录传输层消息统计，实现 stats.Recorder
+func (m *Metrics) RecordPayload(p stats.Payload) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.transfer.Add(p)
+	m.methodStatsLocked(p.Method).transfer.Add(p)
+}
+
+// methodStatsLocked 获取或创建方法统计，调用方需持有写锁
+func (m *Metrics) methodStatsLocked(method string) *methodStats {
+	ms, ok := m.methods[method]
+	if !ok {
+		ms = &methodStats{}
+		m.methods[method] = ms
+	}
+	return ms
+}
+
+// beginRequest 记录一个 RPC 开始处理，必须与 endRequest 成对调用
+func (m *Metrics) beginRequest(method string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight++
+	m.active[method]++
+}
+
+// endRequest 记录一个 RPC 处理结束
+func (m *Metrics) endRequest(method string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight--
+	if m.active[method]--; m.active[method] == 0 {
+		delete(m.active, method)
+	}
+}
+
+// ActiveByMethod 返回各方法正在处理的 RPC 数，只包含大于 0 的方法
+func (m *Metrics) ActiveByMethod() map[string]int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	active := make(map[string]int64, len(m.active))
+	for method, n := range m.active {
+		active[method] = n
+	}
+	return active
+}
+
+// InFlight 返回正在处理的 RPC 数
+func (m *Metrics) InFlight() int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.inFlight
+}
+
+// RecordConcurrencyRejected 记录一次因并发上限被拒绝的 RPC
+func (m *Metrics) RecordConcurrencyRejected() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rejected++
+}
+
+// RecordAuthRejected 记录一次因请求签名校验失败被拒绝的 RPC
+func (m *Metrics) RecordAuthRejected() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.authRejected++
+}
+
+// RecordIdempotentReplay 记录一次按幂等键去重的重复请求
+func (m *Metrics) RecordIdempotentReplay() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.replays++
+}
+
+// RecordPanic 记录一次处理器 panic
+func (m *Metrics) RecordPanic() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.panicCount++
+}
+
+// RecordInjectedFailure 记录一次故障注入返回的错误
+func (m *Metrics) RecordInjectedFailure() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.injectedErrors++
+}
+
+// RecordInjectedDelay 记录一次故障注入的延迟
+func (m *Metrics) RecordInjectedDelay(delay time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.injectedDelays++
+	m.injectedDelay += delay
+}
+
+// GetMetrics 获取指标快照
+func (m *Metrics) GetMetrics() map[string]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	methods := make(map[string]interface{}, len(m.methods))
+	for name, ms := range m.methods {
+		var avg time.Duration
+		if ms.requests > 0 {
+			avg = ms.duration / time.Duration(ms.requests)
+		}
+		methods[name] = map[string]interface{}{
+			"requests":     ms.requests,
+			"failures":     ms.failures,
+			"avg_duration": avg.String(),
+			"transfer":     ms.transfer.Map(),
+		}
+	}
+
+	var avg time.Duration
+	if m.totalRequests > 0 {
+		avg = m.totalDuration / time.Duration(m.totalRequests)
+	}
+
+	return map[string]interface{}{
+		"total_requests":       m.totalRequests,
+		"failed_requests":      m.failedRequests,
+		"panics":               m.panicCount,
+		"in_flight":            m.inFlight,
+		"concurrency_rejected": m.rejected,
+		"auth_rejected":        m.authRejected,
+		"idempotent_replays":   m.replays,
+		"injected_errors":      m.injectedErrors,
+		"injected_delays":      m.injectedDelays,
+		"injected_delay":       m.injectedDelay.String(),
+		"avg_duration":         avg.String(),
+		"uptime":               time.Since(m.startTime).String(),
+		"methods":              methods,
+		"transfer":             m.transfer.Map(),
+	}
+}
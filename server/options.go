@@ -0,0 +1,48 @@
+package server
+
+import (
+	"srpc/pkg/chaos"
+
+	"google.golang.org/grpc"
+)
+
+// Option 服务端构造选项
+type Option func(*options)
+
+// options 汇总构造 Server 时的可选项
+type options struct {
+	serverOptions      []grpc.ServerOption
+	unaryInterceptors  []grpc.UnaryServerInterceptor
+	streamInterceptors []grpc.StreamServerInterceptor
+	chaos              chaos.Config
+}
+
+// WithServerOptions 追加原生 grpc.ServerOption
+func WithServerOptions(opts ...grpc.ServerOption) Option {
+	return func(o *options) {
+		o.serverOptions = append(o.serverOptions, opts...)
+	}
+}
+
+// WithUnaryInterceptors 追加一元拦截器，在内置拦截器（恢复、访问日志、指标）之后执行
+func WithUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) Option {
+	return func(o *options) {
+		o.unaryInterceptors = append(o.unaryInterceptors, interceptors...)
+	}
+}
+
+// WithStreamInterceptors 追加流拦截器，在内置拦截器（恢复、访问日志、指标）之后执行
+func WithStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) Option {
+	return func(o *options) {
+		o.streamInterceptors = append(o.streamInterceptors, interceptors...)
+	}
+}
+
+// WithChaos 启用故障注入，在处理器执行前按配置注入延迟和错误，用于测试客户端的重试和熔断
+// 支持多个状态码和随机延迟；Config 中的 FailureRate、FailureCode、ResponseDelay 非零时覆盖对应项
+// 注入的错误会记录访问日志，但单独计入 injected_* 指标；Ping 和 GetServerInfo 不受影响
+func WithChaos(cfg chaos.Config) Option {
+	return func(o *options) {
+		o.chaos = cfg
+	}
+}
@@ -5,16 +5,27 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
-	_ "srpc/pkg/compress" // 确保压缩器被注册
+	"runtime"
+	"srpc/pkg/chaos"
+	"srpc/pkg/compress" // 同时确保压缩器被注册
 	srpclog "srpc/pkg/log"
+	srpcstats "srpc/pkg/stats"
+	"srpc/pkg/tracing"
 	pb "srpc/proto"
+	"sync"
 	"syscall"
 	"time"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/metadata"
+	channelzsvc "google.golang.org/grpc/channelz/service"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 var slogger = srpclog.NewLogger()
@@ -22,22 +33,16 @@ var slogger = srpclog.NewLogger()
 // server 结构体实现 GreeterServer 接口
 type server struct {
 	pb.UnimplementedGreeterServer
+	startTime time.Time
+	config    Config
 }
 
 // SayHello 实现普通RPC
 func (s *server) SayHello(ctx context.Context, req *pb.HelloRequest) (*pb.HelloReply, error) {
-	// 从 metadata 中获取请求 ID
-	var requestID string
-	if md, ok := metadata.FromIncomingContext(ctx); ok {
-		if ids := md.Get("x-request-id"); len(ids) > 0 {
-			requestID = ids[0]
-		}
-	}
-
-	if requestID != "" {
-		slogger.Info(fmt.Sprintf("收到 SayHello 请求 [ID: %s]: %v", requestID, req.GetName()))
-	} else {
-		slogger.Info(fmt.Sprintf("收到 SayHello 请求: %v", req.GetName()))
+	// 请求 ID 由 InfoContext 从 metadata 中自动提取
+	slogger.InfoContext(ctx, fmt.Sprintf("收到 SayHello 请求: %v", req.GetName()))
+	if err := validateName(req.GetName(), s.config.MaxNameLength); err != nil {
+		return nil, err
 	}
 
 	return &pb.HelloReply{
@@ -47,7 +52,11 @@ func (s *server) SayHello(ctx context.Context, req *pb.HelloRequest) (*pb.HelloR
 
 // GetStream 实现服务端流模式
 func (s *server) GetStream(req *pb.StreamReqData, stream pb.Greeter_GetStreamServer) error {
-	slogger.Info(fmt.Sprintf("收到 GetStream 请求: %v", req.GetData()))
+	ctx := stream.Context()
+	slogger.InfoContext(ctx, fmt.Sprintf("收到 GetStream 请求: %v", req.GetData()))
+	if err := validateStreamData(req.GetData(), s.config.MaxStreamDataSize); err != nil {
+		return err
+	}
 
 	// 发送 5 条流式响应
 	for i := 1; i <= 5; i++ {
@@ -57,8 +66,11 @@ func (s *server) GetStream(req *pb.StreamReqData, stream pb.Greeter_GetStreamSer
 		if err := stream.Send(response); err != nil {
 			return err
 		}
-		slogger.Info(fmt.Sprintf("发送流数据: %v", response.GetData()))
-		time.Sleep(500 * time.Millisecond) // 模拟处理延迟
+		slogger.InfoContext(ctx, fmt.Sprintf("发送流数据: %v", response.GetData()))
+		// 模拟处理延迟，客户端断开或服务器关闭时立即返回，避免拖慢 GracefulStop
+		if err := chaos.Sleep(ctx, 500*time.Millisecond); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -66,54 +78,111 @@ func (s *server) GetStream(req *pb.StreamReqData, stream pb.Greeter_GetStreamSer
 
 // PutStream 实现客户端流模式
 func (s *server) PutStream(stream pb.Greeter_PutStreamServer) error {
-	slogger.Info("开始接收客户端流数据")
+	ctx := stream.Context()
+	slogger.InfoContext(ctx, "开始接收客户端流数据")
 
-	var messageCount int32 = 0
+	progress := streamProgress{start: time.Now()}
 	var lastMessage string
 
+	// 超过 MaxStreamDuration 时即使客户端不再发送消息也结束流
+	var deadline <-chan time.Time
+	if s.config.MaxStreamDuration > 0 {
+		timer := time.NewTimer(s.config.MaxStreamDuration)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	results := recvPutStream(stream)
 	for {
-		req, err := stream.Recv()
-		if err == io.EOF {
+		var r recvResult
+		select {
+		case r = <-results:
+		case <-deadline:
+			return streamLimitExceeded(ctx, "max_stream_duration", fmt.Sprintf("流持续时间超过上限 %v", s.config.MaxStreamDuration), progress)
+		}
+
+		if r.err == io.EOF {
 			// 客户端流结束
-			slogger.Info(fmt.Sprintf("客户端流结束，共接收 %d 条消息", messageCount))
+			slogger.InfoContext(ctx, fmt.Sprintf("客户端流结束，共接收 %d 条消息、%d 字节", progress.messages, progress.bytes))
 			return stream.SendAndClose(&pb.StreamResData{
-				Data: fmt.Sprintf("成功接收 %d 条消息，最后一条: %s", messageCount, lastMessage),
+				Data: fmt.Sprintf("成功接收 %d 条消息（共 %d 字节），最后一条: %s", progress.messages, progress.bytes, lastMessage),
 			})
 		}
-		if err != nil {
+		if r.err != nil {
+			return r.err
+		}
+		if err := validateStreamData(r.req.GetData(), s.config.MaxStreamDataSize); err != nil {
 			return err
 		}
 
-		messageCount++
-		lastMessage = req.GetData()
-		slogger.Info(fmt.Sprintf("接收客户端流数据 %d: %v", messageCount, lastMessage))
+		progress.messages++
+		progress.bytes += int64(len(r.req.GetData()))
+		if err := s.checkStreamLimits(ctx, progress); err != nil {
+			return err
+		}
+		lastMessage = r.req.GetData()
+		slogger.InfoContext(ctx, fmt.Sprintf("接收客户端流数据 %d: %v", progress.messages, lastMessage))
 	}
 }
 
 // AllStream 实现双向流模式
 func (s *server) AllStream(stream pb.Greeter_AllStreamServer) error {
-	slogger.Info("开始双向流通信")
+	ctx := stream.Context()
+	slogger.InfoContext(ctx, "开始双向流通信")
+
+	// 回应、初始消息和心跳由不同的 goroutine 发送，gRPC 不允许并发调用 Send
+	var sendMu sync.Mutex
+	send := func(response *pb.StreamResData) error {
+		sendMu.Lock()
+		defer sendMu.Unlock()
+		return stream.Send(response)
+	}
+
+	// 定期发送心跳，使双方能在 TCP 超时之前发现失联的对端
+	if interval := s.config.StreamHeartbeatInterval; interval > 0 {
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			heartbeat := &pb.StreamResData{Kind: pb.StreamMessageKind_STREAM_MESSAGE_KIND_HEARTBEAT}
+			for {
+				select {
+				case <-ticker.C:
+					if err := send(heartbeat); err != nil {
+						slogger.WarnContext(ctx, "发送心跳失败", map[string]interface{}{"error": err.Error()})
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
 
 	// 启动goroutine接收客户端消息
 	go func() {
 		for {
 			req, err := stream.Recv()
 			if err == io.EOF {
-				slogger.Info("客户端流结束")
+				slogger.InfoContext(ctx, "客户端流结束")
 				return
 			}
 			if err != nil {
-				slogger.Error(fmt.Sprintf("接收客户端消息错误: %v", err))
+				slogger.ErrorContext(ctx, fmt.Sprintf("接收客户端消息错误: %v", err))
 				return
 			}
-			slogger.Info(fmt.Sprintf("接收客户端消息: %v", req.GetData()))
+			slogger.InfoContext(ctx, fmt.Sprintf("接收客户端消息: %v", req.GetData()))
+			// 校验失败的消息不予回应，继续处理后续消息
+			if err := validateStreamData(req.GetData(), s.config.MaxStreamDataSize); err != nil {
+				slogger.WarnContext(ctx, "客户端消息校验失败", map[string]interface{}{"error": err.Error()})
+				continue
+			}
 
 			// 立即回应
 			response := &pb.StreamResData{
 				Data: fmt.Sprintf("回应: %s", req.GetData()),
 			}
-			if err := stream.Send(response); err != nil {
-				slogger.Error(fmt.Sprintf("发送回应错误: %v", err))
+			if err := send(response); err != nil {
+				slogger.ErrorContext(ctx, fmt.Sprintf("发送回应错误: %v", err))
 				return
 			}
 		}
@@ -124,46 +193,348 @@ func (s *server) AllStream(stream pb.Greeter_AllStreamServer) error {
 		response := &pb.StreamResData{
 			Data: fmt.Sprintf("服务端初始消息 %d", i),
 		}
-		if err := stream.Send(response); err != nil {
+		if err := send(response); err != nil {
+			return err
+		}
+		slogger.InfoContext(ctx, fmt.Sprintf("发送服务端初始消息: %v", response.GetData()))
+		if err := chaos.Sleep(ctx, time.Second); err != nil {
 			return err
 		}
-		slogger.Info(fmt.Sprintf("发送服务端初始消息: %v", response.GetData()))
-		time.Sleep(1 * time.Second)
 	}
 
 	// 等待流结束
-	<-stream.Context().Done()
+	<-ctx.Done()
 	return nil
 }
 
-// RunServer 启动 gRPC 服务器
-func RunServer() error {
-	lis, err := net.Listen("tcp", ":50051")
+// Ping 实现轻量级探活，原样返回客户端时间戳和序列号，并附带服务端收到请求的时间
+func (s *server) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingReply, error) {
+	receivedAt := time.Now()
+	return &pb.PingReply{
+		ClientSendTimeUnixNano:    req.GetClientSendTimeUnixNano(),
+		Sequence:                  req.GetSequence(),
+		ServerReceiveTimeUnixNano: receivedAt.UnixNano(),
+		Token:                     req.GetToken(),
+		UptimeSeconds:             int64(receivedAt.Sub(s.startTime).Seconds()),
+		Version:                   Version,
+	}, nil
+}
+
+// GetServerInfo 返回服务端构建与运行信息
+func (s *server) GetServerInfo(ctx context.Context, _ *emptypb.Empty) (*pb.ServerInfo, error) {
+	return &pb.ServerInfo{
+		Version:       Version,
+		GitCommit:     GitCommit,
+		BuildTime:     BuildTime,
+		GoVersion:     runtime.Version(),
+		StartTimeUnix: s.startTime.Unix(),
+		UptimeSeconds: int64(time.Since(s.startTime).Seconds()),
+		Compressors:   compress.Registered(),
+	}, nil
+}
+
+// Server 可嵌入的 gRPC 服务器，默认托管 Greeter 服务
+type Server struct {
+	config     Config
+	grpcServer *grpc.Server
+	metrics    *Metrics
+	drainer    *drainer
+
+	mu          sync.Mutex
+	debugServer *http.Server // 调试 HTTP 服务，未启动时为 nil
+	debugAddr   net.Addr     // 调试 HTTP 服务实际监听的地址
+}
+
+// New 创建新的服务器
+// 内置拦截器（访问日志、指标、panic 恢复）总是排在调用方追加的拦截器之前
+func New(cfg Config, opts ...Option) (*Server, error) {
+	// 消息大小限制为 0 时使用 gRPC 默认值，负数没有意义，直接拒绝以免静默回退
+	if cfg.MaxRecvMsgSize < 0 || cfg.MaxSendMsgSize < 0 {
+		return nil, fmt.Errorf("消息大小限制不能为负数: max_recv_msg_size=%d, max_send_msg_size=%d", cfg.MaxRecvMsgSize, cfg.MaxSendMsgSize)
+	}
+
+	defaults := DefaultConfig()
+	if cfg.ListenAddr == "" {
+		cfg.ListenAddr = defaults.ListenAddr
+	}
+	if cfg.MaxNameLength <= 0 {
+		cfg.MaxNameLength = defaults.MaxNameLength
+	}
+	if cfg.MaxStreamDataSize <= 0 {
+		cfg.MaxStreamDataSize = defaults.MaxStreamDataSize
+	}
+	if cfg.IdempotencyCacheSize <= 0 {
+		cfg.IdempotencyCacheSize = defaults.IdempotencyCacheSize
+	}
+	if cfg.KeepAliveMinTime <= 0 {
+		cfg.KeepAliveMinTime = defaults.KeepAliveMinTime
+	}
+	if cfg.SocketFileMode == 0 {
+		cfg.SocketFileMode = defaults.SocketFileMode
+	}
+	if cfg.InstanceID == "" {
+		// 主机名在容器中通常就是 Pod 名称，足以区分副本
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("获取主机名失败，请通过 InstanceID 指定实例 ID: %v", err)
+		}
+		cfg.InstanceID = hostname
+	}
+
+	tlsConfig, err := buildTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	metrics := NewMetrics()
+	drainer := newDrainer(pb.Greeter_ServiceDesc.ServiceName)
+
+	// 耗时 trailer 覆盖签名校验、去重等待、故障注入延迟和处理器，即服务端在请求上花费的全部时间
+	// panic 恢复紧随其后，包住其余所有内置拦截器和处理器，访问日志记录的是转换后的 Internal 错误
+	// 实例 header 在排空和签名校验之前设置，被拒绝的请求也能看出由哪个副本返回
+	unary := []grpc.UnaryServerInterceptor{
+		accessLogUnaryInterceptor(), serverDurationUnaryInterceptor(), recoveryUnaryInterceptor(metrics),
+		instanceHeaderUnaryInterceptor(cfg.InstanceID), drainer.unaryInterceptor(),
+	}
+	stream := []grpc.StreamServerInterceptor{
+		accessLogStreamInterceptor(), serverDurationStreamInterceptor(), recoveryStreamInterceptor(metrics),
+		instanceHeaderStreamInterceptor(cfg.InstanceID), drainer.streamInterceptor(),
+	}
+	// 签名校验在并发限制之前，未认证的请求不占用并发许可
+	if hmacCfg := hmacConfig(cfg); hmacCfg.Enabled() {
+		unary = append(unary, hmacUnaryInterceptor(hmacCfg, metrics))
+		stream = append(stream, hmacStreamInterceptor(hmacCfg, metrics))
+	}
+	// 幂等去重在并发限制之前，重复请求不占用并发许可，也不计入请求指标
+	if cache := newIdempotencyCache(cfg.IdempotencyTTL, cfg.IdempotencyCacheSize); cache != nil {
+		unary = append(unary, idempotencyUnaryInterceptor(cache, metrics))
+	}
+	// 并发上限在指标拦截器之外，被拒绝的请求单独计入 concurrency_rejected
+	if sem := newSemaphore(cfg.MaxConcurrentStreams); sem != nil {
+		unary = append(unary, concurrencyLimitUnaryInterceptor(sem, metrics))
+		stream = append(stream, concurrencyLimitStreamInterceptor(sem, metrics))
+	}
+	// 故障注入位于访问日志之后、指标之前：访问日志记录注入的错误，指标中单独统计
+	if inj := newInjector(cfg, o.chaos, metrics); inj != nil {
+		slogger.Warn("已启用故障注入，仅用于测试", map[string]interface{}{
+			"error_rate":           inj.cfg.ErrorRate,
+			"codes":                inj.cfg.Codes,
+			"latency":              inj.cfg.Latency.String(),
+			"latency_jitter":       inj.cfg.LatencyJitter.String(),
+			"allow_inject_headers": inj.allowHeaders,
+		})
+		unary = append(unary, inj.unaryInterceptor())
+		stream = append(stream, inj.streamInterceptor())
+	}
+	unary = append(unary, metricsUnaryInterceptor(metrics))
+	unary = append(unary, o.unaryInterceptors...)
+	stream = append(stream, metricsStreamInterceptor(metrics))
+	stream = append(stream, o.streamInterceptors...)
+
+	if cfg.EnableTracing {
+		// 与客户端一样，把请求 ID 关联到 span 上，便于在追踪系统中按请求 ID 检索
+		unary = append([]grpc.UnaryServerInterceptor{requestIDSpanUnaryInterceptor()}, unary...)
+		stream = append([]grpc.StreamServerInterceptor{requestIDSpanStreamInterceptor()}, stream...)
+	}
+
+	serverOpts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+		grpc.StatsHandler(srpcstats.NewHandler(metrics)),
+	}
+	serverOpts = append(serverOpts,
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:                  cfg.KeepAliveTime,
+			Timeout:               cfg.KeepAliveTimeout,
+			MaxConnectionAge:      cfg.MaxConnectionAge,
+			MaxConnectionAgeGrace: cfg.MaxConnectionAgeGrace,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             cfg.KeepAliveMinTime,
+			PermitWithoutStream: cfg.KeepAlivePermitWithoutStream,
+		}),
+	)
+	if cfg.MaxRecvMsgSize > 0 {
+		serverOpts = append(serverOpts, grpc.MaxRecvMsgSize(cfg.MaxRecvMsgSize))
+	}
+	if cfg.MaxSendMsgSize > 0 {
+		serverOpts = append(serverOpts, grpc.MaxSendMsgSize(cfg.MaxSendMsgSize))
+	}
+	if cfg.EnableTracing {
+		serverOpts = append(serverOpts, grpc.StatsHandler(otelgrpc.NewServerHandler()))
+	}
+	if tlsConfig != nil {
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	serverOpts = append(serverOpts, o.serverOptions...)
+
+	s := &Server{
+		config:     cfg,
+		grpcServer: grpc.NewServer(serverOpts...),
+		metrics:    metrics,
+		drainer:    drainer,
+	}
+	pb.RegisterGreeterServer(s.grpcServer, &server{startTime: time.Now(), config: cfg})
+	healthpb.RegisterHealthServer(s.grpcServer, drainer.health)
+	if cfg.EnableChannelz {
+		channelzsvc.RegisterChannelzServiceToServer(s.grpcServer)
+	}
+
+	return s, nil
+}
+
+// RegisterService 注册额外的 gRPC 服务，必须在 Start 之前调用
+func (s *Server) RegisterService(desc *grpc.ServiceDesc, impl any) {
+	s.grpcServer.RegisterService(desc, impl)
+}
+
+// GetServiceInfo 返回已注册服务的信息，使 Server 可直接传给 reflection.Register
+func (s *Server) GetServiceInfo() map[string]grpc.ServiceInfo {
+	return s.grpcServer.GetServiceInfo()
+}
+
+// Start 监听配置的地址并阻塞提供服务，直到服务器停止
+func (s *Server) Start() error {
+	lis, err := listen(s.config)
 	if err != nil {
 		return fmt.Errorf("监听失败: %v", err)
 	}
 
-	// 创建 gRPC 服务器
-	s := grpc.NewServer()
-	pb.RegisterGreeterServer(s, &server{})
+	slogger.Info("gRPC 服务器启动", map[string]interface{}{
+		"listen_addr": s.config.ListenAddr,
+		"instance_id": s.config.InstanceID,
+		"tls":         s.config.TLSCertFile != "",
+		"version":     Version,
+		"git_commit":  GitCommit,
+		"build_time":  BuildTime,
+	})
+	return s.Serve(lis)
+}
 
-	slogger.Info("gRPC 服务器启动，监听端口: 50051")
+// Serve 在调用方提供的 listener 上阻塞提供服务，直到服务器停止，例如测试中的 bufconn.Listener
+// 配置了 DebugAddr 时同时启动调试 HTTP 服务，随 Stop 一起关闭
+func (s *Server) Serve(lis net.Listener) error {
+	if err := s.startDebugServer(); err != nil {
+		lis.Close()
+		return err
+	}
+	if err := s.grpcServer.Serve(lis); err != nil {
+		return fmt.Errorf("服务器启动失败: %w", err)
+	}
+	return nil
+}
 
-	// 关闭处理
-	stopChan := make(chan os.Signal, 1)
-	signal.Notify(stopChan, syscall.SIGINT, syscall.SIGTERM)
+// Drain 进入排空状态：健康检查返回 NOT_SERVING，新 RPC 以 Unavailable 拒绝，进行中的 RPC 和已建立的流继续运行
+// 排空不可撤销，调用方随后应调用 Stop 优雅关闭；重复调用无副作用
+func (s *Server) Drain() {
+	if s.drainer.drain() {
+		slogger.Info("服务器开始排空，不再接受新请求")
+	}
+}
+
+// Draining 返回服务器是否处于排空状态
+func (s *Server) Draining() bool {
+	return s.drainer.draining.Load()
+}
 
+// Stop 优雅关闭服务器，先进入排空状态，ctx 到期后强制关闭剩余连接
+func (s *Server) Stop(ctx context.Context) error {
+	s.Drain()
+	defer s.stopDebugServer()
+	done := make(chan struct{})
 	go func() {
-		<-stopChan
-		slogger.Info("收到关闭信号，开始关闭...")
-		s.GracefulStop()
-		slogger.Info("gRPC 服务器已关闭")
+		s.grpcServer.GracefulStop()
+		close(done)
 	}()
 
-	// 启动服务器
-	if err := s.Serve(lis); err != nil {
-		return fmt.Errorf("服务器启动失败: %v", err)
+	select {
+	case <-done:
+		slogger.Info("gRPC 服务器已关闭")
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		slogger.Warn("优雅关闭超时，已强制关闭 gRPC 服务器")
+		return ctx.Err()
+	}
+}
+
+// Metrics 获取服务端指标收集器
+func (s *Server) Metrics() *Metrics {
+	return s.metrics
+}
+
+// RunServerWithConfig 按配置启动 gRPC 服务器，收到 SIGINT/SIGTERM 时优雅关闭，收到 SIGUSR1 时进入排空状态
+func RunServerWithConfig(cfg Config, opts ...Option) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	drainSignal := make(chan os.Signal, 1)
+	signal.Notify(drainSignal, syscall.SIGUSR1)
+	defer signal.Stop(drainSignal)
+
+	return runServer(ctx, cfg, drainSignal, opts...)
+}
+
+// RunServerContext 使用指定配置启动 gRPC 服务器，ctx 取消时优雅关闭，不安装信号处理器
+// 适合嵌入到自行管理信号和生命周期的应用中；返回前会等待关闭完成
+func RunServerContext(ctx context.Context, cfg Config, opts ...Option) error {
+	return runServer(ctx, cfg, nil, opts...)
+}
+
+// runServer 启动服务器并阻塞到关闭完成，drainSignal 收到信号时进入排空状态，为 nil 时不处理
+func runServer(ctx context.Context, cfg Config, drainSignal <-chan os.Signal, opts ...Option) error {
+	s, err := New(cfg, opts...)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	// 初始化链路追踪导出器，导出配置来自标准 OTEL_* 环境变量
+	if cfg.EnableTracing {
+		shutdownTracing, err := tracing.Setup(context.Background(), "srpc-server")
+		if err != nil {
+			return err
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			shutdownTracing(ctx)
+		}()
+	}
+
+	// 关闭处理
+	serveDone := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-drainSignal:
+				slogger.Info("收到排空信号")
+				s.Drain()
+			case <-ctx.Done():
+				slogger.Info("收到关闭信号，开始关闭...")
+				stopCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+				defer cancel()
+				s.Stop(stopCtx)
+				return
+			case <-serveDone:
+				return
+			}
+		}
+	}()
+
+	err = s.Start()
+	close(serveDone)
+	<-stopped
+	return err
+}
+
+// RunServer 使用默认配置启动 gRPC 服务器
+func RunServer() error {
+	return RunServerWithConfig(DefaultConfig())
 }
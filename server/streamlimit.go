@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	pb "srpc/proto"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// StreamLimitReason 流超出服务端限制时 errdetails.ErrorInfo 的 Reason
+const StreamLimitReason = "STREAM_LIMIT_EXCEEDED"
+
+// streamProgress 客户端流截至当前的接收进度
+type streamProgress struct {
+	messages int64
+	bytes    int64
+	start    time.Time
+}
+
+// recvResult 一次 Recv 的结果
+type recvResult struct {
+	req *pb.StreamReqData
+	err error
+}
+
+// recvPutStream 在独立的 goroutine 中接收客户端流消息，使处理器在 Recv 阻塞时也能按 MaxStreamDuration 结束流
+// 处理器返回后流的 context 被取消，阻塞的 Recv 随之返回，goroutine 退出
+func recvPutStream(stream pb.Greeter_PutStreamServer) <-chan recvResult {
+	results := make(chan recvResult)
+	go func() {
+		ctx := stream.Context()
+		for {
+			req, err := stream.Recv()
+			select {
+			case results <- recvResult{req: req, err: err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return results
+}
+
+// checkStreamLimits 检查已接收的消息数和字节数是否超出配置的上限，0 表示不限制
+func (s *server) checkStreamLimits(ctx context.Context, p streamProgress) error {
+	if limit := s.config.MaxStreamMessages; limit > 0 && p.messages > int64(limit) {
+		return streamLimitExceeded(ctx, "max_stream_messages", fmt.Sprintf("消息数超过上限 %d 条", limit), p)
+	}
+	if limit := s.config.MaxStreamBytes; limit > 0 && p.bytes > int64(limit) {
+		return streamLimitExceeded(ctx, "max_stream_bytes", fmt.Sprintf("累计数据量超过上限 %d 字节", limit), p)
+	}
+	return nil
+}
+
+// streamLimitExceeded 记录客户端地址和接收进度，返回携带 errdetails.ErrorInfo 的 ResourceExhausted 错误
+// ErrorInfo.Metadata 中 limit 为触发的限制，messages_received、bytes_received 和 elapsed_ms 为截至此时的进度
+func streamLimitExceeded(ctx context.Context, limit, message string, p streamProgress) error {
+	elapsed := time.Since(p.start)
+	slogger.WarnContext(ctx, "客户端流超出限制，终止接收", map[string]interface{}{
+		"peer":              peerAddr(ctx),
+		"limit":             limit,
+		"messages_received": p.messages,
+		"bytes_received":    p.bytes,
+		"elapsed":           elapsed.String(),
+	})
+
+	message = fmt.Sprintf("%s，已接收 %d 条消息、%d 字节，耗时 %v", message, p.messages, p.bytes, elapsed.Truncate(time.Millisecond))
+	st := status.New(codes.ResourceExhausted, message)
+	detailed, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: StreamLimitReason,
+		Domain: "srpc",
+		Metadata: map[string]string{
+			"limit":             limit,
+			"messages_received": strconv.FormatInt(p.messages, 10),
+			"bytes_received":    strconv.FormatInt(p.bytes, 10),
+			"elapsed_ms":        strconv.FormatInt(elapsed.Milliseconds(), 10),
+		},
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// peerAddr 返回客户端地址，无法获取时返回空字符串
+func peerAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
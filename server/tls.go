@@ -0,0 +1,97 @@
+package server
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// defaultMinTLSVersion 未配置 MinTLSVersion 时允许的最低 TLS 版本
+const defaultMinTLSVersion = tls.VersionTLS12
+
+// tlsVersions 支持通过配置指定的 TLS 版本
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// ParseTLSVersion 将 "1.2"、"1.3" 等版本号解析为 tls.VersionTLS* 常量
+func ParseTLSVersion(s string) (uint16, error) {
+	version, ok := tlsVersions[strings.TrimPrefix(strings.TrimSpace(s), "TLS")]
+	if !ok {
+		return 0, fmt.Errorf("不支持的 TLS 版本: %q，可选 1.0、1.1、1.2、1.3", s)
+	}
+	return version, nil
+}
+
+// ParseCipherSuites 将逗号分隔的密码套件名称（例如 TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256）解析为 ID 列表
+// 只接受 tls.CipherSuites 中的安全套件；空字符串返回 nil，表示使用 Go 默认的套件列表
+func ParseCipherSuites(s string) ([]uint16, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	byName := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		byName[suite.Name] = suite.ID
+	}
+
+	var ids []uint16
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		id, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("不支持的密码套件: %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// buildTLSConfig 根据配置加载证书并构造 tls.Config，未配置证书时返回 nil 表示不启用 TLS
+// MinTLSVersion 为 0 时使用 TLS 1.2；CipherSuites 只约束 TLS 1.2 及以下版本，TLS 1.3 的套件由 Go 固定选择
+func buildTLSConfig(cfg Config) (*tls.Config, error) {
+	if cfg.TLSCertFile == "" && cfg.TLSKeyFile == "" {
+		return nil, nil
+	}
+	if cfg.TLSCertFile == "" || cfg.TLSKeyFile == "" {
+		return nil, errors.New("启用 TLS 需要同时配置证书文件和私钥文件")
+	}
+
+	minVersion := cfg.MinTLSVersion
+	if minVersion == 0 {
+		minVersion = defaultMinTLSVersion
+	}
+	if minVersion < tls.VersionTLS10 || minVersion > tls.VersionTLS13 {
+		return nil, fmt.Errorf("无效的最低 TLS 版本: 0x%04x", minVersion)
+	}
+	if minVersion < tls.VersionTLS12 {
+		slogger.Warn("最低 TLS 版本低于 1.2，不满足合规要求", map[string]interface{}{
+			"min_tls_version": tls.VersionName(minVersion),
+		})
+	}
+
+	secure := make(map[uint16]bool)
+	for _, suite := range tls.CipherSuites() {
+		secure[suite.ID] = true
+	}
+	for _, id := range cfg.CipherSuites {
+		if !secure[id] {
+			return nil, fmt.Errorf("不支持的密码套件: %s", tls.CipherSuiteName(id))
+		}
+	}
+
+	cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("加载 TLS 证书失败: %w", err)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   minVersion,
+		CipherSuites: cfg.CipherSuites,
+	}, nil
+}
@@ -0,0 +1,25 @@
+package server
+
+import "fmt"
+
+// validateName 校验 SayHello 的 name 字段：不能为空且长度不超过 maxLen
+func validateName(name string, maxLen int) error {
+	if name == "" {
+		return InvalidArgumentError("name", "name 不能为空")
+	}
+	if len(name) > maxLen {
+		return InvalidArgumentError("name", fmt.Sprintf("name 长度 %d 超过上限 %d", len(name), maxLen))
+	}
+	return nil
+}
+
+// validateStreamData 校验流消息的 data 字段：不能为空且大小不超过 maxSize 字节
+func validateStreamData(data string, maxSize int) error {
+	if data == "" {
+		return InvalidArgumentError("data", "data 不能为空")
+	}
+	if len(data) > maxSize {
+		return InvalidArgumentError("data", fmt.Sprintf("data 大小 %d 字节超过上限 %d 字节", len(data), maxSize))
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+package server
+
+// 构建信息，通过 -ldflags 注入：
+//
+//	go build -ldflags "-X srpc/server.Version=v1.2.0 -X srpc/server.GitCommit=$(git rev-parse --short HEAD) -X srpc/server.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	Version   = "dev"
+	GitCommit = "unknown"
+	BuildTime = "unknown"
+)